@@ -2,126 +2,417 @@ package main
 
 import (
 	"context"
+	"database/sql"
 	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"time"
+	"wb-project/internal/admin"
 	"wb-project/internal/app"
+	"wb-project/internal/auth"
 	"wb-project/internal/cache"
 	"wb-project/internal/config"
 	"wb-project/internal/db/conn"
 	"wb-project/internal/db/repository"
+	"wb-project/internal/encryption"
 	"wb-project/internal/handler"
+	"wb-project/internal/health"
 	"wb-project/internal/kafka"
+	"wb-project/internal/outbox"
+	"wb-project/internal/ratelimit"
+	"wb-project/internal/receipt"
+	"wb-project/internal/secret"
 	"wb-project/internal/service"
+	"wb-project/internal/stream"
+	"wb-project/internal/supervisor"
+	"wb-project/internal/webhook"
 
 	"go.opentelemetry.io/otel/sdk/trace"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 )
 
 type Application struct {
-	srv      *app.Server
-	consumer *kafka.OrderConsumer
-	producer *kafka.OrderProducer
-	service  *service.OrderService
-	cache    *cache.OrderCache
-	tp       *trace.TracerProvider
+	srv       *app.Server
+	grpcSrv   *app.GRPCServer
+	httpAddr  string
+	grpcAddr  string
+	db        *sql.DB
+	dbConf    config.DBConfig
+	kafkaConf config.KafkaConfig
+	security  *kafka.Security
+	startup   config.StartupConfig
+	ready     chan struct{} // закрывается, когда БД и Kafka подключены
+	consumer  *kafka.OrderConsumer
+	events    *kafka.EventProducer
+	relay     *outbox.Relay
+	rotator   *encryption.Rotator // nil, если шифрование не настроено
+	limits    *handler.Limits     // nil, если ограничения отключены
+	webhooks  *webhook.Dispatcher
+	hub       *stream.Hub
+	service   *service.OrderService
+	cache     *cache.OrderCache
+	health    *health.Checker
+	drain     time.Duration
+	shutdown  time.Duration
+	secrets   []*secret.Value
+	refresh   time.Duration // период перечитывания файлов секретов
+	tp        *trace.TracerProvider
 }
 
-func NewApplication(cfg *config.Config) (*Application, error) {
-	// 3. Подключение к БД
-	dbConn, err := conn.Connection(&cfg.DB)
+// NewApplication - сборка компонентов по cfg; параметры, изменяемые на лету,
+// подписываются на reloader.
+func NewApplication(cfg *config.Config, reloader *config.Reloader) (*Application, error) {
+	// 3. Учетные данные: файлы секретов перечитываются, пока сервис работает
+	dbPassword, err := secret.New(cfg.DB.Password, cfg.DB.PasswordFile)
 	if err != nil {
-		return nil, fmt.Errorf("подключение к БД: %w", err)
+		return nil, fmt.Errorf("пароль БД: %w", err)
 	}
+	kafkaPassword, err := secret.New(cfg.KafkaConfig.SASLPassword, cfg.KafkaConfig.SASLPasswordFile)
+	if err != nil {
+		return nil, fmt.Errorf("пароль SASL Kafka: %w", err)
+	}
+	kafkaSecurity, err := kafka.NewSecurity(cfg.KafkaConfig, kafkaPassword)
+	if err != nil {
+		return nil, fmt.Errorf("настройка TLS/SASL Kafka: %w", err)
+	}
+
+	// 4. Пул БД; подключение с повторами - при запуске, см. connect
+	dbConn := conn.Open(&cfg.DB, dbPassword)
 	// 5. Сборка слоев
-	orderCache := cache.NewOrderCache(1*time.Minute, 30*time.Second)
-	orderRepo := repository.NewOrderRepository(dbConn)
-	orderService := service.NewOrderService(orderRepo, orderCache)
-	orderHandler := handler.NewOrderHandler(orderService)
-	srv := app.NewServer(orderHandler)
+	orderCache := cache.NewOrderCache(cfg.Cache.TTL, cfg.Cache.CleanupInterval).WithStaleTTL(cfg.Cache.StaleTTL)
+	keyring, err := newKeyring(cfg.Encryption)
+	if err != nil {
+		return nil, fmt.Errorf("настройка шифрования: %w", err)
+	}
+	orderRepo := repository.NewOrderRepository(dbConn).WithEncryption(keyring)
+	hub := stream.NewHub(cfg.Stream.BufferSize, cfg.Stream.History)
+	// таймауты и circuit breaker: медленная БД не копит запросы, промахи кэша отклоняются сразу.
+	// Через него же читают заказы диспетчер вебхуков и релей outbox
+	orders := service.NewBreakerRepository(orderRepo, cfg.Repository)
+	orderService := service.NewOrderService(orders, orderCache).WithNotifier(hub)
+	orderService.SetValidationMode(cfg.Orders.ValidationMode)
+	// доступ к персональным данным без маскирования пишется в журнал аудита
+	auditRepo := repository.NewAuditRepository(dbConn)
+	receipts, err := receipt.NewRenderer(cfg.Orders.TemplatesDir)
+	if err != nil {
+		return nil, fmt.Errorf("шаблоны печатных форм: %w", err)
+	}
+	orderHandler := handler.NewOrderHandler(orderService, auditRepo, cfg.Orders).WithReceipts(receipts)
+	streamHandler := handler.NewStreamHandler(hub, cfg.Stream.Heartbeat)
+
+	webhookRepo := repository.NewWebhookRepository(dbConn)
+	dispatcher := webhook.NewDispatcher(webhookRepo, orders, cfg.Webhook)
+	webhookHandler := handler.NewWebhookHandler(webhook.NewManager(webhookRepo, dispatcher))
 
-	if err = kafka.EnsureTopicExists(cfg.KafkaConfig.Brokers, cfg.KafkaConfig.Topic); err != nil {
-		return nil, fmt.Errorf("создание Kafka topic: %w", err)
+	authenticator, err := newAuthenticator(cfg.Auth)
+	if err != nil {
+		return nil, fmt.Errorf("настройка аутентификации: %w", err)
 	}
 
-	producer, err := kafka.NewProducer(cfg.KafkaConfig.Brokers, cfg.KafkaConfig.Topic)
+	limits, err := newLimits(cfg.RateLimit)
 	if err != nil {
-		return nil, fmt.Errorf("создание Kafka Producer: %w", err)
+		return nil, fmt.Errorf("настройка ограничений нагрузки: %w", err)
+	}
+
+	consumer := kafka.NewOrderConsumer(cfg.KafkaConfig.Brokers, kafkaSecurity, cfg.KafkaConfig.Topic, cfg.KafkaConfig.Group, orderService.HandleOrderMessage)
+	// действия admin API пишутся в тот же журнал аудита
+	adminHandler := handler.NewAdminHandler(admin.NewManager(orderCache, orderService, consumer, auditRepo).WithConfig(reloader))
+
+	// параметры, которые применяются без перезапуска (SIGHUP или POST /admin/config:reload)
+	reloader.
+		OnReload(func(next *config.Config) (func(), error) {
+			return func() { orderCache.SetExpiration(next.Cache.TTL, next.Cache.StaleTTL, next.Cache.CleanupInterval) }, nil
+		}).
+		OnReload(func(next *config.Config) (func(), error) {
+			return func() { orderService.SetValidationMode(next.Orders.ValidationMode) }, nil
+		})
+	if limits != nil {
+		reloader.OnReload(func(next *config.Config) (func(), error) {
+			tiers, err := parseTiers(next.RateLimit.Tiers)
+			if err != nil {
+				return nil, err
+			}
+			inFlight := ratelimit.NewInFlight(next.RateLimit.MaxInFlight, next.RateLimit.InFlightWait)
+			return func() { limits.Update(tiers, inFlight) }, nil
+		})
 	}
 
-	consumer, err := kafka.NewOrderConsumer(cfg.KafkaConfig.Brokers, cfg.KafkaConfig.Topic, orderService.HandleOrderMessage)
+	// readiness: без БД и Kafka инстанс не может обслуживать запросы и принимать заказы
+	checker := health.NewChecker(cfg.Health.CheckTimeout).
+		Register("database", dbConn.PingContext).
+		Register("kafka", consumer.CheckBrokers).
+		Register("consumer", consumer.CheckConsumer)
+	if cfg.Startup.Degraded {
+		checker.WithDegradedStartup("database")
+	}
+	srv, err := app.NewServer(orderHandler, webhookHandler, streamHandler, handler.NewHealthHandler(checker), adminHandler, authenticator, limits).
+		WithTimeouts(cfg.HTTP).
+		WithTrustedProxies(cfg.HTTP.TrustedProxies)
 	if err != nil {
-		return nil, fmt.Errorf("создание Kafka Consumer: %w", err)
+		return nil, fmt.Errorf("настройка HTTP сервера: %w", err)
+	}
+	grpcSrv := app.NewGRPCServer(handler.NewGRPCOrderHandler(orderService, auditRepo, hub, cfg.Orders.BatchMaxSize), authenticator)
+
+	events := kafka.NewEventProducer(cfg.KafkaConfig.Brokers, kafkaSecurity, cfg.KafkaConfig.EventsTopic)
+	relay := outbox.NewRelay(repository.NewOutboxRepository(dbConn), orders, events, cfg.Outbox)
+
+	var rotator *encryption.Rotator
+	if keyring != nil {
+		rotator = encryption.NewRotator(orderRepo, cfg.Encryption)
 	}
 
 	return &Application{
-		srv:      srv,
-		consumer: consumer,
-		producer: producer,
-		service:  orderService,
-		cache:    orderCache,
-		tp:       nil,
+		srv:       srv,
+		grpcSrv:   grpcSrv,
+		httpAddr:  cfg.HTTP.Addr,
+		grpcAddr:  cfg.GRPC.Addr,
+		db:        dbConn,
+		dbConf:    cfg.DB,
+		kafkaConf: cfg.KafkaConfig,
+		security:  kafkaSecurity,
+		startup:   cfg.Startup,
+		ready:     make(chan struct{}),
+		consumer:  consumer,
+		events:    events,
+		relay:     relay,
+		rotator:   rotator,
+		limits:    limits,
+		webhooks:  dispatcher,
+		hub:       hub,
+		service:   orderService,
+		cache:     orderCache,
+		health:    checker,
+		drain:     cfg.Health.DrainDelay,
+		shutdown:  cfg.ShutdownTimeout,
+		secrets:   []*secret.Value{dbPassword, kafkaPassword},
+		refresh:   cfg.SecretsRefreshInterval,
+		tp:        nil,
 	}, nil
 }
 
+// newAuthenticator - API-ключи и JWKS из конфигурации.
+func newAuthenticator(cfg config.AuthConfig) (*auth.Authenticator, error) {
+	if !cfg.Enabled {
+		log.Println("ВНИМАНИЕ: аутентификация отключена (AUTH_ENABLED=false), API доступно всем с правами viewer, admin API закрыт")
+		return auth.Disabled(), nil
+	}
+
+	rawKeys, err := secret.New(cfg.APIKeys, cfg.APIKeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_API_KEYS_FILE: %w", err)
+	}
+	keys, err := auth.ParseAPIKeys(rawKeys.Get())
+	if err != nil {
+		return nil, fmt.Errorf("AUTH_API_KEYS: %w", err)
+	}
+	var verifier *auth.JWTVerifier
+	if cfg.JWKSFile != "" {
+		jwks, err := auth.LoadJWKS(cfg.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		verifier = auth.NewJWTVerifier(jwks, auth.JWTConfig{
+			Issuer:    cfg.JWTIssuer,
+			Audience:  cfg.JWTAudience,
+			RoleClaim: cfg.RoleClaim,
+			Leeway:    cfg.Leeway,
+		})
+	}
+	if keys.Len() == 0 && verifier == nil {
+		return nil, errors.New("аутентификация включена, но не заданы ни AUTH_API_KEYS, ни AUTH_JWKS_FILE")
+	}
+	return auth.NewAuthenticator(true, keys, verifier), nil
+}
+
+// newLimits - ограничители HTTP API из конфигурации.
+func newLimits(cfg config.RateLimitConfig) (*handler.Limits, error) {
+	if !cfg.Enabled {
+		log.Println("ВНИМАНИЕ: ограничение частоты запросов отключено (RATE_LIMIT_ENABLED=false)")
+		return nil, nil
+	}
+	tiers, err := parseTiers(cfg.Tiers)
+	if err != nil {
+		return nil, err
+	}
+	return &handler.Limits{
+		Rate:     ratelimit.NewLimiter(cfg.IdleTTL),
+		Tiers:    tiers,
+		InFlight: ratelimit.NewInFlight(cfg.MaxInFlight, cfg.InFlightWait),
+	}, nil
+}
+
+// parseTiers - уровни rate limit, уровень anonymous обязателен.
+func parseTiers(raw string) (map[string]ratelimit.Tier, error) {
+	tiers, err := ratelimit.ParseTiers(raw)
+	if err != nil {
+		return nil, fmt.Errorf("RATE_LIMIT_TIERS: %w", err)
+	}
+	if _, ok := tiers[handler.TierAnonymous]; !ok {
+		return nil, fmt.Errorf("RATE_LIMIT_TIERS: не задан уровень %s", handler.TierAnonymous)
+	}
+	return tiers, nil
+}
+
+// newKeyring - ключи шифрования персональных данных; без файла данные пишутся открытым текстом.
+func newKeyring(cfg config.EncryptionConfig) (*encryption.Keyring, error) {
+	if cfg.KeyringFile == "" {
+		log.Println("ВНИМАНИЕ: ENCRYPTION_KEYRING_FILE не задан, контакты получателей хранятся в БД открытым текстом")
+		return nil, nil
+	}
+	return encryption.LoadKeyring(cfg.KeyringFile)
+}
+
+// Run - запуск компонентов супервизором до отмены ctx или падения компонента.
+// Порядок регистрации - порядок зависимостей: останавливаются компоненты в обратном,
+// поэтому сначала инстанс выводится из балансировки, затем останавливаются серверы,
+// дообрабатывается сообщение Kafka, и только в конце закрывается БД.
 func (app *Application) Run(ctx context.Context, tp *sdktrace.TracerProvider) error {
 	app.tp = tp
 
+	// подключение к БД и Kafka: обычно запуск ждет его, в деградированном режиме
+	// идет в фоне, а API уже отвечает из кэша
+	dependencies := supervisor.Component{Name: "dependencies", Start: app.connect}
+	if app.startup.Degraded {
+		log.Println("Деградированный режим: API запускается до подключения к БД и Kafka")
+		dependencies = supervisor.Component{Name: "dependencies", Run: func(ctx context.Context) error {
+			if err := app.connect(ctx); err != nil {
+				return err
+			}
+			<-ctx.Done()
+			return nil
+		}}
+	}
+
+	sv := supervisor.New(app.shutdown).
+		Add(supervisor.Component{
+			Name: "database",
+			Stop: func(context.Context) error { return app.db.Close() },
+		}).
+		Add(dependencies).
+		Add(supervisor.Component{
+			Name: "cache.gc",
+			Run:  app.cache.GC,
+			Stop: func(context.Context) error { app.cache.Stop(); return nil },
+		}).
+		Add(supervisor.Component{
+			Name: "kafka.events",
+			Stop: func(context.Context) error { return app.events.Close() },
+		}).
+		Add(supervisor.Component{Name: "outbox.relay", Run: app.afterConnect(app.relay.Run)}).
+		Add(supervisor.Component{Name: "webhooks", Run: app.afterConnect(app.webhooks.Run)}).
+		Add(supervisor.Component{Name: "secrets", Run: func(ctx context.Context) error {
+			return secret.Watch(ctx, app.refresh, app.secrets...)
+		}})
+	if app.rotator != nil {
+		sv.Add(supervisor.Component{Name: "encryption.rotator", Run: app.afterConnect(app.rotator.Run)})
+	}
+	if app.limits != nil {
+		sv.Add(supervisor.Component{Name: "ratelimit.cleanup", Run: app.limits.Rate.Run})
+	}
+	sv.
+		Add(supervisor.Component{
+			// потеря брокера не повод останавливать API: консьюмер переподключается с backoff
+			Name:    "kafka.consumer",
+			Run:     app.afterConnect(app.consumer.Start),
+			Stop:    func(context.Context) error { return app.consumer.Close() },
+			Restart: &supervisor.Backoff{Min: time.Second, Max: 30 * time.Second},
+		}).
+		Add(supervisor.Component{
+			Name: "http",
+			Run: supervisor.Blocking(func() error {
+				log.Printf("Запуск HTTP сервера на %s", app.httpAddr)
+				if err := app.srv.Run(app.httpAddr); !errors.Is(err, http.ErrServerClosed) {
+					return err
+				}
+				return nil
+			}),
+			Stop: app.srv.Stop,
+		}).
+		Add(supervisor.Component{
+			Name: "grpc",
+			Run: supervisor.Blocking(func() error {
+				log.Printf("Запуск gRPC сервера на %s", app.grpcAddr)
+				return app.grpcSrv.Run(app.grpcAddr)
+			}),
+			Stop: app.grpcSrv.Stop,
+		}).
+		Add(supervisor.Component{
+			// закрывается до серверов, иначе открытые SSE-потоки держат остановку HTTP до дедлайна
+			Name: "stream.hub",
+			Stop: func(context.Context) error { app.hub.Close(); return nil },
+		}).
+		Add(supervisor.Component{
+			// readiness падает, и балансировщик успевает вывести инстанс до остановки серверов
+			Name: "drain",
+			Stop: func(ctx context.Context) error {
+				log.Println("Получен сигнал завершения (Graceful Shutdown)...")
+				app.health.Drain()
+				app.grpcSrv.Drain()
+				log.Printf("Ожидание вывода из балансировки: %s", app.drain)
+				select {
+				case <-time.After(app.drain):
+					return nil
+				case <-ctx.Done():
+					return ctx.Err()
+				}
+			},
+		})
+
+	return sv.Run(ctx)
+}
+
+// connect - ожидание БД и Kafka с ограниченным числом попыток и разогрев кэша,
+// после чего запуск считается завершенным.
+func (app *Application) connect(ctx context.Context) error {
+	backoff := supervisor.Backoff{Min: app.startup.RetryBackoffMin, Max: app.startup.RetryBackoffMax}
+	retry := func(name string, fn func(ctx context.Context) error) error {
+		return supervisor.Retry(ctx, name, app.startup.RetryAttempts, backoff, fn)
+	}
+
+	//1. БД
+	if err := retry("database", func(ctx context.Context) error {
+		return conn.Ping(ctx, app.db, &app.dbConf)
+	}); err != nil {
+		return err
+	}
+	// промахи кэша обслуживает БД, поэтому неудачный разогрев не мешает запуску,
+	// но в деградированном режиме трафик принимается только с разогретым кэшем
 	if err := app.service.ReCache(ctx); err != nil {
 		log.Printf("Не удалось восстановить кэш из БД: %v", err)
+	} else {
+		app.health.MarkWarmed()
 	}
-	go func() {
-		log.Println("Запуск Consumer...")
-		if err := app.cache.GC(ctx); err != nil {
-			log.Printf("GC остановлен : %v", err)
-		}
-	}()
-	// Запуск консьюмера
-	go func() {
-		log.Println("Запуск Consumer...")
-		if err := app.consumer.Start(ctx); err != nil {
-			log.Printf("Consumer остановился с ошибкой: %v", err)
-		}
 
-	}()
-	go func() {
-		log.Println("Запуск HTTP сервера на :8080")
-		if err := app.srv.Run(":8080"); err != nil {
-			if errors.Is(err, http.ErrServerClosed) {
-				log.Printf("HTTP сервер в штаном режиме остановлен")
-			} else {
-				log.Fatalf("Критическая ошибка сервера: %v", err)
+	//2. Kafka: топики, продюсер событий, консьюмер
+	if err := retry("kafka", func(context.Context) error {
+		for _, topic := range []string{app.kafkaConf.Topic, app.kafkaConf.EventsTopic} {
+			if err := kafka.EnsureTopicExists(app.kafkaConf.Brokers, app.security, topic); err != nil {
+				return err
 			}
 		}
-	}()
-	if err := app.producer.Send(); err != nil {
-		log.Printf("Не удалось отправить сообщение в Kafka: %v", err)
+		if err := app.events.Connect(); err != nil {
+			return err
+		}
+		return app.consumer.Connect()
+	}); err != nil {
+		return err
 	}
 
-	// 10. Ожидание сигнала завершения
-	<-ctx.Done()
-	log.Println("Получен сигнал завершения (Graceful Shutdown)...")
-
-	// 11. Остановка HTTP сервера
-	// Даем 5 секунд на завершение текущих запросов
-	shutdownContext, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-	app.Shutdown(shutdownContext)
-
+	app.health.MarkStarted()
+	close(app.ready)
+	log.Println("Зависимости подключены, запуск завершен")
 	return nil
 }
 
-func (app *Application) Shutdown(ctx context.Context) {
-	if err := app.srv.Stop(ctx); err != nil {
-		log.Printf("Ошибка остановки HTTP сервера: %v", err)
-	}
-	if err := app.consumer.Close(); err != nil {
-		log.Printf("Ошибка остановки Kafka Consumer: %v", err)
-	}
-	if err := app.producer.Close(); err != nil {
-		log.Printf("Ошибка остановки Kafka Producer: %v", err)
+// afterConnect - Run, который начинается только после подключения к БД и Kafka.
+func (app *Application) afterConnect(run func(ctx context.Context) error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		select {
+		case <-app.ready:
+			return run(ctx)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 	}
-	app.cache.Stop()
 }
@@ -2,12 +2,15 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
+	"log/slog"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 	"wb-project/internal/config"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
 	"wb-project/internal/trace"
 )
 
@@ -16,22 +19,46 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer stop()
 
-	// 2. Загрузка конфигурации
-	cfg := config.LoadConfig()
+	// 2. Загрузка конфигурации: YAML-файл, окружение, флаги
+	fs, printConfig := newFlagSet(flag.ExitOnError)
+	cfg, err := config.Load(fs, os.Args[1:])
+	if err != nil {
+		log.Fatal(err)
+	}
+	if *printConfig {
+		if err := config.Print(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	// Все записи slog (и пакета log, который перенаправляется в slog) проходят маскирование PII
+	level := new(slog.LevelVar)
+	level.Set(logLevel(cfg.Log.Level))
+	slog.SetDefault(slog.New(pii.NewHandler(newLogHandler(cfg.Log, level), pii.Keys(models.Order{}))))
 
-	tp, err := trace.InitTracer(ctx)
+	// перезагрузка конфигурации: те же источники и флаги, что при запуске
+	reloader := config.NewReloader(cfg, func() (*config.Config, error) {
+		fs, _ := newFlagSet(flag.ContinueOnError)
+		return config.Load(fs, os.Args[1:])
+	}).OnReload(func(next *config.Config) (func(), error) {
+		return func() { level.Set(logLevel(next.Log.Level)) }, nil
+	})
+	go reloadOnSIGHUP(ctx, reloader)
+
+	tp, err := trace.InitTracer(ctx, cfg.Tracing)
 	if err != nil {
 		log.Fatalf("Failed to init tracer: %v", err)
 	}
 	defer func() {
-		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.ShutdownTimeout)
 		defer cancel()
 		if err := tp.Shutdown(shutdownCtx); err != nil {
 			log.Printf("Tracer shutdown error: %v", err)
 		}
 	}()
-	
-	application, err := NewApplication(cfg)
+
+	application, err := NewApplication(cfg, reloader)
 	if err != nil {
 		log.Fatalf("Ошибка при инициализации приложения: %v", err)
 	}
@@ -41,3 +68,42 @@ func main() {
 	}
 	log.Println("Сервис успешно остановлен")
 }
+
+// newFlagSet - флаги конфигурации регистрирует config.Load, здесь только собственные флаги команды.
+func newFlagSet(errorHandling flag.ErrorHandling) (*flag.FlagSet, *bool) {
+	fs := flag.NewFlagSet(os.Args[0], errorHandling)
+	printConfig := fs.Bool("print-config", false, "вывести итоговую конфигурацию (секреты скрыты) и выйти")
+	return fs, printConfig
+}
+
+// reloadOnSIGHUP - kill -HUP перечитывает конфигурацию; отказ уже залогирован Reloader.
+func reloadOnSIGHUP(ctx context.Context, reloader *config.Reloader) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+			slog.Info("получен SIGHUP, перезагрузка конфигурации")
+			_, _ = reloader.Reload()
+		}
+	}
+}
+
+// logLevel - уровень из конфигурации, он уже проверен валидацией.
+func logLevel(name string) slog.Level {
+	var level slog.Level
+	_ = level.UnmarshalText([]byte(name))
+	return level
+}
+
+// newLogHandler - вывод slog в stderr с уровнем из level и форматом из конфигурации.
+func newLogHandler(conf config.LogConfig, level slog.Leveler) slog.Handler {
+	opts := &slog.HandlerOptions{Level: level}
+	if conf.Format == "json" {
+		return slog.NewJSONHandler(os.Stderr, opts)
+	}
+	return slog.NewTextHandler(os.Stderr, opts)
+}
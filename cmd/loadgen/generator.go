@@ -0,0 +1,122 @@
+package main
+
+import (
+	"math/rand"
+	"time"
+	"wb-project/internal/models"
+
+	"github.com/brianvoe/gofakeit"
+)
+
+// Виды намеренно невалидных заказов, которые генератор подмешивает в поток.
+const (
+	invalidBadPhone   = "bad_phone"
+	invalidEmptyItems = "empty_items"
+	invalidTotals     = "mismatched_totals"
+)
+
+var invalidKinds = []string{invalidBadPhone, invalidEmptyItems, invalidTotals}
+
+// baseTime - от него отсчитываются даты заказов, чтобы один seed давал одни и те же заказы.
+var baseTime = time.Date(2026, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// createdSpread - в каком интервале после baseTime лежат даты заказов.
+const createdSpread = 30 * 24 * time.Hour
+
+// generator выдает заказы детерминированно при одинаковом seed.
+// Не потокобезопасен: заказы генерируются в одной горутине и раздаются воркерам.
+type generator struct {
+	rnd          *rand.Rand
+	invalidRatio float64
+}
+
+func newGenerator(seed int64, invalidRatio float64) *generator {
+	// gofakeit v3 использует глобальный math/rand, поэтому сидируем и его
+	gofakeit.Seed(seed)
+	return &generator{
+		rnd:          rand.New(rand.NewSource(seed)),
+		invalidRatio: invalidRatio,
+	}
+}
+
+// Next - возвращает очередной заказ и вид порчи ("" для валидного заказа).
+func (g *generator) Next() (models.Order, string) {
+	order := fakeOrder(baseTime.Add(time.Duration(g.rnd.Int63n(int64(createdSpread)))))
+	if g.rnd.Float64() >= g.invalidRatio {
+		return order, ""
+	}
+
+	kind := invalidKinds[g.rnd.Intn(len(invalidKinds))]
+	switch kind {
+	case invalidBadPhone:
+		order.Delivery.Phone = "8-800-" + gofakeit.Numerify("###")
+	case invalidEmptyItems:
+		order.Items = []models.Items{}
+	case invalidTotals:
+		order.Payment.GoodsTotal += gofakeit.Number(1, 1000)
+	}
+	return order, kind
+}
+
+// fakeOrder - валидный заказ: суммы в payment согласованы с позициями.
+func fakeOrder(created time.Time) models.Order {
+	trackNumber := "WB-" + gofakeit.Numerify("##########")
+	items := make([]models.Items, gofakeit.Number(1, 5))
+	goodsTotal := 0
+	for i := range items {
+		price := gofakeit.Number(100, 10000)
+		sale := gofakeit.Number(0, 50)
+		total := price * (100 - sale) / 100
+		items[i] = models.Items{
+			ChrtID:      gofakeit.Number(1, 1000),
+			TrackNumber: trackNumber,
+			Price:       price,
+			Rid:         gofakeit.UUID(),
+			Name:        gofakeit.Name(),
+			Sale:        sale,
+			Size:        "XL",
+			TotalPrice:  total,
+			NmID:        gofakeit.Number(1, 1000000),
+			Brand:       gofakeit.Company(),
+			Status:      202,
+		}
+		goodsTotal += total
+	}
+	deliveryCost := 500
+
+	return models.Order{
+		OrderUID:    gofakeit.UUID(),
+		TrackNumber: trackNumber,
+		Entry:       "WBIL",
+		Delivery: models.Delivery{
+			Name:    gofakeit.Name(),
+			Phone:   "+79" + gofakeit.Numerify("#########"), // e164 формат
+			Zip:     gofakeit.Zip(),
+			City:    gofakeit.City(),
+			Address: gofakeit.Address().Address,
+			Region:  gofakeit.State(),
+			Email:   gofakeit.Email(),
+		},
+		Payment: models.Payment{
+			Transaction:  gofakeit.UUID(),
+			RequestID:    gofakeit.Numerify("##########"),
+			Currency:     "RUB", // ровно 3 символа
+			Provider:     "wbpay",
+			Amount:       goodsTotal + deliveryCost,
+			PaymentDt:    int(created.Unix()),
+			Bank:         "alpha",
+			DeliveryCost: deliveryCost,
+			GoodsTotal:   goodsTotal,
+			CustomFee:    10,
+		},
+		Items:             items,
+		Locale:            "ru",
+		InternalSignature: "",
+		CustomerID:        gofakeit.UUID(),
+		DeliveryService:   "meest",
+		ShardKey:          "9",
+		SmID:              99,
+		DateCreated:       created,
+		OofShard:          "1",
+	}
+}
@@ -0,0 +1,183 @@
+//go:debug randseednop=0
+
+// Команда loadgen генерирует фейковые заказы и отправляет их в Kafka
+// для нагрузочного тестирования сервиса.
+//
+// Пример:
+//
+//	go run ./cmd/loadgen -rate 200 -duration 1m -concurrency 8 -invalid-ratio 0.1 -seed 42
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/kafka"
+	"wb-project/internal/models"
+	"wb-project/internal/secret"
+	"wb-project/internal/trace"
+)
+
+// Стратегии выбора ключа партиционирования.
+const (
+	keyNone       = "none"
+	keyOrderUID   = "order_uid"
+	keyCustomerID = "customer_id"
+)
+
+type job struct {
+	order       models.Order
+	invalidKind string
+}
+
+func main() {
+	// адреса Kafka и трейсинг - из той же конфигурации, что и у сервиса (файл из CONFIG_FILE и окружение)
+	cfg, err := config.LoadEnv()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	brokers := flag.String("brokers", strings.Join(cfg.KafkaConfig.Brokers, ","), "адреса брокеров через запятую")
+	topic := flag.String("topic", cfg.KafkaConfig.Topic, "топик для заказов")
+	rate := flag.Float64("rate", 10, "сообщений в секунду (0 - без ограничения)")
+	duration := flag.Duration("duration", 0, "длительность прогона (0 - без ограничения)")
+	count := flag.Int("count", 0, "всего сообщений (0 - без ограничения)")
+	concurrency := flag.Int("concurrency", 1, "количество параллельных отправителей")
+	keyStrategy := flag.String("key", keyOrderUID, "ключ партиционирования: none|order_uid|customer_id")
+	invalidRatio := flag.Float64("invalid-ratio", 0, "доля намеренно невалидных заказов [0..1]")
+	seed := flag.Int64("seed", 1, "seed генератора для воспроизводимых данных")
+	flag.Parse()
+
+	if *duration == 0 && *count == 0 {
+		log.Fatal("нужно задать -duration или -count")
+	}
+	if *concurrency < 1 {
+		log.Fatal("-concurrency должен быть >= 1")
+	}
+	if *invalidRatio < 0 || *invalidRatio > 1 {
+		log.Fatal("-invalid-ratio должен быть в диапазоне [0..1]")
+	}
+	keyOf, err := partitionKey(*keyStrategy)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	if *duration > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *duration)
+		defer cancel()
+	}
+
+	// трейсинг нужен, чтобы заказ можно было проследить от генератора до БД
+	tracing := cfg.Tracing
+	tracing.ServiceName = "wb-loadgen"
+	tp, err := trace.InitTracer(ctx, tracing)
+	if err != nil {
+		log.Fatalf("Failed to init tracer: %v", err)
+	}
+	defer func() {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := tp.Shutdown(shutdownCtx); err != nil {
+			log.Printf("Tracer shutdown error: %v", err)
+		}
+	}()
+
+	password, err := secret.New(cfg.KafkaConfig.SASLPassword, cfg.KafkaConfig.SASLPasswordFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+	security, err := kafka.NewSecurity(cfg.KafkaConfig, password)
+	if err != nil {
+		log.Fatal(err)
+	}
+	producer, err := kafka.NewProducer(strings.Split(*brokers, ","), security, *topic)
+	if err != nil {
+		log.Fatalf("создание Kafka Producer: %v", err)
+	}
+	defer func() {
+		if err := producer.Close(); err != nil {
+			log.Printf("Ошибка остановки Kafka Producer: %v", err)
+		}
+	}()
+
+	jobs := make(chan job, *concurrency)
+	st := newStats()
+
+	// уже сгенерированные заказы досылаем и после истечения -duration
+	sendCtx := context.WithoutCancel(ctx)
+	var wg sync.WaitGroup
+	for range *concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range jobs {
+				start := time.Now()
+				_, _, err := producer.Send(sendCtx, keyOf(j.order), j.order)
+				st.record(time.Since(start), j.invalidKind, err)
+				if err != nil {
+					log.Printf("не удалось отправить заказ %s: %v", j.order.OrderUID, err)
+				}
+			}
+		}()
+	}
+
+	log.Printf("Старт нагрузки: topic=%s rate=%.1f concurrency=%d seed=%d", *topic, *rate, *concurrency, *seed)
+	start := time.Now()
+	produce(ctx, jobs, newGenerator(*seed, *invalidRatio), *rate, *count)
+	wg.Wait()
+
+	st.report(os.Stdout, time.Since(start))
+}
+
+// produce - генерирует заказы в одной горутине (ради детерминизма) с заданным темпом
+// и закрывает канал по достижении count или отмене контекста.
+func produce(ctx context.Context, jobs chan<- job, gen *generator, rate float64, count int) {
+	defer close(jobs)
+
+	var tick <-chan time.Time
+	if rate > 0 {
+		ticker := time.NewTicker(time.Duration(float64(time.Second) / rate))
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for sent := 0; count == 0 || sent < count; sent++ {
+		if tick != nil {
+			select {
+			case <-ctx.Done():
+				return
+			case <-tick:
+			}
+		}
+		order, kind := gen.Next()
+		select {
+		case <-ctx.Done():
+			return
+		case jobs <- job{order: order, invalidKind: kind}:
+		}
+	}
+}
+
+func partitionKey(strategy string) (func(models.Order) string, error) {
+	switch strategy {
+	case keyNone:
+		return func(models.Order) string { return "" }, nil
+	case keyOrderUID:
+		return func(o models.Order) string { return o.OrderUID }, nil
+	case keyCustomerID:
+		return func(o models.Order) string { return o.CustomerID }, nil
+	default:
+		return nil, fmt.Errorf("неизвестная стратегия ключа: %s", strategy)
+	}
+}
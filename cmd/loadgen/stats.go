@@ -0,0 +1,69 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sync"
+	"time"
+)
+
+// stats собирает результаты отправки со всех воркеров.
+type stats struct {
+	mu        sync.Mutex
+	latencies []time.Duration
+	errors    int
+	invalid   map[string]int
+}
+
+func newStats() *stats {
+	return &stats{invalid: make(map[string]int)}
+}
+
+func (s *stats) record(latency time.Duration, invalidKind string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil {
+		s.errors++
+		return
+	}
+	s.latencies = append(s.latencies, latency)
+	if invalidKind != "" {
+		s.invalid[invalidKind]++
+	}
+}
+
+// report - печатает достигнутую пропускную способность и задержку ack брокера.
+func (s *stats) report(w io.Writer, elapsed time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sent := len(s.latencies)
+	slices.Sort(s.latencies)
+
+	_, _ = fmt.Fprintf(w, "Отправлено: %d, ошибок: %d, за %s\n", sent, s.errors, elapsed.Round(time.Millisecond))
+	if elapsed > 0 {
+		_, _ = fmt.Fprintf(w, "Пропускная способность: %.1f msg/s\n", float64(sent)/elapsed.Seconds())
+	}
+	for _, kind := range invalidKinds {
+		if n := s.invalid[kind]; n > 0 {
+			_, _ = fmt.Fprintf(w, "Невалидных (%s): %d\n", kind, n)
+		}
+	}
+	if sent == 0 {
+		return
+	}
+	_, _ = fmt.Fprintf(w, "Задержка ack: min=%s p50=%s p95=%s p99=%s max=%s\n",
+		s.latencies[0],
+		percentile(s.latencies, 0.50),
+		percentile(s.latencies, 0.95),
+		percentile(s.latencies, 0.99),
+		s.latencies[sent-1],
+	)
+}
+
+// percentile ожидает отсортированный непустой срез.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
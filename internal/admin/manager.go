@@ -0,0 +1,300 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/cache"
+	"wb-project/internal/config"
+	"wb-project/internal/i18n"
+	"wb-project/internal/kafka"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// ErrInvalid - некорректные параметры действия.
+var ErrInvalid = errors.New("некорректный запрос")
+
+// Действия admin API, под этими именами они попадают в журнал аудита и метрики.
+const (
+	ActionCacheStats     = "cache.stats"
+	ActionCacheEntries   = "cache.entries"
+	ActionCacheInspect   = "cache.inspect"
+	ActionCacheEvict     = "cache.evict"
+	ActionCacheFlush     = "cache.flush"
+	ActionCacheReload    = "cache.reload"
+	ActionConsumerStatus = "consumer.position"
+	ActionConsumerPause  = "consumer.pause"
+	ActionConsumerResume = "consumer.resume"
+	ActionConsumerSeek   = "consumer.seek"
+	ActionConfigReload   = "config.reload"
+)
+
+//go:generate mockery --name=Cache --output=./mocks --case=underscore
+type Cache interface {
+	Stats() cache.Stats
+	Entries(limit int) ([]cache.Entry, int)
+	Inspect(uid string) (cache.Entry, models.EncodedOrder, bool)
+	Delete(uid string) bool
+	Flush() int
+}
+
+// Recacher - повторный разогрев кэша из БД.
+//
+//go:generate mockery --name=Recacher --output=./mocks --case=underscore
+type Recacher interface {
+	ReCache(ctx context.Context) error
+}
+
+//go:generate mockery --name=Consumer --output=./mocks --case=underscore
+type Consumer interface {
+	Pause() error
+	Resume() error
+	SeekOffset(ctx context.Context, offset int64) (int64, error)
+	SeekTime(ctx context.Context, t time.Time) (int64, error)
+	Position(ctx context.Context) (kafka.Position, error)
+}
+
+// Auditor - журнал действий через admin API.
+//
+//go:generate mockery --name=Auditor --output=./mocks --case=underscore
+type Auditor interface {
+	RecordAdminAction(ctx context.Context, a models.AdminAction) error
+}
+
+// ConfigReloader - перечитывание конфигурации без перезапуска.
+//
+//go:generate mockery --name=ConfigReloader --output=./mocks --case=underscore
+type ConfigReloader interface {
+	Reload() (config.ReloadResult, error)
+}
+
+// Seek - куда перемотать консьюмер: задается ровно одно из полей.
+type Seek struct {
+	Offset    *int64     `json:"offset,omitempty"`
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+}
+
+// Manager - операции над кэшем и консьюмером без перезапуска сервиса.
+// Каждое действие выполняется в своем спане и пишется в журнал аудита.
+type Manager struct {
+	cache    Cache
+	recacher Recacher
+	consumer Consumer
+	auditor  Auditor
+	config   ConfigReloader // необязательный
+}
+
+func NewManager(c Cache, r Recacher, consumer Consumer, auditor Auditor) *Manager {
+	return &Manager{cache: c, recacher: r, consumer: consumer, auditor: auditor}
+}
+
+// WithConfig подключает перезагрузку конфигурации.
+func (m *Manager) WithConfig(r ConfigReloader) *Manager {
+	m.config = r
+	return m
+}
+
+func (m *Manager) CacheStats(ctx context.Context) cache.Stats {
+	var stats cache.Stats
+	_ = m.do(ctx, ActionCacheStats, "", nil, func(context.Context) error {
+		stats = m.cache.Stats()
+		return nil
+	})
+	return stats
+}
+
+// CacheEntries - первые limit записей кэша и их общее число.
+func (m *Manager) CacheEntries(ctx context.Context, limit int) ([]cache.Entry, int) {
+	var (
+		entries []cache.Entry
+		total   int
+	)
+	_ = m.do(ctx, ActionCacheEntries, "", map[string]string{"limit": strconv.Itoa(limit)}, func(context.Context) error {
+		entries, total = m.cache.Entries(limit)
+		return nil
+	})
+	return entries, total
+}
+
+// CacheEntry - запись кэша вместе с заказом; models.ErrNotFound, если заказа в кэше нет.
+func (m *Manager) CacheEntry(ctx context.Context, uid string) (cache.Entry, models.EncodedOrder, error) {
+	var (
+		entry   cache.Entry
+		encoded models.EncodedOrder
+	)
+	err := m.do(ctx, ActionCacheInspect, uid, nil, func(context.Context) error {
+		var ok bool
+		if entry, encoded, ok = m.cache.Inspect(uid); !ok {
+			return models.ErrNotFound
+		}
+		return nil
+	})
+	return entry, encoded, err
+}
+
+// Evict - удаляет заказ из кэша, следующий запрос прочитает его из БД.
+func (m *Manager) Evict(ctx context.Context, uid string) error {
+	return m.do(ctx, ActionCacheEvict, uid, nil, func(context.Context) error {
+		if !m.cache.Delete(uid) {
+			return models.ErrNotFound
+		}
+		return nil
+	})
+}
+
+// Flush - очищает кэш целиком, возвращает число удаленных записей.
+func (m *Manager) Flush(ctx context.Context) int {
+	var n int
+	_ = m.do(ctx, ActionCacheFlush, "", nil, func(context.Context) error {
+		n = m.cache.Flush()
+		return nil
+	})
+	return n
+}
+
+// Reload - заново загружает заказы из БД в кэш, возвращает размер кэша после загрузки.
+func (m *Manager) Reload(ctx context.Context) (int, error) {
+	err := m.do(ctx, ActionCacheReload, "", nil, m.recacher.ReCache)
+	if err != nil {
+		return 0, err
+	}
+	return m.cache.Stats().Items, nil
+}
+
+func (m *Manager) ConsumerPosition(ctx context.Context) (kafka.Position, error) {
+	var pos kafka.Position
+	err := m.do(ctx, ActionConsumerStatus, "", nil, func(ctx context.Context) error {
+		var err error
+		pos, err = m.consumer.Position(ctx)
+		return err
+	})
+	return pos, err
+}
+
+func (m *Manager) PauseConsumer(ctx context.Context) error {
+	return m.do(ctx, ActionConsumerPause, "", nil, func(context.Context) error {
+		return m.consumer.Pause()
+	})
+}
+
+func (m *Manager) ResumeConsumer(ctx context.Context) error {
+	return m.do(ctx, ActionConsumerResume, "", nil, func(context.Context) error {
+		return m.consumer.Resume()
+	})
+}
+
+// SeekConsumer - перематывает консьюмер для повторной обработки, возвращает итоговый offset.
+func (m *Manager) SeekConsumer(ctx context.Context, s Seek) (int64, error) {
+	var offset int64
+	params := map[string]string{}
+	if s.Offset != nil {
+		params["offset"] = strconv.FormatInt(*s.Offset, 10)
+	}
+	if s.Timestamp != nil {
+		params["timestamp"] = s.Timestamp.UTC().Format(time.RFC3339Nano)
+	}
+	err := m.do(ctx, ActionConsumerSeek, "", params, func(ctx context.Context) error {
+		var err error
+		switch {
+		case (s.Offset == nil) == (s.Timestamp == nil):
+			return fmt.Errorf("%w: %w", ErrInvalid, i18n.Errorf(i18n.MsgSeekTargetRequired))
+		case s.Offset != nil:
+			offset, err = m.consumer.SeekOffset(ctx, *s.Offset)
+		default:
+			offset, err = m.consumer.SeekTime(ctx, *s.Timestamp)
+		}
+		if err == nil {
+			params["resolved_offset"] = strconv.FormatInt(offset, 10)
+		}
+		return err
+	})
+	return offset, err
+}
+
+// ReloadConfig - перечитывает конфигурацию и применяет изменяемые на лету параметры.
+// При отказе (config.ErrReloadRejected) продолжает действовать прежняя конфигурация.
+func (m *Manager) ReloadConfig(ctx context.Context) (config.ReloadResult, error) {
+	var res config.ReloadResult
+	params := map[string]string{}
+	err := m.do(ctx, ActionConfigReload, "", params, func(context.Context) error {
+		if m.config == nil {
+			return fmt.Errorf("%w: перезагрузка конфигурации не настроена", config.ErrReloadRejected)
+		}
+		var err error
+		res, err = m.config.Reload()
+		params["version"] = strconv.FormatInt(res.Version, 10)
+		for _, c := range res.Changes {
+			params[c.Path] = c.Old + " -> " + c.New
+		}
+		return err
+	})
+	return res, err
+}
+
+// do - выполняет действие в спане и пишет результат в журнал аудита.
+// Недоступный журнал действие не отменяет: во время инцидента БД может лежать,
+// а сбросить кэш или остановить консьюмер все равно нужно. Ошибка записи видна в логе и метрике.
+func (m *Manager) do(ctx context.Context, action, target string, params map[string]string, fn func(context.Context) error) error {
+	//1. trace
+	ctx, span := otel.Tracer("admin").Start(ctx, "Admin."+action)
+	defer span.End()
+
+	p, _ := auth.FromContext(ctx)
+	span.SetAttributes(
+		attribute.String("admin.action", action),
+		attribute.String("admin.subject", p.Subject),
+	)
+	if target != "" {
+		span.SetAttributes(attribute.String("admin.target", target))
+	}
+
+	//2. само действие
+	err := fn(ctx)
+	result := "success"
+	if err != nil {
+		result = "error"
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	metric.AdminActionsTotal.WithLabelValues(action, result).Inc()
+	slog.Info("действие через admin API",
+		slog.String("action", action),
+		slog.String("target", target),
+		slog.Any("params", params),
+		slog.String("subject", p.Subject),
+		slog.String("result", result),
+		sl.Traced(ctx))
+
+	//3. аудит
+	record := models.AdminAction{
+		Subject: p.Subject,
+		Role:    p.Role.String(),
+		Action:  action,
+		Target:  target,
+		Params:  params,
+		Result:  result,
+		At:      time.Now().UTC(),
+	}
+	if err != nil {
+		record.Error = err.Error()
+	}
+	if auditErr := m.auditor.RecordAdminAction(ctx, record); auditErr != nil {
+		metric.AdminAuditErrorsTotal.Inc()
+		span.RecordError(auditErr)
+		slog.Error("не удалось записать действие в журнал аудита",
+			slog.String("action", action),
+			slog.Any("error", auditErr),
+			sl.Traced(ctx))
+	}
+	return err
+}
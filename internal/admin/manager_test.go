@@ -0,0 +1,133 @@
+package admin
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	"wb-project/internal/admin/mocks"
+	"wb-project/internal/auth"
+	"wb-project/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestManager(t *testing.T) (*Manager, *mocks.Cache, *mocks.Recacher, *mocks.Consumer, *mocks.Auditor) {
+	c := mocks.NewCache(t)
+	r := mocks.NewRecacher(t)
+	consumer := mocks.NewConsumer(t)
+	auditor := mocks.NewAuditor(t)
+	return NewManager(c, r, consumer, auditor), c, r, consumer, auditor
+}
+
+func adminContext() context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{Subject: "ops", Role: auth.RoleAdmin})
+}
+
+func TestManager_Evict(t *testing.T) {
+	t.Run("Удаление пишется в журнал аудита", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		m, c, _, _, auditor := newTestManager(t)
+		c.On("Delete", "b563feb7b2b84b6test").Return(true)
+		auditor.On("RecordAdminAction", mock.Anything, mock.MatchedBy(func(a models.AdminAction) bool {
+			return a.Subject == "ops" && a.Role == "admin" && a.Action == ActionCacheEvict &&
+				a.Target == "b563feb7b2b84b6test" && a.Result == "success" && a.Error == ""
+		})).Return(nil)
+
+		//2. Act(Действие)
+		err := m.Evict(adminContext(), "b563feb7b2b84b6test")
+
+		//3. Assert
+		assert.NoError(t, err)
+	})
+
+	t.Run("Заказа нет в кэше", func(t *testing.T) {
+		m, c, _, _, auditor := newTestManager(t)
+		c.On("Delete", "unknown").Return(false)
+		auditor.On("RecordAdminAction", mock.Anything, mock.MatchedBy(func(a models.AdminAction) bool {
+			return a.Result == "error" && a.Error == models.ErrNotFound.Error()
+		})).Return(nil)
+
+		err := m.Evict(adminContext(), "unknown")
+
+		assert.ErrorIs(t, err, models.ErrNotFound)
+	})
+}
+
+// Недоступный журнал не должен мешать действиям во время инцидента.
+func TestManager_AuditFailureDoesNotBlock(t *testing.T) {
+	//1. Arrange(подготовка)
+	m, c, _, _, auditor := newTestManager(t)
+	c.On("Flush").Return(42)
+	auditor.On("RecordAdminAction", mock.Anything, mock.Anything).Return(errors.New("connection refused"))
+
+	//2. Act(Действие)
+	n := m.Flush(adminContext())
+
+	//3. Assert
+	assert.Equal(t, 42, n)
+}
+
+func TestManager_SeekConsumer(t *testing.T) {
+	offset := int64(17)
+	at := time.Date(2026, 3, 1, 10, 0, 0, 0, time.UTC)
+
+	t.Run("По offset", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		m, _, _, consumer, auditor := newTestManager(t)
+		consumer.On("SeekOffset", mock.Anything, offset).Return(offset, nil)
+		auditor.On("RecordAdminAction", mock.Anything, mock.MatchedBy(func(a models.AdminAction) bool {
+			return a.Action == ActionConsumerSeek && a.Params["offset"] == "17" && a.Params["resolved_offset"] == "17"
+		})).Return(nil)
+
+		//2. Act(Действие)
+		got, err := m.SeekConsumer(adminContext(), Seek{Offset: &offset})
+
+		//3. Assert
+		require.NoError(t, err)
+		assert.Equal(t, offset, got)
+	})
+
+	t.Run("По времени", func(t *testing.T) {
+		m, _, _, consumer, auditor := newTestManager(t)
+		consumer.On("SeekTime", mock.Anything, at).Return(int64(5), nil)
+		auditor.On("RecordAdminAction", mock.Anything, mock.MatchedBy(func(a models.AdminAction) bool {
+			return a.Params["timestamp"] == "2026-03-01T10:00:00Z" && a.Params["resolved_offset"] == "5"
+		})).Return(nil)
+
+		got, err := m.SeekConsumer(adminContext(), Seek{Timestamp: &at})
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(5), got)
+	})
+
+	t.Run("Нужно ровно одно из offset и timestamp", func(t *testing.T) {
+		m, _, _, consumer, auditor := newTestManager(t)
+		auditor.On("RecordAdminAction", mock.Anything, mock.Anything).Return(nil)
+
+		_, errNone := m.SeekConsumer(adminContext(), Seek{})
+		_, errBoth := m.SeekConsumer(adminContext(), Seek{Offset: &offset, Timestamp: &at})
+
+		assert.ErrorIs(t, errNone, ErrInvalid)
+		assert.ErrorIs(t, errBoth, ErrInvalid)
+		consumer.AssertNotCalled(t, "SeekOffset", mock.Anything, mock.Anything)
+	})
+}
+
+func TestManager_Reload(t *testing.T) {
+	//1. Arrange(подготовка)
+	m, c, r, _, auditor := newTestManager(t)
+	r.On("ReCache", mock.Anything).Return(errors.New("db is down"))
+	auditor.On("RecordAdminAction", mock.Anything, mock.MatchedBy(func(a models.AdminAction) bool {
+		return a.Action == ActionCacheReload && a.Result == "error"
+	})).Return(nil)
+
+	//2. Act(Действие)
+	_, err := m.Reload(adminContext())
+
+	//3. Assert
+	assert.EqualError(t, err, "db is down")
+	c.AssertNotCalled(t, "Stats")
+}
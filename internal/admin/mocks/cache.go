@@ -0,0 +1,149 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	cache "wb-project/internal/cache"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "wb-project/internal/models"
+)
+
+// Cache is an autogenerated mock type for the Cache type
+type Cache struct {
+	mock.Mock
+}
+
+// Delete provides a mock function with given fields: uid
+func (_m *Cache) Delete(uid string) bool {
+	ret := _m.Called(uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Delete")
+	}
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	return r0
+}
+
+// Entries provides a mock function with given fields: limit
+func (_m *Cache) Entries(limit int) ([]cache.Entry, int) {
+	ret := _m.Called(limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Entries")
+	}
+
+	var r0 []cache.Entry
+	var r1 int
+	if rf, ok := ret.Get(0).(func(int) ([]cache.Entry, int)); ok {
+		return rf(limit)
+	}
+	if rf, ok := ret.Get(0).(func(int) []cache.Entry); ok {
+		r0 = rf(limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]cache.Entry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int) int); ok {
+		r1 = rf(limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	return r0, r1
+}
+
+// Flush provides a mock function with no fields
+func (_m *Cache) Flush() int {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func() int); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// Inspect provides a mock function with given fields: uid
+func (_m *Cache) Inspect(uid string) (cache.Entry, models.EncodedOrder, bool) {
+	ret := _m.Called(uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Inspect")
+	}
+
+	var r0 cache.Entry
+	var r1 models.EncodedOrder
+	var r2 bool
+	if rf, ok := ret.Get(0).(func(string) (cache.Entry, models.EncodedOrder, bool)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(string) cache.Entry); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Get(0).(cache.Entry)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) models.EncodedOrder); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Get(1).(models.EncodedOrder)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) bool); ok {
+		r2 = rf(uid)
+	} else {
+		r2 = ret.Get(2).(bool)
+	}
+
+	return r0, r1, r2
+}
+
+// Stats provides a mock function with no fields
+func (_m *Cache) Stats() cache.Stats {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Stats")
+	}
+
+	var r0 cache.Stats
+	if rf, ok := ret.Get(0).(func() cache.Stats); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(cache.Stats)
+	}
+
+	return r0
+}
+
+// NewCache creates a new instance of Cache. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewCache(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Cache {
+	mock := &Cache{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,56 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	config "wb-project/internal/config"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// ConfigReloader is an autogenerated mock type for the ConfigReloader type
+type ConfigReloader struct {
+	mock.Mock
+}
+
+// Reload provides a mock function with no fields
+func (_m *ConfigReloader) Reload() (config.ReloadResult, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reload")
+	}
+
+	var r0 config.ReloadResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (config.ReloadResult, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() config.ReloadResult); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(config.ReloadResult)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewConfigReloader creates a new instance of ConfigReloader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConfigReloader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *ConfigReloader {
+	mock := &ConfigReloader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,151 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	kafka "wb-project/internal/kafka"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Consumer is an autogenerated mock type for the Consumer type
+type Consumer struct {
+	mock.Mock
+}
+
+// Pause provides a mock function with no fields
+func (_m *Consumer) Pause() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Pause")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Position provides a mock function with given fields: ctx
+func (_m *Consumer) Position(ctx context.Context) (kafka.Position, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Position")
+	}
+
+	var r0 kafka.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (kafka.Position, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) kafka.Position); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(kafka.Position)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Resume provides a mock function with no fields
+func (_m *Consumer) Resume() error {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for Resume")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func() error); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SeekOffset provides a mock function with given fields: ctx, offset
+func (_m *Consumer) SeekOffset(ctx context.Context, offset int64) (int64, error) {
+	ret := _m.Called(ctx, offset)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SeekOffset")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int64) (int64, error)); ok {
+		return rf(ctx, offset)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int64) int64); ok {
+		r0 = rf(ctx, offset)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int64) error); ok {
+		r1 = rf(ctx, offset)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SeekTime provides a mock function with given fields: ctx, t
+func (_m *Consumer) SeekTime(ctx context.Context, t time.Time) (int64, error) {
+	ret := _m.Called(ctx, t)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SeekTime")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, t)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, t)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, t)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewConsumer creates a new instance of Consumer. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewConsumer(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Consumer {
+	mock := &Consumer{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,74 @@
+package app
+
+import (
+	"context"
+	"net"
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/auth"
+	"wb-project/internal/handler"
+	"wb-project/internal/trace"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// GRPCServer - gRPC API заказов, работает рядом с HTTP сервером.
+type GRPCServer struct {
+	server *grpc.Server
+	health *health.Server
+}
+
+func NewGRPCServer(orderHandler *handler.GRPCOrderHandler, authenticator *auth.Authenticator) *GRPCServer {
+	server := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(
+			trace.UnaryServerInterceptor(),
+			auth.UnaryServerInterceptor(authenticator, auth.RoleViewer),
+		),
+		grpc.ChainStreamInterceptor(
+			trace.StreamServerInterceptor(),
+			auth.StreamServerInterceptor(authenticator, auth.RoleViewer),
+		),
+	)
+	orderv1.RegisterOrderServiceServer(server, orderHandler)
+
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(server, healthServer)
+	reflection.Register(server)
+
+	return &GRPCServer{server: server, health: healthServer}
+}
+
+func (s *GRPCServer) Run(addr string) error {
+	lis, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+	return s.server.Serve(lis)
+}
+
+// Drain - health-сервис начинает отвечать NOT_SERVING, активные вызовы продолжаются.
+func (s *GRPCServer) Drain() {
+	s.health.Shutdown()
+}
+
+// Stop - дожидается завершения активных вызовов, но не дольше ctx:
+// открытые WatchOrders сами не завершатся, поэтому по дедлайну соединения рвутся.
+func (s *GRPCServer) Stop(ctx context.Context) error {
+	s.health.Shutdown()
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.server.Stop()
+		return ctx.Err()
+	}
+}
@@ -2,22 +2,47 @@ package app
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"wb-project/internal/auth"
+	"wb-project/internal/config"
 	"wb-project/internal/handler"
+
+	"github.com/gin-gonic/gin"
 )
 
 type Server struct {
 	httpServer *http.Server
+	router     *gin.Engine
 }
 
-func NewServer(orderHandler *handler.OrderHandler) *Server {
-	router := handler.NewRouter(orderHandler)
+func NewServer(orderHandler *handler.OrderHandler, webhookHandler *handler.WebhookHandler, streamHandler *handler.StreamHandler, healthHandler *handler.HealthHandler, adminHandler *handler.AdminHandler, authenticator *auth.Authenticator, limits *handler.Limits) *Server {
+	router := handler.NewRouter(orderHandler, webhookHandler, streamHandler, healthHandler, adminHandler, authenticator, limits)
 
 	return &Server{
 		httpServer: &http.Server{
 			Handler: router,
 		},
+		router: router,
+	}
+}
+
+// WithTrustedProxies - адреса или подсети балансировщиков, чьим X-Forwarded-For
+// можно верить при определении IP клиента. Без них IP берется из соединения.
+func (s *Server) WithTrustedProxies(proxies []string) (*Server, error) {
+	if err := s.router.SetTrustedProxies(proxies); err != nil {
+		return nil, fmt.Errorf("доверенные прокси: %w", err)
 	}
+	return s, nil
+}
+
+// WithTimeouts - таймауты чтения и записи HTTP сервера.
+func (s *Server) WithTimeouts(conf config.HTTPConfig) *Server {
+	s.httpServer.ReadHeaderTimeout = conf.ReadHeaderTimeout
+	s.httpServer.ReadTimeout = conf.ReadTimeout
+	s.httpServer.WriteTimeout = conf.WriteTimeout
+	s.httpServer.IdleTimeout = conf.IdleTimeout
+	return s
 }
 
 func (s *Server) Run(addr string) error {
@@ -0,0 +1,65 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// APIKeys - статические ключи. В конфигурации хранятся только SHA-256 хеши,
+// сами ключи знают лишь их владельцы.
+type APIKeys struct {
+	byHash map[string]Principal
+}
+
+// ParseAPIKeys - разбирает список "имя:роль:sha256hex" через запятую.
+func ParseAPIKeys(spec string) (*APIKeys, error) {
+	keys := &APIKeys{byHash: map[string]Principal{}}
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("ожидается имя:роль:sha256, получено %q", entry)
+		}
+		name, roleName, hash := parts[0], parts[1], strings.ToLower(parts[2])
+		role, err := ParseRole(roleName)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %s: %w", name, err)
+		}
+		if raw, err := hex.DecodeString(hash); err != nil || len(raw) != sha256.Size {
+			return nil, fmt.Errorf("ключ %s: хеш должен быть SHA-256 в hex", name)
+		}
+		keys.byHash[hash] = Principal{Subject: name, Role: role, Method: MethodAPIKey}
+	}
+	return keys, nil
+}
+
+// Lookup - владелец ключа. Сравниваются хеши, поэтому время поиска
+// не зависит от того, сколько символов ключа угадано.
+func (k *APIKeys) Lookup(key string) (Principal, bool) {
+	p, ok := k.byHash[HashKey(key)]
+	return p, ok
+}
+
+func (k *APIKeys) Len() int {
+	return len(k.byHash)
+}
+
+// HashKey - значение для конфигурации AUTH_API_KEYS.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// NewKey - случайный API-ключ.
+func NewKey() string {
+	buf := make([]byte, 32)
+	_, _ = rand.Read(buf)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
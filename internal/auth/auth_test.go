@@ -0,0 +1,125 @@
+package auth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAPIKeys(t *testing.T) {
+	keys, err := ParseAPIKeys("ui:viewer:" + HashKey("secret-1") + ", ops:admin:" + HashKey("secret-2"))
+	require.NoError(t, err)
+
+	p, ok := keys.Lookup("secret-2")
+	assert.True(t, ok)
+	assert.Equal(t, Principal{Subject: "ops", Role: RoleAdmin, Method: MethodAPIKey}, p)
+
+	_, ok = keys.Lookup("secret-3")
+	assert.False(t, ok)
+
+	for _, spec := range []string{"ui:viewer", "ui:root:" + HashKey("x"), "ui:viewer:abc"} {
+		_, err := ParseAPIKeys(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+type tokenIssuer struct {
+	signer jose.Signer
+	jwks   jose.JSONWebKeySet
+}
+
+func newTokenIssuer(t *testing.T) tokenIssuer {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: jose.ES256, Key: jose.JSONWebKey{Key: key, KeyID: "k1"}}, nil)
+	require.NoError(t, err)
+	return tokenIssuer{
+		signer: signer,
+		jwks:   jose.JSONWebKeySet{Keys: []jose.JSONWebKey{{Key: key.Public(), KeyID: "k1", Algorithm: string(jose.ES256)}}},
+	}
+}
+
+func (i tokenIssuer) token(t *testing.T, claims jwt.Claims, extra map[string]any) string {
+	token, err := jwt.Signed(i.signer).Claims(claims).Claims(extra).Serialize()
+	require.NoError(t, err)
+	return token
+}
+
+func TestJWTVerifier(t *testing.T) {
+	//1. Arrange(подготовка)
+	issuer := newTokenIssuer(t)
+	verifier := NewJWTVerifier(issuer.jwks, JWTConfig{Issuer: "idp", Audience: "orders"})
+	now := time.Now()
+	valid := jwt.Claims{Subject: "alice", Issuer: "idp", Audience: jwt.Audience{"orders"}, Expiry: jwt.NewNumericDate(now.Add(time.Hour))}
+
+	t.Run("Старшая роль из массива", func(t *testing.T) {
+		p, err := verifier.Verify(issuer.token(t, valid, map[string]any{"role": []string{"viewer", "support", "unknown"}}))
+
+		assert.NoError(t, err)
+		assert.Equal(t, Principal{Subject: "alice", Role: RoleSupport, Method: MethodJWT}, p)
+	})
+
+	t.Run("Истекший токен", func(t *testing.T) {
+		expired := valid
+		expired.Expiry = jwt.NewNumericDate(now.Add(-time.Hour))
+
+		_, err := verifier.Verify(issuer.token(t, expired, map[string]any{"role": "admin"}))
+
+		assert.ErrorIs(t, err, ErrExpired)
+	})
+
+	t.Run("Чужая аудитория", func(t *testing.T) {
+		other := valid
+		other.Audience = jwt.Audience{"billing"}
+
+		_, err := verifier.Verify(issuer.token(t, other, map[string]any{"role": "admin"}))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Подпись другим ключом", func(t *testing.T) {
+		stranger := newTokenIssuer(t)
+
+		_, err := verifier.Verify(stranger.token(t, valid, map[string]any{"role": "admin"}))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("Без роли", func(t *testing.T) {
+		_, err := verifier.Verify(issuer.token(t, valid, nil))
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+}
+
+func TestAuthenticator(t *testing.T) {
+	keys, err := ParseAPIKeys("ui:viewer:" + HashKey("secret"))
+	require.NoError(t, err)
+	a := NewAuthenticator(true, keys, nil)
+
+	p, method, err := a.Authenticate("", "secret")
+	assert.NoError(t, err)
+	assert.Equal(t, MethodAPIKey, method)
+	assert.Equal(t, RoleViewer, p.Role)
+
+	_, _, err = a.Authenticate("ApiKey secret", "")
+	assert.NoError(t, err)
+
+	_, _, err = a.Authenticate("", "")
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+
+	_, method, err = a.Authenticate("Bearer abc", "")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+	assert.Equal(t, MethodJWT, method)
+
+	p, _, err = Disabled().Authenticate("", "")
+	assert.NoError(t, err)
+	assert.Equal(t, RoleViewer, p.Role, "без аутентификации нет ни admin API, ни немаскированных данных")
+}
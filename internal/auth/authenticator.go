@@ -0,0 +1,70 @@
+package auth
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrMissingCredentials = errors.New("учетные данные не переданы")
+	ErrInvalidCredentials = errors.New("неверные учетные данные")
+	ErrExpired            = errors.New("срок действия токена истек")
+)
+
+// Authenticator - проверяет учетные данные запроса. Выключенный аутентификатор
+// (только для локальной разработки) пропускает всех как viewer: данные маскируются,
+// admin API и view=unmasked недоступны.
+type Authenticator struct {
+	enabled bool
+	keys    *APIKeys
+	jwt     *JWTVerifier // nil, если JWKS не настроен
+}
+
+func NewAuthenticator(enabled bool, keys *APIKeys, verifier *JWTVerifier) *Authenticator {
+	if keys == nil {
+		keys = &APIKeys{byHash: map[string]Principal{}}
+	}
+	return &Authenticator{enabled: enabled, keys: keys, jwt: verifier}
+}
+
+// Disabled - аутентификатор, пропускающий все запросы с ролью viewer.
+func Disabled() *Authenticator {
+	return NewAuthenticator(false, nil, nil)
+}
+
+func (a *Authenticator) Enabled() bool {
+	return a.enabled
+}
+
+// Authenticate - по значению Authorization ("Bearer <jwt>" или "ApiKey <key>")
+// и отдельному заголовку с API-ключом. Ошибка сопровождается методом для метрик.
+func (a *Authenticator) Authenticate(authorization, apiKey string) (Principal, string, error) {
+	if !a.enabled {
+		return Principal{Subject: MethodAnonymous, Role: RoleViewer, Method: MethodAnonymous}, MethodAnonymous, nil
+	}
+
+	scheme, credentials, _ := strings.Cut(strings.TrimSpace(authorization), " ")
+	credentials = strings.TrimSpace(credentials)
+	switch {
+	case apiKey != "":
+		return a.byAPIKey(apiKey)
+	case strings.EqualFold(scheme, "ApiKey") && credentials != "":
+		return a.byAPIKey(credentials)
+	case strings.EqualFold(scheme, "Bearer") && credentials != "":
+		if a.jwt == nil {
+			return Principal{}, MethodJWT, ErrInvalidCredentials
+		}
+		p, err := a.jwt.Verify(credentials)
+		return p, MethodJWT, err
+	default:
+		return Principal{}, "", ErrMissingCredentials
+	}
+}
+
+func (a *Authenticator) byAPIKey(key string) (Principal, string, error) {
+	p, ok := a.keys.Lookup(key)
+	if !ok {
+		return Principal{}, MethodAPIKey, ErrInvalidCredentials
+	}
+	return p, MethodAPIKey, nil
+}
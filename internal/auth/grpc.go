@@ -0,0 +1,102 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"wb-project/internal/metric"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Health check и reflection доступны без учетных данных.
+var publicGRPCServices = map[string]bool{
+	"/grpc.health.v1.Health/Check":                                   true,
+	"/grpc.health.v1.Health/Watch":                                   true,
+	"/grpc.health.v1.Health/List":                                    true,
+	"/grpc.reflection.v1.ServerReflection/ServerReflectionInfo":      true,
+	"/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo": true,
+}
+
+// UnaryServerInterceptor - аутентификация по metadata authorization / x-api-key,
+// для вызова нужна роль не ниже required.
+func UnaryServerInterceptor(a *Authenticator, required Role) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if publicGRPCServices[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		ctx, err := authenticateGRPC(ctx, a, required)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(a *Authenticator, required Role) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if publicGRPCServices[info.FullMethod] {
+			return handler(srv, ss)
+		}
+		ctx, err := authenticateGRPC(ss.Context(), a, required)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &authenticatedStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type authenticatedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *authenticatedStream) Context() context.Context {
+	return s.ctx
+}
+
+func authenticateGRPC(ctx context.Context, a *Authenticator, required Role) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+
+	p, method, err := a.Authenticate(first("authorization"), first("x-api-key"))
+	if err != nil {
+		metric.AuthAttemptsTotal.WithLabelValues(MetricMethod(method), FailureReason(err)).Inc()
+		return ctx, status.Error(codes.Unauthenticated, err.Error())
+	}
+	if !p.Allows(required) {
+		metric.AuthAttemptsTotal.WithLabelValues(p.Method, "forbidden").Inc()
+		return ctx, status.Error(codes.PermissionDenied, "недостаточно прав")
+	}
+	if a.Enabled() {
+		metric.AuthAttemptsTotal.WithLabelValues(p.Method, "success").Inc()
+	}
+	return WithPrincipal(ctx, p), nil
+}
+
+// MetricMethod - значение метки method, когда способ не удалось определить.
+func MetricMethod(method string) string {
+	if method == "" {
+		return "none"
+	}
+	return method
+}
+
+// FailureReason - значение метки result для неуспешной аутентификации.
+func FailureReason(err error) string {
+	switch {
+	case errors.Is(err, ErrMissingCredentials):
+		return "missing"
+	case errors.Is(err, ErrExpired):
+		return "expired"
+	default:
+		return "invalid"
+	}
+}
@@ -0,0 +1,124 @@
+package auth
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/go-jose/go-jose/v4"
+	"github.com/go-jose/go-jose/v4/jwt"
+)
+
+// Алгоритмы подписи, которые принимаются от выпускающего токены.
+var signatureAlgorithms = []jose.SignatureAlgorithm{jose.RS256, jose.PS256, jose.ES256, jose.EdDSA}
+
+// JWTConfig - параметры проверки токенов.
+type JWTConfig struct {
+	Issuer    string
+	Audience  string
+	RoleClaim string // claim с ролью: строка или массив строк
+	Leeway    time.Duration
+}
+
+// JWTVerifier - проверяет bearer-токены по ключам из локального JWKS.
+type JWTVerifier struct {
+	keys jose.JSONWebKeySet
+	cfg  JWTConfig
+	now  func() time.Time
+}
+
+// LoadJWKS - читает набор публичных ключей из файла.
+func LoadJWKS(path string) (jose.JSONWebKeySet, error) {
+	var set jose.JSONWebKeySet
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return set, fmt.Errorf("чтение JWKS: %w", err)
+	}
+	if err := json.Unmarshal(data, &set); err != nil {
+		return set, fmt.Errorf("разбор JWKS: %w", err)
+	}
+	if len(set.Keys) == 0 {
+		return set, errors.New("JWKS не содержит ключей")
+	}
+	return set, nil
+}
+
+func NewJWTVerifier(keys jose.JSONWebKeySet, cfg JWTConfig) *JWTVerifier {
+	if cfg.RoleClaim == "" {
+		cfg.RoleClaim = "role"
+	}
+	return &JWTVerifier{keys: keys, cfg: cfg, now: time.Now}
+}
+
+// Verify - проверяет подпись, срок действия, iss/aud и возвращает вызывающего.
+func (v *JWTVerifier) Verify(token string) (Principal, error) {
+	tok, err := jwt.ParseSigned(token, signatureAlgorithms)
+	if err != nil {
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if len(tok.Headers) == 0 {
+		return Principal{}, ErrInvalidCredentials
+	}
+
+	candidates := v.keys.Keys
+	if kid := tok.Headers[0].KeyID; kid != "" {
+		candidates = v.keys.Key(kid)
+	}
+
+	var claims jwt.Claims
+	var custom map[string]any
+	verified := false
+	for _, key := range candidates {
+		if err := tok.Claims(key.Public().Key, &claims, &custom); err == nil {
+			verified = true
+			break
+		}
+	}
+	if !verified {
+		return Principal{}, fmt.Errorf("%w: подпись не подходит ни к одному ключу", ErrInvalidCredentials)
+	}
+
+	expected := jwt.Expected{Issuer: v.cfg.Issuer, Time: v.now()}
+	if v.cfg.Audience != "" {
+		expected.AnyAudience = jwt.Audience{v.cfg.Audience}
+	}
+	if err := claims.ValidateWithLeeway(expected, v.cfg.Leeway); err != nil {
+		if errors.Is(err, jwt.ErrExpired) {
+			return Principal{}, ErrExpired
+		}
+		return Principal{}, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+	if claims.Expiry == nil {
+		return Principal{}, fmt.Errorf("%w: токен без exp", ErrInvalidCredentials)
+	}
+
+	role := highestRole(custom[v.cfg.RoleClaim])
+	if role == RoleNone {
+		return Principal{}, fmt.Errorf("%w: в токене нет известной роли", ErrInvalidCredentials)
+	}
+	return Principal{Subject: claims.Subject, Role: role, Method: MethodJWT}, nil
+}
+
+// highestRole - старшая из известных ролей claim'а; неизвестные игнорируются.
+func highestRole(claim any) Role {
+	var names []string
+	switch v := claim.(type) {
+	case string:
+		names = []string{v}
+	case []any:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				names = append(names, s)
+			}
+		}
+	}
+	best := RoleNone
+	for _, name := range names {
+		if role, err := ParseRole(name); err == nil && role > best {
+			best = role
+		}
+	}
+	return best
+}
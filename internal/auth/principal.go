@@ -0,0 +1,77 @@
+// Package auth отвечает за аутентификацию вызывающих API: статические
+// API-ключи и JWT, подписанные ключами из локального JWKS, и их роли.
+package auth
+
+import (
+	"context"
+	"fmt"
+)
+
+// Role - уровень доступа. Роли упорядочены: каждая следующая включает права предыдущей.
+type Role int
+
+const (
+	RoleNone Role = iota
+	RoleViewer
+	RoleSupport
+	RoleAdmin
+)
+
+func (r Role) String() string {
+	switch r {
+	case RoleViewer:
+		return "viewer"
+	case RoleSupport:
+		return "support"
+	case RoleAdmin:
+		return "admin"
+	default:
+		return "none"
+	}
+}
+
+// ParseRole - роль по имени из конфигурации или claim токена.
+func ParseRole(name string) (Role, error) {
+	switch name {
+	case "viewer":
+		return RoleViewer, nil
+	case "support":
+		return RoleSupport, nil
+	case "admin":
+		return RoleAdmin, nil
+	default:
+		return RoleNone, fmt.Errorf("неизвестная роль %q", name)
+	}
+}
+
+// Способы аутентификации, они же значения метки method в метриках.
+const (
+	MethodAPIKey    = "api_key"
+	MethodJWT       = "jwt"
+	MethodAnonymous = "anonymous" // аутентификация отключена
+)
+
+// Principal - аутентифицированный вызывающий.
+type Principal struct {
+	Subject string // имя ключа или sub токена
+	Role    Role
+	Method  string
+}
+
+// Allows - достаточно ли роли вызывающего для required.
+func (p Principal) Allows(required Role) bool {
+	return p.Role >= required
+}
+
+type principalKey struct{}
+
+// WithPrincipal - кладет вызывающего в контекст запроса.
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext - вызывающий из контекста; ok=false, если запрос не проходил аутентификацию.
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
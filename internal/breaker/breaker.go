@@ -0,0 +1,151 @@
+// Package breaker - circuit breaker: после всплеска ошибок зависимость перестает
+// вызываться на время OpenTimeout, затем пропускается один пробный вызов.
+package breaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+	"wb-project/internal/metric"
+)
+
+// ErrOpen - вызов отклонен без обращения к зависимости.
+var ErrOpen = errors.New("circuit breaker разомкнут")
+
+// State - состояние breaker, значение совпадает со значением метрики.
+type State int
+
+const (
+	Closed   State = iota // вызовы проходят, ошибки считаются
+	HalfOpen              // проходит один пробный вызов
+	Open                  // вызовы отклоняются
+)
+
+func (s State) String() string {
+	switch s {
+	case HalfOpen:
+		return "half-open"
+	case Open:
+		return "open"
+	default:
+		return "closed"
+	}
+}
+
+// Config - порог срабатывания и время до пробного вызова.
+type Config struct {
+	FailureRate float64       // доля ошибок в окне, при которой breaker размыкается
+	MinRequests int           // меньше вызовов в окне - доля ошибок не оценивается
+	Window      time.Duration // окно подсчета ошибок
+	OpenTimeout time.Duration // сколько вызовы отклоняются до пробного
+}
+
+// Breaker - безопасен для конкурентного использования.
+type Breaker struct {
+	name string
+	cfg  Config
+	now  func() time.Time
+
+	mu          sync.Mutex
+	state       State
+	openedAt    time.Time
+	windowStart time.Time
+	requests    int
+	failures    int
+	probing     bool   // пробный вызов в полуоткрытом состоянии еще не завершился
+	generation  uint64 // растет при каждой смене состояния
+}
+
+// New - name - метка в метрике order_breaker_state.
+func New(name string, cfg Config) *Breaker {
+	b := &Breaker{name: name, cfg: cfg, now: time.Now}
+	b.windowStart = b.now()
+	metric.BreakerState.WithLabelValues(name).Set(float64(Closed))
+	return b
+}
+
+// State - текущее состояние.
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// Allow - разрешение на вызов. done обязательно вызывается с результатом:
+// failed - сбой зависимости (а не, например, отсутствие записи).
+// Результат вызова, разрешенного до смены состояния, не учитывается: медленный
+// вызов из Closed не должен засчитываться как пробный в HalfOpen.
+func (b *Breaker) Allow() (done func(failed bool), err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	switch b.state {
+	case Open:
+		if now.Sub(b.openedAt) < b.cfg.OpenTimeout {
+			metric.BreakerRejectedTotal.WithLabelValues(b.name).Inc()
+			return nil, ErrOpen
+		}
+		b.setState(HalfOpen)
+		fallthrough
+	case HalfOpen:
+		if b.probing {
+			metric.BreakerRejectedTotal.WithLabelValues(b.name).Inc()
+			return nil, ErrOpen
+		}
+		b.probing = true
+	case Closed:
+		if now.Sub(b.windowStart) >= b.cfg.Window {
+			b.reset(now)
+		}
+	}
+	generation := b.generation
+	return func(failed bool) { b.done(generation, failed) }, nil
+}
+
+func (b *Breaker) done(generation uint64, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if generation != b.generation {
+		return
+	}
+	now := b.now()
+	switch b.state {
+	case HalfOpen:
+		b.probing = false
+		if failed {
+			b.open(now)
+			return
+		}
+		b.setState(Closed)
+		b.reset(now)
+	case Closed:
+		b.requests++
+		if failed {
+			b.failures++
+		}
+		if b.requests >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRate*float64(b.requests) {
+			b.open(now)
+		}
+	}
+}
+
+func (b *Breaker) open(now time.Time) {
+	b.openedAt = now
+	b.setState(Open)
+}
+
+func (b *Breaker) reset(now time.Time) {
+	b.windowStart = now
+	b.requests, b.failures = 0, 0
+}
+
+func (b *Breaker) setState(s State) {
+	if b.state == s {
+		return
+	}
+	b.state = s
+	b.generation++
+	metric.BreakerState.WithLabelValues(b.name).Set(float64(s))
+}
@@ -0,0 +1,111 @@
+package breaker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// clock - ручное время для проверки переходов без ожидания.
+type clock struct{ t time.Time }
+
+func (c *clock) now() time.Time { return c.t }
+
+func newTestBreaker() (*Breaker, *clock) {
+	c := &clock{t: time.Unix(0, 0)}
+	b := New("test", Config{FailureRate: 0.5, MinRequests: 4, Window: 10 * time.Second, OpenTimeout: 30 * time.Second})
+	b.now = c.now
+	b.windowStart = c.t
+	return b, c
+}
+
+// call - вызов через breaker с заданным результатом.
+func call(t *testing.T, b *Breaker, failed bool) {
+	t.Helper()
+	done, err := b.Allow()
+	require.NoError(t, err)
+	done(failed)
+}
+
+func TestBreaker(t *testing.T) {
+	t.Run("Размыкается, когда доля сбоев достигает порога", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		b, _ := newTestBreaker()
+
+		//2. Act(Действие)
+		call(t, b, true)
+		call(t, b, true)
+		call(t, b, false)
+		beforeMin := b.State()
+		call(t, b, false)
+		_, err := b.Allow()
+
+		//3. Assert
+		assert.Equal(t, Closed, beforeMin, "до MinRequests доля не оценивается")
+		assert.Equal(t, Open, b.State())
+		assert.ErrorIs(t, err, ErrOpen)
+	})
+
+	t.Run("Окно сбрасывает счетчики", func(t *testing.T) {
+		b, c := newTestBreaker()
+		call(t, b, true)
+		call(t, b, true)
+		call(t, b, true)
+
+		c.t = c.t.Add(10 * time.Second)
+		call(t, b, true)
+
+		assert.Equal(t, Closed, b.State())
+	})
+
+	t.Run("Пробный вызов после OpenTimeout", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		b, c := newTestBreaker()
+		for range 4 {
+			call(t, b, true)
+		}
+		require.Equal(t, Open, b.State())
+
+		//2. Act(Действие)
+		c.t = c.t.Add(30 * time.Second)
+		probe, err := b.Allow()
+		require.NoError(t, err)
+		_, concurrent := b.Allow()
+		probe(true)
+		reopened := b.State()
+
+		c.t = c.t.Add(30 * time.Second)
+		call(t, b, false)
+
+		//3. Assert
+		assert.ErrorIs(t, concurrent, ErrOpen, "в полуоткрытом состоянии проходит один вызов")
+		assert.Equal(t, Open, reopened)
+		assert.Equal(t, Closed, b.State())
+	})
+	t.Run("Медленный вызов из Closed не завершает пробный", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		b, c := newTestBreaker()
+		slow, err := b.Allow()
+		require.NoError(t, err)
+		for range 4 {
+			call(t, b, true)
+		}
+		require.Equal(t, Open, b.State())
+
+		//2. Act(Действие)
+		c.t = c.t.Add(30 * time.Second)
+		probe, err := b.Allow()
+		require.NoError(t, err)
+		slow(false)
+		afterSlow := b.State()
+		_, concurrent := b.Allow()
+		probe(true)
+
+		//3. Assert
+		assert.Equal(t, HalfOpen, afterSlow, "результат вызова из Closed не замыкает breaker")
+		assert.ErrorIs(t, concurrent, ErrOpen, "пробный вызов еще идет, второй не пропускается")
+		assert.Equal(t, Open, b.State())
+	})
+}
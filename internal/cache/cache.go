@@ -4,6 +4,7 @@ import (
 	"context"
 	"log"
 	"sync"
+	"sync/atomic"
 	"time"
 	"wb-project/internal/metric"
 	"wb-project/internal/models"
@@ -12,16 +13,20 @@ import (
 // Реализовать кэширование данных в сервисе: хранить последние полученные
 // данные заказов в памяти (например, в map), чтобы быстро выдавать их по запросу.
 type cacheItem struct {
-	data      *models.Order
+	data      models.EncodedOrder // заказ сериализуется один раз при записи
 	expiresAt int64
 }
 
 type OrderCache struct {
 	items             map[string]cacheItem
 	defaultExpiration time.Duration //Это стандартное время жизни.
+	staleTTL          time.Duration // сколько просроченная запись еще хранится для ответа, когда БД недоступна
 	cleanupInterval   time.Duration //Это частота работы нашего "уборщика", который чистит кеш
 	sync.RWMutex
 	ticker *time.Ticker
+
+	hits   atomic.Uint64
+	misses atomic.Uint64
 }
 
 func NewOrderCache(defaultExpiration, cleanupInterval time.Duration) *OrderCache {
@@ -34,14 +39,40 @@ func NewOrderCache(defaultExpiration, cleanupInterval time.Duration) *OrderCache
 	return c
 }
 
+// WithStaleTTL - запас после истечения срока жизни, в течение которого запись не удаляется
+// и доступна через GetStale.
+func (ch *OrderCache) WithStaleTTL(staleTTL time.Duration) *OrderCache {
+	ch.staleTTL = staleTTL
+	return ch
+}
+
+// SetExpiration - новое время жизни, запас для просроченных записей и частота очистки
+// без перезапуска. Уже закэшированные заказы живут до прежнего срока.
+func (ch *OrderCache) SetExpiration(defaultExpiration, staleTTL, cleanupInterval time.Duration) {
+	ch.Lock()
+	defer ch.Unlock()
+	ch.defaultExpiration = defaultExpiration
+	ch.staleTTL = staleTTL
+	if cleanupInterval != ch.cleanupInterval {
+		ch.cleanupInterval = cleanupInterval
+		ch.ticker.Reset(cleanupInterval)
+	}
+}
+
 func (ch *OrderCache) Set(uid string, order *models.Order) {
+	encoded, err := models.EncodeOrder(order)
+	if err != nil {
+		log.Printf("не удалось сериализовать заказ %s для кеша: %v", uid, err)
+		return
+	}
+
 	ch.Lock()
 	defer ch.Unlock()
 	_, exists := ch.items[uid]
 	//При сохранении указываем время жизни, когда нужно удалить объект
 	expiration := time.Now().Add(ch.defaultExpiration).UnixNano()
 	ch.items[uid] = cacheItem{
-		data:      order,
+		data:      encoded,
 		expiresAt: expiration,
 	}
 	if !exists {
@@ -51,22 +82,46 @@ func (ch *OrderCache) Set(uid string, order *models.Order) {
 }
 
 func (ch *OrderCache) Get(uid string) (*models.Order, bool) {
+	res, ok := ch.GetEncoded(uid)
+	if !ok {
+		return nil, false
+	}
+	return res.Order, true
+}
+
+// GetEncoded - заказ вместе с готовым JSON и ETag.
+func (ch *OrderCache) GetEncoded(uid string) (models.EncodedOrder, bool) {
 	ch.RLock()
 	defer ch.RUnlock()
 
 	res, ok := ch.items[uid]
 	if !ok {
-		return nil, false
+		ch.misses.Add(1)
+		return models.EncodedOrder{}, false
 	}
 
 	// Если ключ есть, проверяем, не протух ли он
 	if time.Now().UnixNano() > res.expiresAt {
-		return nil, false
+		ch.misses.Add(1)
+		return models.EncodedOrder{}, false
 	}
 
+	ch.hits.Add(1)
 	return res.data, true
 }
 
+// GetStale - заказ, в том числе просроченный не дольше чем на staleTTL.
+// Для ответа, когда БД недоступна.
+func (ch *OrderCache) GetStale(uid string) (*models.Order, bool) {
+	ch.RLock()
+	defer ch.RUnlock()
+	res, ok := ch.items[uid]
+	if !ok || time.Now().UnixNano() > res.expiresAt+int64(ch.staleTTL) {
+		return nil, false
+	}
+	return res.data.Order, true
+}
+
 func (ch *OrderCache) GC(ctx context.Context) error {
 	log.Println("Начинаем проверку кеша")
 	for {
@@ -77,7 +132,8 @@ func (ch *OrderCache) GC(ctx context.Context) error {
 			now := time.Now().UnixNano() //текущее время в UnixNano
 			deletedCounter := 0
 			for key, item := range ch.items { //
-				if now > item.expiresAt { //проверка, что настало время очистки
+				// просроченная запись живет еще staleTTL на случай недоступной БД
+				if now > item.expiresAt+int64(ch.staleTTL) { //проверка, что настало время очистки
 					metric.CacheSize.Dec()
 					delete(ch.items, key) //удаление данных их кеша
 					deletedCounter++
@@ -0,0 +1,116 @@
+package cache
+
+import (
+	"sort"
+	"time"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+)
+
+// Stats - состояние кэша для admin API.
+type Stats struct {
+	Items      int     `json:"items"`
+	Expired    int     `json:"expired"`    // просрочены, но еще не удалены GC
+	SizeBytes  int     `json:"size_bytes"` // суммарный размер сериализованных заказов
+	Hits       uint64  `json:"hits"`
+	Misses     uint64  `json:"misses"`
+	HitRatio   float64 `json:"hit_ratio"`
+	TTLSeconds float64 `json:"ttl_seconds"`
+}
+
+// Entry - описание записи кэша без самого заказа.
+type Entry struct {
+	OrderUID  string    `json:"order_uid"`
+	ETag      string    `json:"etag"`
+	SizeBytes int       `json:"size_bytes"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Expired   bool      `json:"expired"`
+}
+
+func (ch *OrderCache) Stats() Stats {
+	ch.RLock()
+	defer ch.RUnlock()
+
+	now := time.Now().UnixNano()
+	stats := Stats{
+		Items:      len(ch.items),
+		Hits:       ch.hits.Load(),
+		Misses:     ch.misses.Load(),
+		TTLSeconds: ch.defaultExpiration.Seconds(),
+	}
+	for _, item := range ch.items {
+		stats.SizeBytes += len(item.data.JSON)
+		if now > item.expiresAt {
+			stats.Expired++
+		}
+	}
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+	return stats
+}
+
+// Entries - первые limit записей по UID и общее число записей.
+func (ch *OrderCache) Entries(limit int) ([]Entry, int) {
+	ch.RLock()
+	uids := make([]string, 0, len(ch.items))
+	for uid := range ch.items {
+		uids = append(uids, uid)
+	}
+	ch.RUnlock()
+
+	sort.Strings(uids)
+	entries := make([]Entry, 0, min(limit, len(uids)))
+	for _, uid := range uids {
+		if len(entries) == limit {
+			break
+		}
+		// запись могли удалить, пока список сортировался
+		if entry, _, ok := ch.Inspect(uid); ok {
+			entries = append(entries, entry)
+		}
+	}
+	return entries, len(uids)
+}
+
+// Inspect - запись кэша вместе с заказом, в том числе просроченная.
+func (ch *OrderCache) Inspect(uid string) (Entry, models.EncodedOrder, bool) {
+	ch.RLock()
+	defer ch.RUnlock()
+
+	item, ok := ch.items[uid]
+	if !ok {
+		return Entry{}, models.EncodedOrder{}, false
+	}
+	return Entry{
+		OrderUID:  uid,
+		ETag:      item.data.ETag,
+		SizeBytes: len(item.data.JSON),
+		ExpiresAt: time.Unix(0, item.expiresAt).UTC(),
+		Expired:   time.Now().UnixNano() > item.expiresAt,
+	}, item.data, true
+}
+
+// Delete - удаляет заказ из кэша, false - его там не было.
+func (ch *OrderCache) Delete(uid string) bool {
+	ch.Lock()
+	defer ch.Unlock()
+
+	if _, ok := ch.items[uid]; !ok {
+		return false
+	}
+	delete(ch.items, uid)
+	metric.CacheSize.Dec()
+	return true
+}
+
+// Flush - очищает кэш, возвращает число удаленных записей.
+func (ch *OrderCache) Flush() int {
+	ch.Lock()
+	defer ch.Unlock()
+
+	n := len(ch.items)
+	ch.items = make(map[string]cacheItem)
+	metric.CacheSize.Set(0)
+	return n
+}
@@ -1,49 +1,310 @@
 package config
 
-import "os"
+import (
+	"time"
+)
 
+// Config - конфигурация сервиса. Значение каждого поля складывается слоями:
+// значение по умолчанию (Default) -> YAML-файл -> переменная окружения из тега env -> флаг
+// командной строки. Имя флага - путь из тегов yaml: --db.host, --kafka.brokers.
+// Поля с тегом secret не выводятся в --print-config, поля с тегом reload применяются
+// без перезапуска по SIGHUP или через admin API (см. Reloader).
 type Config struct {
-	DB          DBConfig
-	KafkaConfig KafkaConfig
+	HTTP            HTTPConfig       `yaml:"http"`
+	GRPC            GRPCConfig       `yaml:"grpc"`
+	DB              DBConfig         `yaml:"db"`
+	KafkaConfig     KafkaConfig      `yaml:"kafka"`
+	Cache           CacheConfig      `yaml:"cache"`
+	Log             LogConfig        `yaml:"log"`
+	Tracing         TracingConfig    `yaml:"tracing"`
+	Outbox          OutboxConfig     `yaml:"outbox"`
+	Webhook         WebhookConfig    `yaml:"webhook"`
+	Stream          StreamConfig     `yaml:"stream"`
+	Orders          OrdersConfig     `yaml:"orders"`
+	Auth            AuthConfig       `yaml:"auth"`
+	Encryption      EncryptionConfig `yaml:"encryption"`
+	RateLimit       RateLimitConfig  `yaml:"rate_limit"`
+	Health          HealthConfig     `yaml:"health"`
+	Startup         StartupConfig    `yaml:"startup"`
+	Repository      RepositoryConfig `yaml:"repository"`
+	ShutdownTimeout time.Duration    `yaml:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" validate:"gt=0"` // общий дедлайн остановки, включая вывод из балансировки
+	// как часто перечитывать файлы с паролями (*_file), чтобы подхватить ротацию без перезапуска
+	SecretsRefreshInterval time.Duration `yaml:"secrets_refresh_interval" env:"SECRETS_REFRESH_INTERVAL" validate:"gt=0"`
 }
+
+// HTTPConfig - HTTP API и веб-консоль.
+type HTTPConfig struct {
+	Addr              string        `yaml:"addr" env:"HTTP_ADDR" validate:"required,hostname_port"`
+	ReadHeaderTimeout time.Duration `yaml:"read_header_timeout" env:"HTTP_READ_HEADER_TIMEOUT" validate:"gte=0"`
+	ReadTimeout       time.Duration `yaml:"read_timeout" env:"HTTP_READ_TIMEOUT" validate:"gte=0"`
+	WriteTimeout      time.Duration `yaml:"write_timeout" env:"HTTP_WRITE_TIMEOUT" validate:"gte=0"` // 0 - без ограничения, иначе рвется SSE-стрим
+	IdleTimeout       time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" validate:"gte=0"`
+	TrustedProxies    []string      `yaml:"trusted_proxies" env:"HTTP_TRUSTED_PROXIES" validate:"dive,cidr|ip"` // балансировщики, которым можно верить в X-Forwarded-For
+}
+
+// GRPCConfig - настройки gRPC API.
+type GRPCConfig struct {
+	Addr string `yaml:"addr" env:"GRPC_ADDR" validate:"required,hostname_port"`
+}
+
 type DBConfig struct {
-	Host     string
-	Port     string
-	User     string
-	Password string
-	DBName   string
+	Host     string `yaml:"host" env:"DB_HOST" validate:"required"`
+	Port     string `yaml:"port" env:"DB_PORT" validate:"required,numeric"`
+	User     string `yaml:"user" env:"DB_USER" validate:"required"`
+	Password string `yaml:"password" env:"DB_PASSWORD" secret:"true"`
+	DBName   string `yaml:"name" env:"DB_NAME" validate:"required"`
+	// файл с паролем (Docker/Kubernetes secret), важнее password; перечитывается для новых соединений
+	PasswordFile string `yaml:"password_file" env:"DB_PASSWORD_FILE"`
+
+	// TLS: disable, require (без проверки сертификата), verify-ca, verify-full
+	SSLMode     string `yaml:"sslmode" env:"DB_SSLMODE" validate:"oneof=disable require verify-ca verify-full"`
+	SSLRootCert string `yaml:"sslrootcert" env:"DB_SSLROOTCERT"`                         // CA сервера
+	SSLCert     string `yaml:"sslcert" env:"DB_SSLCERT" validate:"required_with=SSLKey"` // клиентский сертификат
+	SSLKey      string `yaml:"sslkey" env:"DB_SSLKEY" validate:"required_with=SSLCert"`
+
+	// пул соединений database/sql
+	MaxOpenConns    int           `yaml:"max_open_conns" env:"DB_MAX_OPEN_CONNS" validate:"gte=0"` // 0 - без ограничения
+	MaxIdleConns    int           `yaml:"max_idle_conns" env:"DB_MAX_IDLE_CONNS" validate:"gte=0"`
+	ConnMaxLifetime time.Duration `yaml:"conn_max_lifetime" env:"DB_CONN_MAX_LIFETIME" validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `yaml:"conn_max_idle_time" env:"DB_CONN_MAX_IDLE_TIME" validate:"gte=0"`
+	ConnectTimeout  time.Duration `yaml:"connect_timeout" env:"DB_CONNECT_TIMEOUT" validate:"gt=0"`
 }
 
+// CacheConfig - кэш заказов в памяти.
 type CacheConfig struct {
+	TTL             time.Duration `yaml:"ttl" env:"CACHE_TTL" validate:"gt=0" reload:"true"`
+	StaleTTL        time.Duration `yaml:"stale_ttl" env:"CACHE_STALE_TTL" validate:"gte=0" reload:"true"` // сколько после TTL заказ хранится для ответа при недоступной БД
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"CACHE_CLEANUP_INTERVAL" validate:"gt=0" reload:"true"`
 }
 
 type KafkaConfig struct {
-	Brokers []string
-	Topic   string
-	Group   string
-}
-
-func LoadConfig() *Config {
-	dbconfig := DBConfig{
-		Host:     getEnv("DB_HOST", "localhost"),
-		Port:     getEnv("DB_PORT", "5432"),
-		User:     getEnv("DB_USER", "user"),
-		Password: getEnv("DB_PASSWORD", "pass"),
-		DBName:   getEnv("DB_NAME", "order_db"),
-	}
+	Brokers     []string `yaml:"brokers" env:"KAFKA_BROKERS,KAFKA_BROKER" validate:"required,min=1,dive,hostname_port"`
+	Topic       string   `yaml:"topic" env:"KAFKA_TOPIC" validate:"required"`
+	Group       string   `yaml:"group" env:"KAFKA_GROUP"` // группа для хранения offset консьюмера, пусто - читать с новых
+	EventsTopic string   `yaml:"events_topic" env:"KAFKA_EVENTS_TOPIC" validate:"required"`
 
-	kafkaConf := KafkaConfig{
-		Brokers: []string{getEnv("KAFKA_BROKER", "localhost:9092")},
-		Topic:   getEnv("KAFKA_TOPIC", "test-new"),
-	}
+	TLSEnabled            bool   `yaml:"tls_enabled" env:"KAFKA_TLS_ENABLED"`
+	TLSCAFile             string `yaml:"tls_ca_file" env:"KAFKA_TLS_CA_FILE"`                                         // пусто - системные CA
+	TLSCertFile           string `yaml:"tls_cert_file" env:"KAFKA_TLS_CERT_FILE" validate:"required_with=TLSKeyFile"` // клиентский сертификат для mTLS
+	TLSKeyFile            string `yaml:"tls_key_file" env:"KAFKA_TLS_KEY_FILE" validate:"required_with=TLSCertFile"`
+	TLSInsecureSkipVerify bool   `yaml:"tls_insecure_skip_verify" env:"KAFKA_TLS_INSECURE_SKIP_VERIFY"` // только для отладки
 
-	return &Config{DB: dbconfig, KafkaConfig: kafkaConf}
+	SASLMechanism    string `yaml:"sasl_mechanism" env:"KAFKA_SASL_MECHANISM" validate:"omitempty,oneof=PLAIN SCRAM-SHA-256 SCRAM-SHA-512"` // пусто - без SASL
+	SASLUser         string `yaml:"sasl_user" env:"KAFKA_SASL_USER" validate:"required_with=SASLMechanism"`
+	SASLPassword     string `yaml:"sasl_password" env:"KAFKA_SASL_PASSWORD" secret:"true"`
+	SASLPasswordFile string `yaml:"sasl_password_file" env:"KAFKA_SASL_PASSWORD_FILE" validate:"excluded_if=SASLMechanism PLAIN"` // важнее sasl_password; PLAIN не перечитывает пароль, поэтому с файлом не сочетается
 }
 
-func getEnv(key, defaultValue string) string {
-	if value := os.Getenv(key); value != "" {
-		return value
-	}
+// LogConfig - журнал slog.
+type LogConfig struct {
+	Level  string `yaml:"level" env:"LOG_LEVEL" validate:"oneof=debug info warn error" reload:"true"`
+	Format string `yaml:"format" env:"LOG_FORMAT" validate:"oneof=text json"`
+}
+
+// TracingConfig - экспорт трейсов по OTLP/HTTP.
+type TracingConfig struct {
+	Enabled     bool    `yaml:"enabled" env:"TRACING_ENABLED"`
+	ServiceName string  `yaml:"service_name" env:"TRACING_SERVICE_NAME" validate:"required"`
+	Endpoint    string  `yaml:"endpoint" env:"TRACING_ENDPOINT" validate:"omitempty,hostname_port"` // пусто - из OTEL_EXPORTER_OTLP_ENDPOINT или localhost:4318
+	Insecure    bool    `yaml:"insecure" env:"TRACING_INSECURE"`
+	SampleRatio float64 `yaml:"sample_ratio" env:"TRACING_SAMPLE_RATIO" validate:"gte=0,lte=1"`
+}
+
+// OutboxConfig - настройки релея событий из outbox в Kafka.
+type OutboxConfig struct {
+	PollInterval    time.Duration `yaml:"poll_interval" env:"OUTBOX_POLL_INTERVAL" validate:"gt=0"`
+	BatchSize       int           `yaml:"batch_size" env:"OUTBOX_BATCH_SIZE" validate:"gt=0"`
+	MaxBackoff      time.Duration `yaml:"max_backoff" env:"OUTBOX_MAX_BACKOFF" validate:"gt=0"`
+	Retention       time.Duration `yaml:"retention" env:"OUTBOX_RETENTION" validate:"gt=0"` // сколько хранить уже отправленные события
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"OUTBOX_CLEANUP_INTERVAL" validate:"gt=0"`
+}
+
+// WebhookConfig - настройки доставки исходящих вебхуков.
+type WebhookConfig struct {
+	PollInterval    time.Duration `yaml:"poll_interval" env:"WEBHOOK_POLL_INTERVAL" validate:"gt=0"`
+	BatchSize       int           `yaml:"batch_size" env:"WEBHOOK_BATCH_SIZE" validate:"gt=0"`
+	Timeout         time.Duration `yaml:"timeout" env:"WEBHOOK_TIMEOUT" validate:"gt=0"` // таймаут одного HTTP-запроса к подписчику
+	MaxAttempts     int           `yaml:"max_attempts" env:"WEBHOOK_MAX_ATTEMPTS" validate:"gt=0"`
+	BaseBackoff     time.Duration `yaml:"base_backoff" env:"WEBHOOK_BASE_BACKOFF" validate:"gt=0"`
+	MaxBackoff      time.Duration `yaml:"max_backoff" env:"WEBHOOK_MAX_BACKOFF" validate:"gtefield=BaseBackoff"`
+	DisableAfter    int           `yaml:"disable_after" env:"WEBHOOK_DISABLE_AFTER" validate:"gt=0"` // неудач подряд, после которых подписка отключается
+	Retention       time.Duration `yaml:"retention" env:"WEBHOOK_RETENTION" validate:"gt=0"`         // сколько хранить завершенные доставки и их попытки
+	CleanupInterval time.Duration `yaml:"cleanup_interval" env:"WEBHOOK_CLEANUP_INTERVAL" validate:"gt=0"`
+}
 
-	return defaultValue
+// StreamConfig - настройки SSE-стрима новых заказов.
+type StreamConfig struct {
+	BufferSize int           `yaml:"buffer_size" env:"STREAM_BUFFER_SIZE" validate:"gt=0"` // событий в буфере клиента, при переполнении клиент отключается
+	History    int           `yaml:"history" env:"STREAM_HISTORY" validate:"gte=0"`        // событий в кольцевом буфере для Last-Event-ID
+	Heartbeat  time.Duration `yaml:"heartbeat" env:"STREAM_HEARTBEAT" validate:"gt=0"`
+}
+
+// OrdersConfig - ограничения API чтения заказов.
+type OrdersConfig struct {
+	BatchMaxSize int           `yaml:"batch_max_size" env:"ORDERS_BATCH_MAX_SIZE" validate:"gt=0"` // максимум UID в одном пакетном запросе (HTTP и gRPC)
+	CacheMaxAge  time.Duration `yaml:"cache_max_age" env:"ORDERS_CACHE_MAX_AGE" validate:"gte=0"`  // max-age в Cache-Control ответов с заказом
+	TemplatesDir string        `yaml:"templates_dir" env:"RECEIPT_TEMPLATES_DIR"`                  // каталог с шаблонами печатных форм, пусто - встроенные
+	// strict - заказ с любой ошибкой валидации отклоняется, lenient - отклоняются только
+	// заказы без обязательных полей, остальные нарушения пишутся в журнал
+	ValidationMode string `yaml:"validation_mode" env:"ORDERS_VALIDATION_MODE" validate:"oneof=strict lenient" reload:"true"`
+}
+
+// AuthConfig - аутентификация вызывающих HTTP и gRPC API.
+type AuthConfig struct {
+	Enabled     bool          `yaml:"enabled" env:"AUTH_ENABLED"`
+	APIKeys     string        `yaml:"api_keys" env:"AUTH_API_KEYS" secret:"true"` // "имя:роль:sha256(ключа)" через запятую
+	APIKeysFile string        `yaml:"api_keys_file" env:"AUTH_API_KEYS_FILE"`     // то же из файла, важнее api_keys; читается при старте
+	JWKSFile    string        `yaml:"jwks_file" env:"AUTH_JWKS_FILE"`             // публичные ключи для проверки JWT, пусто - JWT не принимаются
+	JWTIssuer   string        `yaml:"jwt_issuer" env:"AUTH_JWT_ISSUER"`
+	JWTAudience string        `yaml:"jwt_audience" env:"AUTH_JWT_AUDIENCE"`
+	RoleClaim   string        `yaml:"jwt_role_claim" env:"AUTH_JWT_ROLE_CLAIM" validate:"required"`
+	Leeway      time.Duration `yaml:"jwt_leeway" env:"AUTH_JWT_LEEWAY" validate:"gte=0"` // допустимое расхождение часов при проверке exp/nbf
+}
+
+// EncryptionConfig - шифрование персональных данных получателя в БД.
+type EncryptionConfig struct {
+	KeyringFile       string        `yaml:"keyring_file" env:"ENCRYPTION_KEYRING_FILE"`                           // пусто - данные хранятся открытым текстом
+	RotationInterval  time.Duration `yaml:"rotation_interval" env:"ENCRYPTION_ROTATION_INTERVAL" validate:"gt=0"` // как часто искать строки, зашифрованные не основным ключом
+	RotationBatchSize int           `yaml:"rotation_batch_size" env:"ENCRYPTION_ROTATION_BATCH_SIZE" validate:"gt=0"`
+}
+
+// RateLimitConfig - ограничения нагрузки на HTTP API.
+type RateLimitConfig struct {
+	Enabled      bool          `yaml:"enabled" env:"RATE_LIMIT_ENABLED"`
+	Tiers        string        `yaml:"tiers" env:"RATE_LIMIT_TIERS" reload:"true"`                                  // "уровень:запросов_в_секунду:burst" через запятую, уровень - роль или anonymous
+	IdleTTL      time.Duration `yaml:"idle_ttl" env:"RATE_LIMIT_IDLE_TTL" validate:"gt=0"`                          // через сколько забывать неактивных клиентов
+	MaxInFlight  int           `yaml:"max_inflight" env:"RATE_LIMIT_MAX_INFLIGHT" validate:"gte=0" reload:"true"`   // одновременных запросов к БД, 0 - без ограничения
+	InFlightWait time.Duration `yaml:"inflight_wait" env:"RATE_LIMIT_INFLIGHT_WAIT" validate:"gte=0" reload:"true"` // сколько запрос ждет свободного места
+}
+
+// HealthConfig - пробы liveness/readiness/startup.
+type HealthConfig struct {
+	CheckTimeout time.Duration `yaml:"check_timeout" env:"HEALTH_CHECK_TIMEOUT" validate:"gt=0"` // таймаут проверки одного компонента
+	DrainDelay   time.Duration `yaml:"drain_delay" env:"HEALTH_DRAIN_DELAY" validate:"gte=0"`    // сколько readiness отвечает отказом до остановки серверов
+}
+
+// StartupConfig - ожидание БД и Kafka при запуске.
+type StartupConfig struct {
+	RetryAttempts   int           `yaml:"retry_attempts" env:"STARTUP_RETRY_ATTEMPTS" validate:"gte=1"`      // попыток подключения к каждой зависимости
+	RetryBackoffMin time.Duration `yaml:"retry_backoff_min" env:"STARTUP_RETRY_BACKOFF_MIN" validate:"gt=0"` // пауза после первой неудачи, дальше удваивается
+	RetryBackoffMax time.Duration `yaml:"retry_backoff_max" env:"STARTUP_RETRY_BACKOFF_MAX" validate:"gt=0"` // предел паузы
+	Degraded        bool          `yaml:"degraded" env:"STARTUP_DEGRADED"`                                   // API поднимается сразу и отдает данные из кэша, пока зависимости подключаются
+}
+
+// RepositoryConfig - таймауты запросов к БД из сервиса заказов и circuit breaker перед ними.
+type RepositoryConfig struct {
+	ReadTimeout        time.Duration `yaml:"read_timeout" env:"REPOSITORY_READ_TIMEOUT" validate:"gt=0"`                       // Get, GetMany, List
+	WriteTimeout       time.Duration `yaml:"write_timeout" env:"REPOSITORY_WRITE_TIMEOUT" validate:"gt=0"`                     // Save
+	ScanTimeout        time.Duration `yaml:"scan_timeout" env:"REPOSITORY_SCAN_TIMEOUT" validate:"gt=0"`                       // GetAll при разогреве кэша
+	BreakerFailureRate float64       `yaml:"breaker_failure_rate" env:"REPOSITORY_BREAKER_FAILURE_RATE" validate:"gt=0,lte=1"` // доля сбоев в окне, после которой запросы отклоняются сразу
+	BreakerMinRequests int           `yaml:"breaker_min_requests" env:"REPOSITORY_BREAKER_MIN_REQUESTS" validate:"gte=1"`      // меньше запросов в окне - не размыкается
+	BreakerWindow      time.Duration `yaml:"breaker_window" env:"REPOSITORY_BREAKER_WINDOW" validate:"gt=0"`
+	BreakerOpenTimeout time.Duration `yaml:"breaker_open_timeout" env:"REPOSITORY_BREAKER_OPEN_TIMEOUT" validate:"gt=0"` // через сколько пропустить пробный запрос
+}
+
+// Default - значения по умолчанию, рассчитанные на docker-compose из репозитория.
+func Default() *Config {
+	return &Config{
+		HTTP: HTTPConfig{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 5 * time.Second,
+			IdleTimeout:       2 * time.Minute,
+		},
+		GRPC: GRPCConfig{Addr: ":50051"},
+		DB: DBConfig{
+			Host:            "localhost",
+			Port:            "5432",
+			User:            "user",
+			Password:        "pass",
+			SSLMode:         "disable",
+			DBName:          "order_db",
+			MaxOpenConns:    25,
+			MaxIdleConns:    5,
+			ConnMaxLifetime: 30 * time.Minute,
+			ConnMaxIdleTime: 5 * time.Minute,
+			ConnectTimeout:  5 * time.Second,
+		},
+		KafkaConfig: KafkaConfig{
+			Brokers:     []string{"localhost:9092"},
+			Topic:       "test-new",
+			Group:       "wb-order-service",
+			EventsTopic: "orders.events",
+		},
+		Cache: CacheConfig{
+			TTL:             time.Minute,
+			StaleTTL:        10 * time.Minute,
+			CleanupInterval: 30 * time.Second,
+		},
+		Log: LogConfig{Level: "info", Format: "text"},
+		Tracing: TracingConfig{
+			Enabled:     true,
+			ServiceName: "wb-order-service",
+			Insecure:    true,
+			SampleRatio: 1,
+		},
+		Outbox: OutboxConfig{
+			PollInterval:    time.Second,
+			BatchSize:       100,
+			MaxBackoff:      time.Minute,
+			Retention:       24 * time.Hour,
+			CleanupInterval: 10 * time.Minute,
+		},
+		Webhook: WebhookConfig{
+			PollInterval:    2 * time.Second,
+			BatchSize:       50,
+			Timeout:         10 * time.Second,
+			MaxAttempts:     8,
+			BaseBackoff:     10 * time.Second,
+			MaxBackoff:      time.Hour,
+			DisableAfter:    20,
+			Retention:       7 * 24 * time.Hour,
+			CleanupInterval: 10 * time.Minute,
+		},
+		Stream: StreamConfig{
+			BufferSize: 64,
+			History:    1000,
+			Heartbeat:  15 * time.Second,
+		},
+		Orders: OrdersConfig{
+			BatchMaxSize:   100,
+			CacheMaxAge:    time.Minute,
+			ValidationMode: "strict",
+		},
+		Auth: AuthConfig{
+			Enabled:   true, // без ключей или JWKS сервис не запустится
+			RoleClaim: "role",
+			Leeway:    30 * time.Second,
+		},
+		Encryption: EncryptionConfig{
+			RotationInterval:  10 * time.Minute,
+			RotationBatchSize: 500,
+		},
+		RateLimit: RateLimitConfig{
+			Enabled:      true,
+			Tiers:        "anonymous:10:20,viewer:20:40,support:50:100,admin:0:0",
+			IdleTTL:      10 * time.Minute,
+			MaxInFlight:  64,
+			InFlightWait: 100 * time.Millisecond,
+		},
+		Health: HealthConfig{
+			CheckTimeout: 2 * time.Second,
+			DrainDelay:   5 * time.Second,
+		},
+		Startup: StartupConfig{
+			RetryAttempts:   10,
+			RetryBackoffMin: 500 * time.Millisecond,
+			RetryBackoffMax: 10 * time.Second,
+		},
+		Repository: RepositoryConfig{
+			ReadTimeout:        2 * time.Second,
+			WriteTimeout:       5 * time.Second,
+			ScanTimeout:        time.Minute,
+			BreakerFailureRate: 0.5,
+			BreakerMinRequests: 10,
+			BreakerWindow:      10 * time.Second,
+			BreakerOpenTimeout: 30 * time.Second,
+		},
+		ShutdownTimeout:        15 * time.Second,
+		SecretsRefreshInterval: time.Minute,
+	}
 }
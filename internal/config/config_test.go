@@ -0,0 +1,235 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeConfig(t *testing.T, content string) string {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func TestLoad_Defaults(t *testing.T) {
+	//1. Arrange(подготовка)
+	t.Setenv(EnvConfigFile, "")
+
+	//2. Act(Действие)
+	cfg, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+
+	//3. Assert
+	require.NoError(t, err)
+	assert.Equal(t, Default(), cfg)
+}
+
+// Флаг важнее переменной окружения, переменная окружения важнее файла.
+func TestLoad_Precedence(t *testing.T) {
+	//1. Arrange(подготовка)
+	path := writeConfig(t, `
+http:
+  addr: ":9000"
+db:
+  host: db.file
+  port: "6432"
+kafka:
+  brokers: [kafka-1:9092, kafka-2:9092]
+cache:
+  ttl: 3m
+`)
+	t.Setenv(EnvConfigFile, path)
+	t.Setenv("DB_HOST", "db.env")
+	t.Setenv("DB_PORT", "7432")
+	t.Setenv("KAFKA_BROKER", "legacy:9092")
+	t.Setenv("RATE_LIMIT_ENABLED", "false")
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+
+	//2. Act(Действие)
+	cfg, err := Load(fs, []string{"--db.port=8432", "--auth.enabled", "--cache.cleanup_interval", "1m"})
+
+	//3. Assert
+	require.NoError(t, err)
+	assert.Equal(t, ":9000", cfg.HTTP.Addr)
+	assert.Equal(t, "db.env", cfg.DB.Host)
+	assert.Equal(t, "8432", cfg.DB.Port)
+	assert.Equal(t, []string{"legacy:9092"}, cfg.KafkaConfig.Brokers)
+	assert.Equal(t, 3*time.Minute, cfg.Cache.TTL)
+	assert.Equal(t, time.Minute, cfg.Cache.CleanupInterval)
+	assert.False(t, cfg.RateLimit.Enabled)
+	assert.True(t, cfg.Auth.Enabled)
+	assert.Equal(t, "order_db", cfg.DB.DBName)
+}
+
+func TestLoad_Errors(t *testing.T) {
+	t.Run("Все ошибки сразу", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		t.Setenv(EnvConfigFile, "")
+		t.Setenv("OUTBOX_BATCH_SIZE", "много")
+		t.Setenv("DB_PORT", "postgres")
+
+		//2. Act(Действие)
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError),
+			[]string{"--log.level=trace", "--kafka.brokers=", "--tracing.sample_ratio=2"})
+
+		//3. Assert
+		require.Error(t, err)
+		msg := err.Error()
+		assert.Contains(t, msg, "OUTBOX_BATCH_SIZE")
+		assert.Contains(t, msg, "db.port (DB_PORT)")
+		assert.Contains(t, msg, "log.level (LOG_LEVEL)")
+		assert.Contains(t, msg, "kafka.brokers (KAFKA_BROKERS)")
+		assert.Contains(t, msg, "tracing.sample_ratio (TRACING_SAMPLE_RATIO)")
+	})
+
+	t.Run("Файл пароля SASL PLAIN отклоняется", func(t *testing.T) {
+		t.Setenv(EnvConfigFile, "")
+		t.Setenv("KAFKA_SASL_MECHANISM", "PLAIN")
+		t.Setenv("KAFKA_SASL_USER", "orders")
+		t.Setenv("KAFKA_SASL_PASSWORD_FILE", "/run/secrets/kafka")
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "kafka.sasl_password_file (KAFKA_SASL_PASSWORD_FILE)")
+	})
+
+	t.Run("Неизвестный ключ в файле", func(t *testing.T) {
+		t.Setenv(EnvConfigFile, writeConfig(t, "db:\n  hots: localhost\n"))
+
+		_, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "hots")
+	})
+}
+
+func TestPrint_RedactsSecrets(t *testing.T) {
+	//1. Arrange(подготовка)
+	cfg := Default()
+	cfg.DB.Password = "s3cr3t"
+	cfg.Auth.APIKeys = "ops:admin:abcdef"
+	var buf bytes.Buffer
+
+	//2. Act(Действие)
+	require.NoError(t, Print(&buf, cfg))
+
+	//3. Assert
+	out := buf.String()
+	assert.NotContains(t, out, "s3cr3t")
+	assert.NotContains(t, out, "abcdef")
+	assert.Contains(t, out, "password: '******'")
+	assert.Contains(t, out, "ttl: 1m0s # CACHE_TTL")
+
+	// вывод можно снова подать на вход
+	t.Setenv(EnvConfigFile, writeConfig(t, out))
+	loaded, err := Load(flag.NewFlagSet("test", flag.ContinueOnError), nil)
+	require.NoError(t, err)
+	assert.Equal(t, cfg.KafkaConfig.Brokers, loaded.KafkaConfig.Brokers)
+	assert.Equal(t, cfg.HTTP, loaded.HTTP)
+}
+
+func TestReloader(t *testing.T) {
+	t.Run("Применяются изменяемые на лету параметры", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		next := Default()
+		next.Cache.TTL = 5 * time.Minute
+		next.Log.Level = "debug"
+		r := NewReloader(Default(), func() (*Config, error) { return next, nil })
+		var applied time.Duration
+		r.OnReload(func(cfg *Config) (func(), error) {
+			return func() { applied = cfg.Cache.TTL }, nil
+		})
+
+		//2. Act(Действие)
+		res, err := r.Reload()
+
+		//3. Assert
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), res.Version)
+		assert.Equal(t, []Change{
+			{Path: "cache.ttl", Old: "1m0s", New: "5m0s"},
+			{Path: "log.level", Old: "info", New: "debug"},
+		}, res.Changes)
+		assert.Equal(t, 5*time.Minute, applied)
+		assert.Same(t, next, r.Current())
+	})
+
+	t.Run("Без изменений версия не растет", func(t *testing.T) {
+		r := NewReloader(Default(), func() (*Config, error) { return Default(), nil })
+
+		res, err := r.Reload()
+
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), res.Version)
+		assert.Empty(t, res.Changes)
+	})
+
+	t.Run("Отказ оставляет прежнюю конфигурацию", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			load   func() (*Config, error)
+			hook   Hook
+			reason string
+		}{
+			{
+				name: "Параметр требует перезапуска",
+				load: func() (*Config, error) {
+					next := Default()
+					next.Cache.TTL = time.Hour
+					next.DB.Host = "other"
+					return next, nil
+				},
+				reason: "db.host",
+			},
+			{
+				name:   "Ошибка чтения",
+				load:   func() (*Config, error) { return nil, assert.AnError },
+				reason: assert.AnError.Error(),
+			},
+			{
+				name: "Компонент не принял значение",
+				load: func() (*Config, error) {
+					next := Default()
+					next.RateLimit.Tiers = "viewer:1:1"
+					return next, nil
+				},
+				hook: func(*Config) (func(), error) {
+					return nil, errors.New("не задан уровень anonymous")
+				},
+				reason: "anonymous",
+			},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				//1. Arrange(подготовка)
+				current := Default()
+				r := NewReloader(current, tt.load)
+				applied := false
+				r.OnReload(func(*Config) (func(), error) {
+					return func() { applied = true }, nil
+				})
+				if tt.hook != nil {
+					r.OnReload(tt.hook)
+				}
+
+				//2. Act(Действие)
+				res, err := r.Reload()
+
+				//3. Assert
+				assert.ErrorIs(t, err, ErrReloadRejected)
+				assert.ErrorContains(t, err, tt.reason)
+				assert.Equal(t, int64(1), res.Version)
+				assert.False(t, applied)
+				assert.Same(t, current, r.Current())
+			})
+		}
+	})
+}
@@ -0,0 +1,320 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-playground/validator/v10"
+	"gopkg.in/yaml.v3"
+)
+
+// EnvConfigFile - путь к YAML-файлу конфигурации, если не задан флаг --config.
+const EnvConfigFile = "CONFIG_FILE"
+
+const redacted = "******"
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// field - лист дерева Config: скалярное поле со своим путем, переменными окружения и флагом.
+type field struct {
+	path   string   // db.host
+	env    []string // DB_HOST; первая - основная, остальные - устаревшие имена
+	secret bool
+	reload bool // можно менять без перезапуска
+	value  reflect.Value
+}
+
+// fields - листья cfg в порядке объявления.
+func fields(cfg *Config) []field {
+	var out []field
+	var walk func(v reflect.Value, prefix string)
+	walk = func(v reflect.Value, prefix string) {
+		t := v.Type()
+		for i := 0; i < t.NumField(); i++ {
+			sf := t.Field(i)
+			path := prefix + sf.Tag.Get("yaml")
+			if sf.Type.Kind() == reflect.Struct {
+				walk(v.Field(i), path+".")
+				continue
+			}
+			f := field{
+				path:   path,
+				secret: sf.Tag.Get("secret") == "true",
+				reload: sf.Tag.Get("reload") == "true",
+				value:  v.Field(i),
+			}
+			if env := sf.Tag.Get("env"); env != "" {
+				f.env = strings.Split(env, ",")
+			}
+			out = append(out, f)
+		}
+	}
+	walk(reflect.ValueOf(cfg).Elem(), "")
+	return out
+}
+
+// set - разбор строкового значения из окружения или флага в тип поля.
+func (f field) set(s string) error {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+	case v.Kind() == reflect.String:
+		v.SetString(s)
+	case v.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case v.Kind() == reflect.Int:
+		n, err := strconv.Atoi(s)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(n))
+	case v.Kind() == reflect.Float64:
+		n, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	case v.Kind() == reflect.Slice && v.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		v.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("неподдерживаемый тип %s", v.Type())
+	}
+	return nil
+}
+
+// String - значение поля в том же виде, в каком его принимают env и флаги.
+func (f field) String() string {
+	v := f.value
+	switch {
+	case v.Type() == durationType:
+		return time.Duration(v.Int()).String()
+	case v.Kind() == reflect.Slice:
+		return strings.Join(v.Interface().([]string), ",")
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
+
+// flagValue - флаг поля. Значение запоминается при разборе и применяется поверх
+// файла и окружения, чтобы флаг всегда побеждал независимо от порядка слоев.
+type flagValue struct {
+	def    string
+	isBool bool
+	raw    string
+}
+
+func (v *flagValue) String() string {
+	if v == nil {
+		return ""
+	}
+	return v.def
+}
+
+func (v *flagValue) Set(s string) error {
+	v.raw = s
+	return nil
+}
+
+func (v *flagValue) IsBoolFlag() bool { return v.isBool }
+
+// Load - конфигурация из слоев: Default -> YAML-файл (--config или CONFIG_FILE) ->
+// переменные окружения -> флаги из args. Ошибки разбора и валидации всех полей
+// собираются вместе, чтобы сервис сообщил обо всем сразу.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	cfg := Default()
+	leaves := fields(cfg)
+
+	//1. Регистрируем флаги: по одному на каждое поле
+	configFile := fs.String("config", os.Getenv(EnvConfigFile), "путь к YAML-файлу конфигурации (env "+EnvConfigFile+")")
+	values := make(map[string]*flagValue, len(leaves))
+	byPath := make(map[string]field, len(leaves))
+	for _, f := range leaves {
+		v := &flagValue{def: f.String(), isBool: f.value.Kind() == reflect.Bool}
+		usage := f.path
+		if len(f.env) > 0 {
+			usage += " (env " + f.env[0] + ")"
+		}
+		fs.Var(v, f.path, usage)
+		values[f.path] = v
+		byPath[f.path] = f
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	//2. Файл
+	if *configFile != "" {
+		if err := loadFile(*configFile, cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	//3. Окружение и флаги
+	var errs []error
+	for _, f := range leaves {
+		for _, name := range f.env {
+			value := os.Getenv(name)
+			if value == "" {
+				continue
+			}
+			if err := f.set(value); err != nil {
+				errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			}
+			break
+		}
+	}
+	fs.Visit(func(fl *flag.Flag) {
+		f, ok := byPath[fl.Name]
+		if !ok {
+			return // флаги вызывающего кода, например --print-config
+		}
+		if err := f.set(values[fl.Name].raw); err != nil {
+			errs = append(errs, fmt.Errorf("--%s: %w", fl.Name, err))
+		}
+	})
+
+	//4. Валидация итоговых значений
+	if err := cfg.Validate(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := errors.Join(errs...); err != nil {
+		return nil, fmt.Errorf("некорректная конфигурация:\n%w", err)
+	}
+	return cfg, nil
+}
+
+// LoadEnv - Load без флагов командной строки, для вспомогательных утилит со своими флагами.
+func LoadEnv() (*Config, error) {
+	return Load(flag.NewFlagSet("config", flag.ContinueOnError), nil)
+}
+
+func loadFile(path string, cfg *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("файл конфигурации: %w", err)
+	}
+	defer file.Close()
+
+	dec := yaml.NewDecoder(file)
+	dec.KnownFields(true) // опечатка в ключе не должна молча оставлять значение по умолчанию
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("файл конфигурации %s: %w", path, err)
+	}
+	return nil
+}
+
+// Validate - проверка правил из тегов validate. Ошибки всех полей возвращаются вместе,
+// каждая с путем в YAML и именем переменной окружения.
+func (c *Config) Validate() error {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(sf reflect.StructField) string {
+		return sf.Tag.Get("yaml")
+	})
+	err := validate.Struct(c)
+	var verrs validator.ValidationErrors
+	if !errors.As(err, &verrs) {
+		return err
+	}
+
+	env := map[string]string{}
+	for _, f := range fields(c) {
+		if len(f.env) > 0 {
+			env[f.path] = f.env[0]
+		}
+	}
+	errs := make([]error, 0, len(verrs))
+	for _, fe := range verrs {
+		//1. Config.kafka.brokers[0] -> kafka.brokers[0]
+		_, path, _ := strings.Cut(fe.Namespace(), ".")
+		rule := fe.Tag()
+		if fe.Param() != "" {
+			rule += "=" + fe.Param()
+		}
+		name, _, _ := strings.Cut(path, "[")
+		if e, ok := env[name]; ok {
+			path += " (" + e + ")"
+		}
+		errs = append(errs, fmt.Errorf("%s: значение %q не проходит правило %s", path, fmt.Sprint(fe.Value()), rule))
+	}
+	return errors.Join(errs...)
+}
+
+// Print - итоговая конфигурация в YAML, секреты заменены звездочками.
+func Print(w io.Writer, cfg *Config) error {
+	root := &yaml.Node{Kind: yaml.MappingNode}
+	sections := map[string]*yaml.Node{}
+	for _, f := range fields(cfg) {
+		//1. Находим или создаем секцию по префиксу пути
+		parent := root
+		section, key, nested := strings.Cut(f.path, ".")
+		if nested {
+			if parent = sections[section]; parent == nil {
+				parent = &yaml.Node{Kind: yaml.MappingNode}
+				sections[section] = parent
+				root.Content = append(root.Content, scalar(section), parent)
+			}
+		} else {
+			key = section
+		}
+
+		//2. Значение
+		var value *yaml.Node
+		switch {
+		case f.secret && !f.value.IsZero():
+			value = scalar(redacted)
+		case f.value.Kind() == reflect.Slice:
+			value = &yaml.Node{Kind: yaml.SequenceNode}
+			for _, item := range f.value.Interface().([]string) {
+				value.Content = append(value.Content, scalar(item))
+			}
+			if len(value.Content) == 0 {
+				value = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"} // пустой список читается обратно как nil
+			}
+		default:
+			value = scalar(f.String())
+		}
+		name := scalar(key)
+		if len(f.env) > 0 {
+			// у списка комментарий в строке значения уехал бы к следующему ключу
+			if value.Kind == yaml.SequenceNode {
+				name.LineComment = f.env[0]
+			} else {
+				value.LineComment = f.env[0]
+			}
+		}
+		parent.Content = append(parent.Content, name, value)
+	}
+
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(root); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+func scalar(s string) *yaml.Node {
+	return &yaml.Node{Kind: yaml.ScalarNode, Value: s}
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"wb-project/internal/metric"
+)
+
+// ErrReloadRejected - новая конфигурация не применена, действует прежняя.
+var ErrReloadRejected = errors.New("перезагрузка конфигурации отклонена")
+
+// Hook - подготовка компонента к новой конфигурации. Проверки, которые могут не пройти,
+// выполняются здесь; apply только подменяет значения и вызывается, лишь когда
+// все хуки подготовились без ошибок, поэтому компоненты переключаются вместе.
+type Hook func(cfg *Config) (apply func(), err error)
+
+// Change - изменившийся параметр.
+type Change struct {
+	Path string `json:"path"`
+	Old  string `json:"old"`
+	New  string `json:"new"`
+}
+
+// ReloadResult - итог перезагрузки.
+type ReloadResult struct {
+	Version int64    `json:"version"`
+	Changes []Change `json:"changes"`
+}
+
+// Reloader - перечитывает конфигурацию и применяет параметры с тегом reload
+// к работающим компонентам.
+type Reloader struct {
+	mu      sync.Mutex
+	load    func() (*Config, error)
+	current *Config
+	version int64
+	hooks   []Hook
+}
+
+// NewReloader - cfg - конфигурация, с которой сервис запущен; load читает ее заново
+// из тех же источников.
+func NewReloader(cfg *Config, load func() (*Config, error)) *Reloader {
+	metric.ConfigVersion.Set(1)
+	return &Reloader{load: load, current: cfg, version: 1}
+}
+
+// OnReload - регистрирует хук компонента.
+func (r *Reloader) OnReload(h Hook) *Reloader {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.hooks = append(r.hooks, h)
+	return r
+}
+
+// Current - действующая конфигурация, ее нельзя изменять.
+func (r *Reloader) Current() *Config {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.current
+}
+
+// Reload - читает конфигурацию и применяет изменения. Ошибка чтения или валидации,
+// изменение параметров, требующих перезапуска, и отказ любого хука отклоняют
+// перезагрузку целиком (ErrReloadRejected).
+func (r *Reloader) Reload() (ReloadResult, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	//1. Читаем и сравниваем с действующей
+	next, err := r.load()
+	if err != nil {
+		return r.reject(err)
+	}
+	changes, restart := diff(r.current, next)
+	if len(restart) > 0 {
+		return r.reject(fmt.Errorf("параметры меняются только перезапуском: %s", strings.Join(restart, ", ")))
+	}
+	if len(changes) == 0 {
+		metric.ConfigReloadsTotal.WithLabelValues("unchanged").Inc()
+		slog.Info("перезагрузка конфигурации: изменений нет", slog.Int64("version", r.version))
+		return ReloadResult{Version: r.version}, nil
+	}
+
+	//2. Готовим все компоненты, и только потом переключаем
+	applies := make([]func(), 0, len(r.hooks))
+	for _, hook := range r.hooks {
+		apply, err := hook(next)
+		if err != nil {
+			return r.reject(err)
+		}
+		if apply != nil {
+			applies = append(applies, apply)
+		}
+	}
+	for _, apply := range applies {
+		apply()
+	}
+	r.current = next
+	r.version++
+
+	metric.ConfigVersion.Set(float64(r.version))
+	metric.ConfigReloadsTotal.WithLabelValues("applied").Inc()
+	for _, c := range changes {
+		slog.Info("параметр конфигурации изменен",
+			slog.String("path", c.Path),
+			slog.String("old", c.Old),
+			slog.String("new", c.New),
+			slog.Int64("version", r.version))
+	}
+	return ReloadResult{Version: r.version, Changes: changes}, nil
+}
+
+func (r *Reloader) reject(err error) (ReloadResult, error) {
+	metric.ConfigReloadsTotal.WithLabelValues("rejected").Inc()
+	slog.Error("перезагрузка конфигурации отклонена, действует прежняя",
+		slog.Int64("version", r.version),
+		slog.Any("error", err))
+	return ReloadResult{Version: r.version}, fmt.Errorf("%w: %w", ErrReloadRejected, err)
+}
+
+// diff - изменившиеся параметры с тегом reload и пути остальных изменившихся.
+func diff(old, next *Config) (changes []Change, restart []string) {
+	before, after := fields(old), fields(next)
+	for i, f := range before {
+		was, now := f.String(), after[i].String()
+		if was == now {
+			continue
+		}
+		if !f.reload {
+			restart = append(restart, f.path)
+			continue
+		}
+		if f.secret {
+			was, now = redacted, redacted
+		}
+		changes = append(changes, Change{Path: f.path, Old: was, New: now})
+	}
+	return changes, restart
+}
@@ -1,35 +1,93 @@
 package conn
 
 import (
+	"context"
 	"database/sql"
+	"database/sql/driver"
 	"fmt"
-	"os"
+	"strings"
 	"wb-project/internal/config"
+	"wb-project/internal/secret"
 
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"github.com/uptrace/opentelemetry-go-extra/otelsql"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 )
 
-func Connection(conf *config.DBConfig) (*sql.DB, error) {
-	dns := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
-		conf.Host,
-		conf.Port,
-		conf.User,
-		conf.Password,
-		conf.DBName)
-	db, err := otelsql.Open("postgres", dns,
+// Open - пул соединений с Postgres без подключения: первое соединение откроется при Ping
+// или первом запросе. Пароль берется из password при открытии каждого нового соединения,
+// поэтому ротация файла секрета подхватывается без перезапуска: старые соединения
+// доживают до ConnMaxLifetime.
+func Open(conf *config.DBConfig, password *secret.Value) *sql.DB {
+	db := otelsql.OpenDB(&connector{conf: conf, password: password},
 		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
-		otelsql.WithDBName(os.Getenv("db_name")),
+		otelsql.WithDBName(conf.DBName),
 	)
+
+	db.SetMaxOpenConns(conf.MaxOpenConns)
+	db.SetMaxIdleConns(conf.MaxIdleConns)
+	db.SetConnMaxLifetime(conf.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(conf.ConnMaxIdleTime)
+	return db
+}
+
+// Ping - проверка подключения не дольше ConnectTimeout.
+func Ping(ctx context.Context, db *sql.DB, conf *config.DBConfig) error {
+	ctx, cancel := context.WithTimeout(ctx, conf.ConnectTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("не удалось достучаться до БД. Ошибка: %v", err)
+	}
+	return nil
+}
+
+// connector - driver.Connector, собирающий DSN заново для каждого соединения.
+type connector struct {
+	conf     *config.DBConfig
+	password *secret.Value
+}
+
+func (c *connector) Connect(ctx context.Context) (driver.Conn, error) {
+	pc, err := pq.NewConnector(DSN(c.conf, c.password.Get()))
 	if err != nil {
 		return nil, fmt.Errorf("неудалось установить сооединение. Ошибка: %v", err)
 	}
+	return pc.Connect(ctx)
+}
 
-	err = db.Ping()
-	if err != nil {
-		return nil, fmt.Errorf("не удалось достучаться до БД. Ошибка: %v", err)
+func (c *connector) Driver() driver.Driver {
+	return &pq.Driver{}
+}
+
+// DSN - строка подключения lib/pq в формате key=value.
+func DSN(conf *config.DBConfig, password string) string {
+	params := [][2]string{
+		{"host", conf.Host},
+		{"port", conf.Port},
+		{"user", conf.User},
+		{"password", password},
+		{"dbname", conf.DBName},
+		{"sslmode", conf.SSLMode},
+		{"sslrootcert", conf.SSLRootCert},
+		{"sslcert", conf.SSLCert},
+		{"sslkey", conf.SSLKey},
+	}
+	var b strings.Builder
+	for _, p := range params {
+		if p[1] == "" {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(p[0] + "=" + quote(p[1]))
 	}
+	return b.String()
+}
 
-	return db, nil
+// quote - значение в одинарных кавычках: пароль из файла может содержать пробелы и кавычки.
+func quote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `'`, `\'`)
+	return "'" + s + "'"
 }
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"wb-project/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// AuditRepository - журнал доступа к персональным данным без маскирования и действий через admin API.
+type AuditRepository struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *AuditRepository {
+	return &AuditRepository{db: db}
+}
+
+func (r *AuditRepository) RecordPIIAccess(ctx context.Context, a models.PIIAccess) error {
+	_, err := r.db.ExecContext(ctx,
+		`INSERT INTO pii_access_log (subject, role, channel, operation, order_uids, reason, accessed_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7)`,
+		a.Subject, a.Role, a.Channel, a.Operation, pq.Array(a.OrderUIDs), a.Reason, a.At,
+	)
+	return err
+}
+
+func (r *AuditRepository) RecordAdminAction(ctx context.Context, a models.AdminAction) error {
+	params, err := json.Marshal(a.Params)
+	if err != nil {
+		return err
+	}
+	if a.Params == nil {
+		params = []byte("{}")
+	}
+	_, err = r.db.ExecContext(ctx,
+		`INSERT INTO admin_audit_log (subject, role, action, target, params, result, error, performed_at)
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
+		a.Subject, a.Role, a.Action, a.Target, params, a.Result, a.Error, a.At,
+	)
+	return err
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"wb-project/internal/encryption"
+	"wb-project/internal/models"
+)
+
+// WithEncryption - шифровать персональные данные получателя ключами из keyring.
+// Без keyring данные пишутся открытым текстом, а зашифрованные строки не читаются.
+func (r *OrderRepository) WithEncryption(keyring *encryption.Keyring) *OrderRepository {
+	r.keyring = keyring
+	return r
+}
+
+// sealedDelivery - столбцы deliveries с персональными данными в том виде, в каком они лежат в БД.
+type sealedDelivery struct {
+	name, phone, address, email string
+	keyID                       sql.NullString
+	wrappedDEK                  []byte
+	phoneIdx, emailIdx          sql.NullString
+}
+
+// aad - привязка шифротекста к заказу и столбцу.
+func aad(uid, column string) string {
+	return uid + ":" + column
+}
+
+// sealDelivery - шифрует контакты получателя новым ключом данных.
+func (r *OrderRepository) sealDelivery(uid string, d models.Delivery) (sealedDelivery, error) {
+	if r.keyring == nil {
+		return sealedDelivery{name: d.Name, phone: d.Phone, address: d.Address, email: d.Email}, nil
+	}
+	env, err := r.keyring.NewEnvelope()
+	if err != nil {
+		return sealedDelivery{}, err
+	}
+	return sealedDelivery{
+		name:       env.Encrypt(d.Name, aad(uid, "name")),
+		phone:      env.Encrypt(d.Phone, aad(uid, "phone")),
+		address:    env.Encrypt(d.Address, aad(uid, "address")),
+		email:      env.Encrypt(d.Email, aad(uid, "email")),
+		keyID:      sql.NullString{String: env.KeyID, Valid: true},
+		wrappedDEK: env.WrappedDEK,
+		phoneIdx:   sql.NullString{String: r.keyring.BlindIndex("phone", d.Phone), Valid: true},
+		emailIdx:   sql.NullString{String: r.keyring.BlindIndex("email", d.Email), Valid: true},
+	}, nil
+}
+
+// openDelivery - расшифровывает контакты, прочитанные в d. Строки без key_id
+// записаны до включения шифрования и возвращаются как есть.
+func (r *OrderRepository) openDelivery(uid string, d *models.Delivery, keyID sql.NullString, wrappedDEK []byte) error {
+	if !keyID.Valid {
+		return nil
+	}
+	if r.keyring == nil {
+		return fmt.Errorf("заказ %s: %w: данные зашифрованы, а keyring не настроен", uid, models.ErrUnreadable)
+	}
+	env, err := r.keyring.OpenEnvelope(keyID.String, wrappedDEK)
+	if err != nil {
+		return fmt.Errorf("заказ %s: %w: ключ данных: %w", uid, models.ErrUnreadable, err)
+	}
+	for column, field := range map[string]*string{"name": &d.Name, "phone": &d.Phone, "address": &d.Address, "email": &d.Email} {
+		if *field, err = env.Decrypt(*field, aad(uid, column)); err != nil {
+			return fmt.Errorf("заказ %s, %s: %w: %w", uid, column, models.ErrUnreadable, err)
+		}
+	}
+	return nil
+}
+
+// contactFilter - условие поиска по phone или email для List.
+func (r *OrderRepository) contactFilter(column, value string, arg func(any) string) string {
+	plain := "(key_id IS NULL AND " + column + " = " + arg(value) + ")"
+	if r.keyring == nil {
+		return plain
+	}
+	return column + "_bidx = " + arg(r.keyring.BlindIndex(column, value)) + " OR " + plain
+}
+
+// ReencryptBatch - перешифровывает до limit строк deliveries, у которых ключ
+// не основной или которые еще хранятся открытым текстом. Строки блокируются
+// (FOR UPDATE SKIP LOCKED), поэтому несколько экземпляров не мешают друг другу.
+func (r *OrderRepository) ReencryptBatch(ctx context.Context, limit int) (int, error) {
+	if r.keyring == nil {
+		return 0, nil
+	}
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("не удалось откатить транзакцию %v", err)
+		}
+	}()
+
+	rows, err := tx.QueryContext(ctx,
+		`SELECT order_uid, name, phone, address, email, key_id, wrapped_dek FROM deliveries
+         WHERE key_id IS DISTINCT FROM $1
+         LIMIT $2 FOR UPDATE SKIP LOCKED`,
+		r.keyring.Primary(), limit)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка выборки deliveries для перешифрования: %w", err)
+	}
+	type row struct {
+		uid      string
+		delivery models.Delivery
+	}
+	var batch []row
+	for rows.Next() {
+		var rw row
+		var keyID sql.NullString
+		var wrappedDEK []byte
+		d := &rw.delivery
+		if err := rows.Scan(&rw.uid, &d.Name, &d.Phone, &d.Address, &d.Email, &keyID, &wrappedDEK); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		if err := r.openDelivery(rw.uid, d, keyID, wrappedDEK); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		batch = append(batch, rw)
+	}
+	if err := rows.Close(); err != nil {
+		return 0, err
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, rw := range batch {
+		sealed, err := r.sealDelivery(rw.uid, rw.delivery)
+		if err != nil {
+			return 0, err
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE deliveries SET name = $2, phone = $3, address = $4, email = $5,
+                key_id = $6, wrapped_dek = $7, phone_bidx = $8, email_bidx = $9
+             WHERE order_uid = $1`,
+			rw.uid, sealed.name, sealed.phone, sealed.address, sealed.email,
+			sealed.keyID, sealed.wrappedDEK, sealed.phoneIdx, sealed.emailIdx)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка перешифрования заказа %s: %w", rw.uid, err)
+		}
+	}
+	return len(batch), tx.Commit()
+}
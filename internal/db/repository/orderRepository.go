@@ -2,21 +2,30 @@ package repository
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"wb-project/internal/encryption"
 	"wb-project/internal/models"
+
+	"github.com/lib/pq"
 )
 
 type OrderRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *encryption.Keyring // nil - персональные данные не шифруются
 }
 
 func NewOrderRepository(db *sql.DB) *OrderRepository {
 	return &OrderRepository{db: db}
 }
 
-// Save - метод для сохранения order в БД.
+// Save - метод для сохранения order в БД. Заказ с уже сохраненным order_uid
+// перезаписывается (вебхук order.updated), без изменений - models.ErrAlreadyExists.
 func (r *OrderRepository) Save(ctx context.Context, order models.Order) error {
 	// Начинаем транзакцию
 	tx, err := r.db.BeginTx(ctx, nil)
@@ -30,13 +39,29 @@ func (r *OrderRepository) Save(ctx context.Context, order models.Order) error {
 		}
 	}()
 
-	// Сначала добавляем заказ в бд
-	_, err = tx.ExecContext(ctx,
-		`INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard) 
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+	hash, err := r.contentHash(order)
+	if err != nil {
+		return fmt.Errorf("ошибка при расчете отпечатка order, error: %w", err)
+	}
+
+	// Сначала добавляем заказ в бд. Заказ с тем же order_uid перезаписывается,
+	// только если изменилось содержимое; xmax = 0 - строка вставлена, а не обновлена
+	var inserted bool
+	err = tx.QueryRowContext(ctx,
+		`INSERT INTO orders (order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard, content_hash) 
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+         ON CONFLICT (order_uid) DO UPDATE SET track_number = EXCLUDED.track_number, entry = EXCLUDED.entry, locale = EXCLUDED.locale,
+             internal_signature = EXCLUDED.internal_signature, customer_id = EXCLUDED.customer_id, delivery_service = EXCLUDED.delivery_service,
+             shard_key = EXCLUDED.shard_key, sm_id = EXCLUDED.sm_id, date_created = EXCLUDED.date_created, oof_shard = EXCLUDED.oof_shard,
+             content_hash = EXCLUDED.content_hash
+         WHERE orders.content_hash IS DISTINCT FROM EXCLUDED.content_hash
+         RETURNING xmax = 0`,
 		order.OrderUID, order.TrackNumber, order.Entry, order.Locale, order.InternalSignature,
-		order.CustomerID, order.DeliveryService, order.ShardKey, order.SmID, order.DateCreated, order.OofShard,
-	)
+		order.CustomerID, order.DeliveryService, order.ShardKey, order.SmID, order.DateCreated, order.OofShard, hash,
+	).Scan(&inserted)
+	if errors.Is(err, sql.ErrNoRows) { // тот же заказ без изменений - повторная доставка сообщения
+		return fmt.Errorf("заказ %s: %w", order.OrderUID, models.ErrAlreadyExists)
+	}
 	if err != nil {
 		return fmt.Errorf("ошибка при добавлении сущности order в БД, error: %w", err)
 	}
@@ -44,7 +69,10 @@ func (r *OrderRepository) Save(ctx context.Context, order models.Order) error {
 	// Добавляем сущность payments
 	_, err = tx.ExecContext(ctx,
 		`INSERT INTO payments (order_uid, transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee) 
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)`,
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+         ON CONFLICT (order_uid) DO UPDATE SET transaction = EXCLUDED.transaction, request_id = EXCLUDED.request_id, currency = EXCLUDED.currency,
+             provider = EXCLUDED.provider, amount = EXCLUDED.amount, payment_dt = EXCLUDED.payment_dt, bank = EXCLUDED.bank,
+             delivery_cost = EXCLUDED.delivery_cost, goods_total = EXCLUDED.goods_total, custom_fee = EXCLUDED.custom_fee`,
 		order.OrderUID, order.Payment.Transaction, order.Payment.RequestID, order.Payment.Currency, order.Payment.Provider,
 		order.Payment.Amount, order.Payment.PaymentDt, order.Payment.Bank, order.Payment.DeliveryCost, order.Payment.GoodsTotal, order.Payment.CustomFee,
 	)
@@ -52,17 +80,32 @@ func (r *OrderRepository) Save(ctx context.Context, order models.Order) error {
 		return fmt.Errorf("ошибка при добавлении сущности payments в бд, error: %w", err)
 	}
 
-	// Добавляем сущность deliveries
+	// Добавляем сущность deliveries, контакты получателя - в зашифрованном виде
+	sealed, err := r.sealDelivery(order.OrderUID, order.Delivery)
+	if err != nil {
+		return fmt.Errorf("ошибка при шифровании delivery, error: %w", err)
+	}
 	_, err = tx.ExecContext(ctx,
-		`INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email) 
-         VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`,
-		order.OrderUID, order.Delivery.Name, order.Delivery.Phone, order.Delivery.Zip,
-		order.Delivery.City, order.Delivery.Address, order.Delivery.Region, order.Delivery.Email,
+		`INSERT INTO deliveries (order_uid, name, phone, zip, city, address, region, email, key_id, wrapped_dek, phone_bidx, email_bidx) 
+         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12)
+         ON CONFLICT (order_uid) DO UPDATE SET name = EXCLUDED.name, phone = EXCLUDED.phone, zip = EXCLUDED.zip, city = EXCLUDED.city,
+             address = EXCLUDED.address, region = EXCLUDED.region, email = EXCLUDED.email, key_id = EXCLUDED.key_id,
+             wrapped_dek = EXCLUDED.wrapped_dek, phone_bidx = EXCLUDED.phone_bidx, email_bidx = EXCLUDED.email_bidx`,
+		order.OrderUID, sealed.name, sealed.phone, order.Delivery.Zip,
+		order.Delivery.City, sealed.address, order.Delivery.Region, sealed.email,
+		sealed.keyID, sealed.wrappedDEK, sealed.phoneIdx, sealed.emailIdx,
 	)
 	if err != nil {
 		return fmt.Errorf("ошибка при добавлении сущности delivery в бд, error: %w", err)
 	}
 
+	// При перезаписи позиции заменяются целиком
+	if !inserted {
+		if _, err = tx.ExecContext(ctx, `DELETE FROM items WHERE order_uid = $1`, order.OrderUID); err != nil {
+			return fmt.Errorf("ошибка при удалении старых items, error: %w", err)
+		}
+	}
+
 	// Добавляем сущность Items
 	for _, item := range order.Items {
 		_, err = tx.ExecContext(ctx,
@@ -76,6 +119,19 @@ func (r *OrderRepository) Save(ctx context.Context, order models.Order) error {
 		}
 	}
 
+	// Событие пишем в outbox в той же транзакции: оно не потеряется
+	// и не будет опубликовано, если сохранение заказа откатится
+	if err = insertOrderStoredEvent(ctx, tx, order); err != nil {
+		return fmt.Errorf("ошибка при добавлении события в outbox, error: %w", err)
+	}
+	event := models.WebhookOrderCreated
+	if !inserted {
+		event = models.WebhookOrderUpdated
+	}
+	if err = insertWebhookDeliveries(ctx, tx, event, order); err != nil {
+		return fmt.Errorf("ошибка при постановке вебхуков в очередь, error: %w", err)
+	}
+
 	// В случая успеха фиксируем наши изменения
 	return tx.Commit()
 }
@@ -87,26 +143,34 @@ func (r *OrderRepository) Get(ctx context.Context, uid string) (models.Order, er
 	//orders
 	err := r.db.QueryRowContext(ctx, "Select order_uid, track_number, entry, locale, internal_signature, customer_id, delivery_service, shard_key, sm_id, date_created, oof_shard  FROM orders Where order_uid=$1",
 		uid).Scan(&order.OrderUID, &order.TrackNumber, &order.Entry, &order.Locale, &order.InternalSignature, &order.CustomerID, &order.DeliveryService, &order.ShardKey, &order.SmID, &order.DateCreated, &order.OofShard)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Order{}, fmt.Errorf("заказ %s: %w", uid, models.ErrNotFound)
+	}
 	if err != nil {
-		return models.Order{}, fmt.Errorf("error при получении orders: %v", err)
+		return models.Order{}, fmt.Errorf("error при получении orders: %w", err)
 	}
 	//payments
 	err = r.db.QueryRowContext(ctx, `Select transaction, request_id, currency, provider, amount, payment_dt, bank, delivery_cost, goods_total, custom_fee FROM payments Where order_uid = $1`,
 		uid).Scan(&order.Payment.Transaction, &order.Payment.RequestID, &order.Payment.Currency, &order.Payment.Provider, &order.Payment.Amount, &order.Payment.PaymentDt, &order.Payment.Bank, &order.Payment.DeliveryCost, &order.Payment.GoodsTotal, &order.Payment.CustomFee)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("error при получении payments: %v", err)
+		return models.Order{}, fmt.Errorf("error при получении payments: %w", err)
 	}
 	//deliveries
-	err = r.db.QueryRowContext(ctx, "SELECT name, phone, zip, city, address, region, email FROM deliveries WHERE order_uid = $1",
-		uid).Scan(&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email)
+	var keyID sql.NullString
+	var wrappedDEK []byte
+	err = r.db.QueryRowContext(ctx, "SELECT name, phone, zip, city, address, region, email, key_id, wrapped_dek FROM deliveries WHERE order_uid = $1",
+		uid).Scan(&order.Delivery.Name, &order.Delivery.Phone, &order.Delivery.Zip, &order.Delivery.City, &order.Delivery.Address, &order.Delivery.Region, &order.Delivery.Email, &keyID, &wrappedDEK)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("error при получении deliveries: %v", err)
+		return models.Order{}, fmt.Errorf("error при получении deliveries: %w", err)
+	}
+	if err = r.openDelivery(uid, &order.Delivery, keyID, wrappedDEK); err != nil {
+		return models.Order{}, err
 	}
 
 	//items
 	rows, err := r.db.QueryContext(ctx, "Select chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status FROM items where order_uid=$1", uid)
 	if err != nil {
-		return models.Order{}, fmt.Errorf("error при получении items : %v", err)
+		return models.Order{}, fmt.Errorf("error при получении items : %w", err)
 	}
 	defer func() {
 		if err = rows.Close(); err != nil {
@@ -117,7 +181,7 @@ func (r *OrderRepository) Get(ctx context.Context, uid string) (models.Order, er
 	for rows.Next() {
 		var item models.Items
 		if err := rows.Scan(&item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name, &item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status); err != nil {
-			return models.Order{}, fmt.Errorf("error при получении items: %v", err)
+			return models.Order{}, fmt.Errorf("error при получении items: %w", err)
 		}
 		order.Items = append(order.Items, item)
 	}
@@ -125,6 +189,78 @@ func (r *OrderRepository) Get(ctx context.Context, uid string) (models.Order, er
 	return order, nil
 }
 
+// GetMany - заказы по набору UID двумя запросами вместо четырех на каждый заказ.
+// Ненайденные UID просто отсутствуют в результате, порядок не гарантируется.
+func (r *OrderRepository) GetMany(ctx context.Context, uids []string) ([]models.Order, error) {
+	if len(uids) == 0 {
+		return nil, nil
+	}
+
+	//1. orders + payments + deliveries одним запросом
+	rows, err := r.db.QueryContext(ctx, `SELECT o.order_uid, o.track_number, o.entry, o.locale, o.internal_signature, o.customer_id, o.delivery_service, o.shard_key, o.sm_id, o.date_created, o.oof_shard,
+       p.transaction, p.request_id, p.currency, p.provider, p.amount, p.payment_dt, p.bank, p.delivery_cost, p.goods_total, p.custom_fee,
+       d.name, d.phone, d.zip, d.city, d.address, d.region, d.email, d.key_id, d.wrapped_dek
+FROM orders o
+JOIN payments p ON p.order_uid = o.order_uid
+JOIN deliveries d ON d.order_uid = o.order_uid
+WHERE o.order_uid = ANY($1)`, pq.Array(uids))
+	if err != nil {
+		return nil, fmt.Errorf("error при получении orders: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	orders := make([]models.Order, 0, len(uids))
+	index := make(map[string]int, len(uids))
+	for rows.Next() {
+		var o models.Order
+		var keyID sql.NullString
+		var wrappedDEK []byte
+		if err := rows.Scan(&o.OrderUID, &o.TrackNumber, &o.Entry, &o.Locale, &o.InternalSignature, &o.CustomerID, &o.DeliveryService, &o.ShardKey, &o.SmID, &o.DateCreated, &o.OofShard,
+			&o.Payment.Transaction, &o.Payment.RequestID, &o.Payment.Currency, &o.Payment.Provider, &o.Payment.Amount, &o.Payment.PaymentDt, &o.Payment.Bank, &o.Payment.DeliveryCost, &o.Payment.GoodsTotal, &o.Payment.CustomFee,
+			&o.Delivery.Name, &o.Delivery.Phone, &o.Delivery.Zip, &o.Delivery.City, &o.Delivery.Address, &o.Delivery.Region, &o.Delivery.Email, &keyID, &wrappedDEK); err != nil {
+			return nil, fmt.Errorf("error при получении orders: %w", err)
+		}
+		if err := r.openDelivery(o.OrderUID, &o.Delivery, keyID, wrappedDEK); err != nil {
+			return nil, err
+		}
+		index[o.OrderUID] = len(orders)
+		orders = append(orders, o)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	if len(orders) == 0 {
+		return orders, nil
+	}
+
+	//2. items всех найденных заказов
+	itemRows, err := r.db.QueryContext(ctx, "SELECT order_uid, chrt_id, track_number, price, rid, name, sale, size, total_price, nm_id, brand, status FROM items WHERE order_uid = ANY($1) ORDER BY id", pq.Array(uids))
+	if err != nil {
+		return nil, fmt.Errorf("error при получении items: %w", err)
+	}
+	defer func() {
+		if err = itemRows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	for itemRows.Next() {
+		var uid string
+		var item models.Items
+		if err := itemRows.Scan(&uid, &item.ChrtID, &item.TrackNumber, &item.Price, &item.Rid, &item.Name, &item.Sale, &item.Size, &item.TotalPrice, &item.NmID, &item.Brand, &item.Status); err != nil {
+			return nil, fmt.Errorf("error при получении items: %w", err)
+		}
+		if i, ok := index[uid]; ok {
+			orders[i].Items = append(orders[i].Items, item)
+		}
+	}
+	return orders, itemRows.Err()
+}
+
 // GetAll - возвращает массив заказов и ошибку
 func (r *OrderRepository) GetAll(ctx context.Context) ([]models.Order, error) {
 	var orders []models.Order
@@ -154,3 +290,97 @@ func (r *OrderRepository) GetAll(ctx context.Context) ([]models.Order, error) {
 	return orders, nil
 
 }
+
+// List - страница заказов от новых к старым с keyset-пагинацией по (date_created, order_uid).
+// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница.
+func (r *OrderRepository) List(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error) {
+	query := "SELECT order_uid, date_created FROM orders WHERE 1=1"
+	var args []any
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	if q.Entry != "" {
+		query += " AND entry = " + arg(q.Entry)
+	}
+	if q.DeliveryService != "" {
+		query += " AND delivery_service = " + arg(q.DeliveryService)
+	}
+	if q.CustomerID != "" {
+		query += " AND customer_id = " + arg(q.CustomerID)
+	}
+	// поиск по контактам идет через слепой индекс, открытым текстом сравниваются
+	// только строки, которые еще не зашифрованы
+	if q.Phone != "" {
+		query += " AND order_uid IN (SELECT order_uid FROM deliveries WHERE " + r.contactFilter("phone", q.Phone, arg) + ")"
+	}
+	if q.Email != "" {
+		query += " AND order_uid IN (SELECT order_uid FROM deliveries WHERE " + r.contactFilter("email", q.Email, arg) + ")"
+	}
+	if q.After != nil {
+		query += fmt.Sprintf(" AND (date_created, order_uid) < (%s, %s)", arg(q.After.DateCreated), arg(q.After.OrderUID))
+	}
+	query += " ORDER BY date_created DESC, order_uid DESC LIMIT " + arg(q.Limit+1)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.OrderPage{}, fmt.Errorf("ошибка при получении списка заказов: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	var keys []models.OrderCursor
+	for rows.Next() {
+		var key models.OrderCursor
+		if err := rows.Scan(&key.OrderUID, &key.DateCreated); err != nil {
+			return models.OrderPage{}, fmt.Errorf("ошибка при чтении списка заказов: %w", err)
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return models.OrderPage{}, err
+	}
+
+	page := models.OrderPage{Orders: make([]models.Order, 0, min(len(keys), q.Limit))}
+	if len(keys) > q.Limit {
+		keys = keys[:q.Limit]
+		last := keys[len(keys)-1]
+		page.Next = &last
+	}
+	uids := make([]string, len(keys))
+	for i, key := range keys {
+		uids[i] = key.OrderUID
+	}
+	orders, err := r.GetMany(ctx, uids)
+	if err != nil {
+		return models.OrderPage{}, err
+	}
+	// GetMany не сохраняет порядок, восстанавливаем его по ключам страницы
+	byUID := make(map[string]models.Order, len(orders))
+	for _, order := range orders {
+		byUID[order.OrderUID] = order
+	}
+	for _, uid := range uids {
+		if order, ok := byUID[uid]; ok {
+			page.Orders = append(page.Orders, order)
+		}
+	}
+	return page, nil
+}
+
+// contentHash - отпечаток содержимого заказа. С keyring это HMAC: хеш по заказу
+// с персональными данными не должен перебираться без ключа.
+func (r *OrderRepository) contentHash(order models.Order) (string, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return "", err
+	}
+	if r.keyring != nil {
+		return r.keyring.BlindIndex("order", string(data)), nil
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
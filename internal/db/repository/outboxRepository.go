@@ -0,0 +1,129 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+	"wb-project/internal/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+type OutboxRepository struct {
+	db *sql.DB
+}
+
+func NewOutboxRepository(db *sql.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// insertOrderStoredEvent - пишет событие "заказ сохранен" в outbox в рамках транзакции tx.
+// Вместе с событием сохраняется контекст трассировки, чтобы релей продолжил трейс.
+// Сам заказ в outbox не копируется: релей собирает payload при публикации.
+func insertOrderStoredEvent(ctx context.Context, tx *sql.Tx, order models.Order) error {
+	carrier := propagation.MapCarrier{}
+	otel.GetTextMapPropagator().Inject(ctx, carrier)
+	headers, err := json.Marshal(carrier)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO outbox (aggregate_id, event_type, schema_version, headers)
+         VALUES ($1, $2, $3, $4)`,
+		order.OrderUID, models.EventOrderStored, models.OrderEventSchemaVersion, headers,
+	)
+	return err
+}
+
+// Relay - выбирает до limit неотправленных событий, блокируя их от других экземпляров
+// (FOR UPDATE SKIP LOCKED), и по порядку передает в publish.
+// На первой ошибке публикации обработка останавливается, чтобы не нарушить порядок событий:
+// у события увеличивается счетчик попыток, уже отправленные помечаются как sent.
+func (r *OutboxRepository) Relay(ctx context.Context, limit int, publish func(context.Context, models.OutboxEvent) error) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && err != sql.ErrTxDone {
+			log.Printf("не удалось откатить транзакцию %v", err)
+		}
+	}()
+
+	events, err := fetchPending(ctx, tx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := 0
+	var publishErr error
+	for _, event := range events {
+		if publishErr = publish(ctx, event); publishErr != nil {
+			_, err = tx.ExecContext(ctx,
+				`UPDATE outbox SET attempts = attempts + 1, last_error = $2 WHERE id = $1`,
+				event.ID, publishErr.Error())
+			if err != nil {
+				return 0, fmt.Errorf("ошибка при обновлении попыток outbox: %w", err)
+			}
+			break
+		}
+		_, err = tx.ExecContext(ctx,
+			`UPDATE outbox SET sent_at = now(), attempts = attempts + 1, last_error = NULL WHERE id = $1`,
+			event.ID)
+		if err != nil {
+			return 0, fmt.Errorf("ошибка при отметке события outbox: %w", err)
+		}
+		sent++
+	}
+
+	if err = tx.Commit(); err != nil {
+		return 0, err
+	}
+	if publishErr != nil {
+		return sent, fmt.Errorf("ошибка публикации события: %w", publishErr)
+	}
+	return sent, nil
+}
+
+func fetchPending(ctx context.Context, tx *sql.Tx, limit int) ([]models.OutboxEvent, error) {
+	rows, err := tx.QueryContext(ctx,
+		`SELECT id, aggregate_id, event_type, schema_version, headers, created_at, attempts
+         FROM outbox WHERE sent_at IS NULL ORDER BY id LIMIT $1 FOR UPDATE SKIP LOCKED`, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении событий outbox: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	var events []models.OutboxEvent
+	for rows.Next() {
+		var event models.OutboxEvent
+		var headers []byte
+		if err := rows.Scan(&event.ID, &event.AggregateID, &event.EventType, &event.SchemaVersion,
+			&headers, &event.CreatedAt, &event.Attempts); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении события outbox: %w", err)
+		}
+		if err := json.Unmarshal(headers, &event.Headers); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении заголовков outbox: %w", err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+// DeleteSentBefore - удаляет отправленные события старше before, возвращает количество удаленных.
+func (r *OutboxRepository) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM outbox WHERE sent_at IS NOT NULL AND sent_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке outbox: %w", err)
+	}
+	return res.RowsAffected()
+}
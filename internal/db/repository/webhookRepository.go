@@ -0,0 +1,264 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+	"wb-project/internal/models"
+
+	"github.com/lib/pq"
+)
+
+type WebhookRepository struct {
+	db *sql.DB
+}
+
+func NewWebhookRepository(db *sql.DB) *WebhookRepository {
+	return &WebhookRepository{db: db}
+}
+
+// insertWebhookDeliveries - ставит в очередь доставку события всем включенным подпискам
+// на eventType. Вызывается в транзакции сохранения заказа. Тело запроса диспетчер
+// собирает при отправке, в очереди хранится только order_uid.
+func insertWebhookDeliveries(ctx context.Context, tx *sql.Tx, eventType string, order models.Order) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO webhook_deliveries (subscription_id, event_type, order_uid)
+         SELECT id, $1, $2 FROM webhook_subscriptions WHERE enabled AND $1 = ANY(events)`,
+		eventType, order.OrderUID,
+	)
+	return err
+}
+
+const subscriptionColumns = `id, url, secret, events, enabled, consecutive_failures, coalesce(disabled_reason, ''), created_at, updated_at`
+
+func scanSubscription(row interface{ Scan(...any) error }) (models.WebhookSubscription, error) {
+	var sub models.WebhookSubscription
+	err := row.Scan(&sub.ID, &sub.URL, &sub.Secret, pq.Array(&sub.Events), &sub.Enabled,
+		&sub.ConsecutiveFailures, &sub.DisabledReason, &sub.CreatedAt, &sub.UpdatedAt)
+	return sub, err
+}
+
+// CreateSubscription - сохраняет подписку и возвращает ее с присвоенным id.
+func (r *WebhookRepository) CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx,
+		`INSERT INTO webhook_subscriptions (url, secret, events, enabled)
+         VALUES ($1, $2, $3, $4) RETURNING `+subscriptionColumns,
+		sub.URL, sub.Secret, pq.Array(sub.Events), sub.Enabled,
+	)
+	created, err := scanSubscription(row)
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("ошибка при создании подписки: %w", err)
+	}
+	return created, nil
+}
+
+func (r *WebhookRepository) GetSubscription(ctx context.Context, id int64) (models.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions WHERE id = $1`, id)
+	sub, err := scanSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookSubscription{}, models.ErrNotFound
+	}
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("ошибка при получении подписки: %w", err)
+	}
+	return sub, nil
+}
+
+func (r *WebhookRepository) ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error) {
+	rows, err := r.db.QueryContext(ctx, `SELECT `+subscriptionColumns+` FROM webhook_subscriptions ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении подписок: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	subs := []models.WebhookSubscription{}
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, fmt.Errorf("ошибка при чтении подписки: %w", err)
+		}
+		subs = append(subs, sub)
+	}
+	return subs, rows.Err()
+}
+
+// UpdateSubscription - перезаписывает изменяемые поля подписки.
+func (r *WebhookRepository) UpdateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	row := r.db.QueryRowContext(ctx,
+		`UPDATE webhook_subscriptions
+         SET url = $2, events = $3, enabled = $4, consecutive_failures = $5,
+             disabled_reason = nullif($6, ''), updated_at = now()
+         WHERE id = $1 RETURNING `+subscriptionColumns,
+		sub.ID, sub.URL, pq.Array(sub.Events), sub.Enabled, sub.ConsecutiveFailures, sub.DisabledReason,
+	)
+	updated, err := scanSubscription(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.WebhookSubscription{}, models.ErrNotFound
+	}
+	if err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("ошибка при обновлении подписки: %w", err)
+	}
+	return updated, nil
+}
+
+func (r *WebhookRepository) DeleteSubscription(ctx context.Context, id int64) error {
+	res, err := r.db.ExecContext(ctx, `DELETE FROM webhook_subscriptions WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("ошибка при удалении подписки: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return models.ErrNotFound
+	}
+	return nil
+}
+
+// ListDeliveries - последние limit доставок подписки, новые первыми.
+func (r *WebhookRepository) ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`SELECT id, subscription_id, event_type, order_uid, status, attempts, next_attempt_at,
+                coalesce(last_status_code, 0), coalesce(last_error, ''), created_at, delivered_at
+         FROM webhook_deliveries WHERE subscription_id = $1 ORDER BY id DESC LIMIT $2`,
+		subscriptionID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при получении доставок: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.OrderUID, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении доставки: %w", err)
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, rows.Err()
+}
+
+// DeleteFinishedBefore - удаляет доставленные и окончательно неудачные доставки, созданные
+// раньше before, вместе с журналом попыток. Возвращает количество удаленных доставок.
+func (r *WebhookRepository) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	res, err := r.db.ExecContext(ctx,
+		`DELETE FROM webhook_deliveries WHERE status <> 'pending' AND created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("ошибка при очистке доставок: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// ClaimDue - берет в работу до limit доставок, время которых пришло.
+// Вместо удержания транзакции на время HTTP-запросов доставка "арендуется":
+// next_attempt_at сдвигается на lease, и другие экземпляры ее не увидят.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTask, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`UPDATE webhook_deliveries d
+         SET next_attempt_at = now() + make_interval(secs => $2)
+         FROM webhook_subscriptions s
+         WHERE s.id = d.subscription_id AND d.id IN (
+             SELECT dd.id FROM webhook_deliveries dd
+             JOIN webhook_subscriptions ss ON ss.id = dd.subscription_id AND ss.enabled
+             WHERE dd.status = 'pending' AND dd.next_attempt_at <= now()
+             ORDER BY dd.next_attempt_at LIMIT $1
+             FOR UPDATE OF dd SKIP LOCKED)
+         RETURNING d.id, d.subscription_id, d.event_type, d.order_uid, d.attempts, d.created_at, s.url, s.secret`,
+		limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("ошибка при выборке доставок: %w", err)
+	}
+	defer func() {
+		if err = rows.Close(); err != nil {
+			log.Printf("ошибка при закрытии rows: %v", err)
+		}
+	}()
+
+	var tasks []models.WebhookTask
+	for rows.Next() {
+		var t models.WebhookTask
+		d := &t.Delivery
+		if err := rows.Scan(&d.ID, &d.SubscriptionID, &d.EventType, &d.OrderUID, &d.Attempts,
+			&d.CreatedAt, &t.URL, &t.Secret); err != nil {
+			return nil, fmt.Errorf("ошибка при чтении доставки: %w", err)
+		}
+		d.Status = models.DeliveryPending
+		tasks = append(tasks, t)
+	}
+	return tasks, rows.Err()
+}
+
+// RecordAttempt - пишет попытку в журнал, обновляет состояние доставки и счетчик
+// неудач подписки. Когда неудач подряд становится disableAfter, подписка отключается,
+// а ее ожидающие доставки помечаются как failed. Возвращает true, если подписка отключена.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, disableAfter int) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			log.Printf("не удалось откатить транзакцию %v", err)
+		}
+	}()
+
+	_, err = tx.ExecContext(ctx,
+		`INSERT INTO webhook_attempts (delivery_id, attempt, status_code, error, duration_ms)
+         VALUES ($1, $2, nullif($3, 0), nullif($4, ''), $5)`,
+		attempt.DeliveryID, attempt.Attempt, attempt.StatusCode, attempt.Error, attempt.Duration.Milliseconds())
+	if err != nil {
+		return false, fmt.Errorf("ошибка при записи попытки: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx,
+		`UPDATE webhook_deliveries
+         SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = nullif($5, 0),
+             last_error = nullif($6, ''), delivered_at = $7
+         WHERE id = $1`,
+		delivery.ID, delivery.Status, delivery.Attempts, delivery.NextAttemptAt, delivery.LastStatusCode,
+		delivery.LastError, delivery.DeliveredAt)
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении доставки: %w", err)
+	}
+
+	disabled := false
+	switch {
+	case attempt.NotSent:
+		// подписчик не виноват, счетчик неудач подряд не трогаем
+	case attempt.Error == "":
+		_, err = tx.ExecContext(ctx,
+			`UPDATE webhook_subscriptions SET consecutive_failures = 0 WHERE id = $1`, delivery.SubscriptionID)
+	default:
+		err = tx.QueryRowContext(ctx,
+			`UPDATE webhook_subscriptions
+             SET consecutive_failures = consecutive_failures + 1,
+                 enabled = enabled AND consecutive_failures + 1 < $2::int,
+                 disabled_reason = CASE WHEN consecutive_failures + 1 >= $2::int
+                     THEN 'отключена после ' || $2::int || ' неудачных доставок подряд' ELSE disabled_reason END,
+                 updated_at = now()
+             WHERE id = $1 RETURNING NOT enabled`,
+			delivery.SubscriptionID, disableAfter).Scan(&disabled)
+	}
+	if err != nil {
+		return false, fmt.Errorf("ошибка при обновлении подписки: %w", err)
+	}
+
+	if disabled {
+		_, err = tx.ExecContext(ctx,
+			`UPDATE webhook_deliveries SET status = 'failed', last_error = 'подписка отключена'
+             WHERE subscription_id = $1 AND status = 'pending'`, delivery.SubscriptionID)
+		if err != nil {
+			return false, fmt.Errorf("ошибка при отмене доставок: %w", err)
+		}
+	}
+	return disabled, tx.Commit()
+}
@@ -0,0 +1,116 @@
+package encryption
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/encryption/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestKeyring(t *testing.T, primary string, keys map[string]string, indexKey string) *Keyring {
+	k, err := NewKeyring(primary, keys, indexKey)
+	require.NoError(t, err)
+	return k
+}
+
+func TestEnvelope_RoundTrip(t *testing.T) {
+	//1. Arrange(подготовка)
+	k := newTestKeyring(t, "k1", map[string]string{"k1": NewKey()}, NewKey())
+	env, err := k.NewEnvelope()
+	require.NoError(t, err)
+
+	//2. Act(Действие)
+	ciphertext := env.Encrypt("+79991234567", "uid:phone")
+	opened, err := k.OpenEnvelope(env.KeyID, env.WrappedDEK)
+	require.NoError(t, err)
+	plain, err := opened.Decrypt(ciphertext, "uid:phone")
+
+	//3. Assert
+	require.NoError(t, err)
+	assert.Equal(t, "+79991234567", plain)
+	assert.NotContains(t, ciphertext, "9991234567")
+
+	// шифротекст, перенесенный в другую строку или столбец, не расшифровывается
+	_, err = opened.Decrypt(ciphertext, "other:phone")
+	assert.ErrorIs(t, err, ErrDecrypt)
+}
+
+// После смены основного ключа старые строки читаются, пока ключ остается в keyring.
+func TestKeyring_Rotation(t *testing.T) {
+	//1. Arrange(подготовка)
+	oldKey, newKey, indexKey := NewKey(), NewKey(), NewKey()
+	before := newTestKeyring(t, "k1", map[string]string{"k1": oldKey}, indexKey)
+	env, err := before.NewEnvelope()
+	require.NoError(t, err)
+	ciphertext := env.Encrypt("Иван", "uid:name")
+
+	//2. Act(Действие)
+	after := newTestKeyring(t, "k2", map[string]string{"k1": oldKey, "k2": newKey}, indexKey)
+	opened, err := after.OpenEnvelope(env.KeyID, env.WrappedDEK)
+	require.NoError(t, err)
+	plain, err := opened.Decrypt(ciphertext, "uid:name")
+	fresh, _ := after.NewEnvelope()
+	withoutOld := newTestKeyring(t, "k2", map[string]string{"k2": newKey}, indexKey)
+	_, errRemoved := withoutOld.OpenEnvelope(env.KeyID, env.WrappedDEK)
+
+	//3. Assert
+	require.NoError(t, err)
+	assert.Equal(t, "Иван", plain)
+	assert.Equal(t, "k2", fresh.KeyID)
+	assert.ErrorIs(t, errRemoved, ErrUnknownKey)
+	// слепой индекс не зависит от ротации KEK
+	assert.Equal(t, before.BlindIndex("email", "a@b.ru"), after.BlindIndex("email", "a@b.ru"))
+}
+
+func TestKeyring_BlindIndex(t *testing.T) {
+	k := newTestKeyring(t, "k1", map[string]string{"k1": NewKey()}, NewKey())
+
+	assert.Equal(t, k.BlindIndex("phone", "+79991234567"), k.BlindIndex("phone", "+7 (999) 123-45-67"))
+	assert.Equal(t, k.BlindIndex("email", "Ivan@Example.com"), k.BlindIndex("email", " ivan@example.com"))
+	assert.NotEqual(t, k.BlindIndex("email", "x"), k.BlindIndex("phone", "x"))
+}
+
+func TestLoadKeyring(t *testing.T) {
+	dir := t.TempDir()
+	valid := filepath.Join(dir, "keyring.json")
+	require.NoError(t, os.WriteFile(valid, []byte(`{"primary":"k1","keys":{"k1":"`+NewKey()+`"},"index_key":"`+NewKey()+`"}`), 0o600))
+	noPrimary := filepath.Join(dir, "no-primary.json")
+	require.NoError(t, os.WriteFile(noPrimary, []byte(`{"primary":"k2","keys":{"k1":"`+NewKey()+`"},"index_key":"`+NewKey()+`"}`), 0o600))
+	short := filepath.Join(dir, "short.json")
+	require.NoError(t, os.WriteFile(short, []byte(`{"primary":"k1","keys":{"k1":"c2hvcnQ="},"index_key":"`+NewKey()+`"}`), 0o600))
+
+	k, err := LoadKeyring(valid)
+	require.NoError(t, err)
+	assert.Equal(t, "k1", k.Primary())
+
+	_, err = LoadKeyring(noPrimary)
+	assert.ErrorIs(t, err, ErrUnknownKey)
+
+	_, err = LoadKeyring(short)
+	assert.Error(t, err)
+}
+
+func TestRotator_RotateOnce(t *testing.T) {
+	//1. Arrange(подготовка)
+	store := mocks.NewStore(t)
+	rotator := NewRotator(store, config.EncryptionConfig{RotationInterval: time.Minute, RotationBatchSize: 50})
+	store.On("ReencryptBatch", mock.Anything, 50).Return(50, nil).Once()
+	store.On("ReencryptBatch", mock.Anything, 50).Return(0, errors.New("db down")).Once()
+
+	//2. Act(Действие)
+	n, err := rotator.RotateOnce(context.Background())
+	_, errFailed := rotator.RotateOnce(context.Background())
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 50, n)
+	assert.Error(t, errFailed)
+}
@@ -0,0 +1,81 @@
+package encryption
+
+import (
+	"crypto/cipher"
+	"encoding/base64"
+	"fmt"
+)
+
+// Envelope - ключ данных одной строки. Значения шифруются AES-GCM с
+// associated data (например, order_uid и имя столбца), поэтому шифротекст
+// нельзя незаметно перенести в другую строку или столбец.
+type Envelope struct {
+	KeyID      string // каким KEK зашифрован ключ данных
+	WrappedDEK []byte // nonce || AES-GCM(KEK, DEK)
+	dek        cipher.AEAD
+}
+
+// NewEnvelope - новый ключ данных, зашифрованный основным KEK.
+func (k *Keyring) NewEnvelope() (*Envelope, error) {
+	dek := randomBytes(keySize)
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{
+		KeyID:      k.primary,
+		WrappedDEK: seal(k.keks[k.primary], dek, []byte(k.primary)),
+		dek:        aead,
+	}, nil
+}
+
+// OpenEnvelope - расшифровывает ключ данных строки.
+func (k *Keyring) OpenEnvelope(keyID string, wrapped []byte) (*Envelope, error) {
+	kek, ok := k.keks[keyID]
+	if !ok {
+		return nil, fmt.Errorf("%q: %w", keyID, ErrUnknownKey)
+	}
+	dek, err := open(kek, wrapped, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(dek)
+	if err != nil {
+		return nil, err
+	}
+	return &Envelope{KeyID: keyID, WrappedDEK: wrapped, dek: aead}, nil
+}
+
+// Encrypt - шифротекст в base64, чтобы хранить его в текстовых столбцах.
+func (e *Envelope) Encrypt(plaintext, aad string) string {
+	return base64.StdEncoding.EncodeToString(seal(e.dek, []byte(plaintext), []byte(aad)))
+}
+
+func (e *Envelope) Decrypt(ciphertext, aad string) (string, error) {
+	raw, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return "", ErrDecrypt
+	}
+	plain, err := open(e.dek, raw, []byte(aad))
+	if err != nil {
+		return "", err
+	}
+	return string(plain), nil
+}
+
+func seal(aead cipher.AEAD, plaintext, aad []byte) []byte {
+	nonce := randomBytes(aead.NonceSize())
+	return aead.Seal(nonce, nonce, plaintext, aad)
+}
+
+func open(aead cipher.AEAD, data, aad []byte) ([]byte, error) {
+	if len(data) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	nonce, ciphertext := data[:aead.NonceSize()], data[aead.NonceSize():]
+	plain, err := aead.Open(nil, nonce, ciphertext, aad)
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	return plain, nil
+}
@@ -0,0 +1,145 @@
+// Package encryption - шифрование персональных данных на уровне полей.
+// Каждая строка шифруется своим ключом данных (DEK), который хранится рядом
+// в зашифрованном ключом из keyring (KEK) виде. Ротация KEK сводится
+// к перешифрованию строк фоновым Rotator.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+const keySize = 32 // AES-256
+
+var (
+	ErrUnknownKey = errors.New("ключ отсутствует в keyring")
+	ErrDecrypt    = errors.New("не удалось расшифровать значение")
+)
+
+// keyringFile - формат файла keyring:
+//
+//	{"primary": "2026-03", "keys": {"2026-01": "<base64>", "2026-03": "<base64>"}, "index_key": "<base64>"}
+//
+// primary - ключ для новых записей, остальные нужны для чтения до завершения ротации.
+// index_key не ротируется: от него зависят слепые индексы всех строк.
+type keyringFile struct {
+	Primary  string            `json:"primary"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// Keyring - ключи шифрования ключей (KEK) и ключ слепого индекса.
+type Keyring struct {
+	primary  string
+	keks     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// LoadKeyring - читает keyring из JSON-файла.
+func LoadKeyring(path string) (*Keyring, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("чтение keyring: %w", err)
+	}
+	var f keyringFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("разбор keyring: %w", err)
+	}
+	return NewKeyring(f.Primary, f.Keys, f.IndexKey)
+}
+
+// NewKeyring - keys: идентификатор -> ключ в base64, ровно 32 байта.
+func NewKeyring(primary string, keys map[string]string, indexKey string) (*Keyring, error) {
+	k := &Keyring{primary: primary, keks: make(map[string]cipher.AEAD, len(keys))}
+	for id, encoded := range keys {
+		if strings.Contains(id, ":") {
+			return nil, fmt.Errorf("ключ %q: идентификатор не может содержать ':'", id)
+		}
+		raw, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("ключ %q: %w", id, err)
+		}
+		if k.keks[id], err = newAEAD(raw); err != nil {
+			return nil, err
+		}
+	}
+	if _, ok := k.keks[primary]; !ok {
+		return nil, fmt.Errorf("основной ключ %q: %w", primary, ErrUnknownKey)
+	}
+	var err error
+	if k.indexKey, err = decodeKey(indexKey); err != nil {
+		return nil, fmt.Errorf("index_key: %w", err)
+	}
+	return k, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("ожидается %d байта, получено %d", keySize, len(raw))
+	}
+	return raw, nil
+}
+
+// NewKey - случайный ключ в base64 для файла keyring.
+func NewKey() string {
+	return base64.StdEncoding.EncodeToString(randomBytes(keySize))
+}
+
+// Primary - идентификатор ключа, которым шифруются новые записи.
+func (k *Keyring) Primary() string {
+	return k.primary
+}
+
+// BlindIndex - детерминированный HMAC нормализованного значения для поиска
+// по равенству без расшифровки. kind разделяет индексы разных полей.
+func (k *Keyring) BlindIndex(kind, value string) string {
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(kind + ":" + normalize(kind, value)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// normalize - "+7 (999) 123-45-67" и "+79991234567" должны давать один индекс.
+func normalize(kind, value string) string {
+	value = strings.TrimSpace(value)
+	switch kind {
+	case "email":
+		return strings.ToLower(value)
+	case "phone":
+		var b strings.Builder
+		for _, r := range value {
+			if r == '+' || (r >= '0' && r <= '9') {
+				b.WriteRune(r)
+			}
+		}
+		return b.String()
+	}
+	return value
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func randomBytes(n int) []byte {
+	b := make([]byte, n)
+	_, _ = rand.Read(b) // crypto/rand.Read не возвращает ошибок
+	return b
+}
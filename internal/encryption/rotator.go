@@ -0,0 +1,68 @@
+package encryption
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/metric"
+)
+
+// Store - хранилище строк с зашифрованными полями.
+//
+//go:generate mockery --name=Store --output=./mocks --case=underscore
+type Store interface {
+	// ReencryptBatch - перешифровывает до limit строк, зашифрованных не основным ключом
+	// (или еще не зашифрованных), возвращает количество обработанных.
+	ReencryptBatch(ctx context.Context, limit int) (int, error)
+}
+
+// Rotator - фоновое перешифрование после смены основного ключа в keyring.
+// Старый ключ можно удалить из файла, когда order_encryption_reencrypted_total перестанет расти.
+type Rotator struct {
+	store Store
+	cfg   config.EncryptionConfig
+}
+
+func NewRotator(store Store, cfg config.EncryptionConfig) *Rotator {
+	return &Rotator{store: store, cfg: cfg}
+}
+
+// Run - работает до отмены ctx: пока батчи заполнены целиком, следующий берется сразу,
+// иначе ждет RotationInterval.
+func (r *Rotator) Run(ctx context.Context) error {
+	slog.Info("Запуск перешифрования персональных данных",
+		slog.Duration("interval", r.cfg.RotationInterval),
+		slog.Int("batch_size", r.cfg.RotationBatchSize))
+
+	for {
+		wait := r.cfg.RotationInterval
+		n, err := r.RotateOnce(ctx)
+		switch {
+		case err != nil:
+			slog.Error("ошибка перешифрования", slog.Any("error", err), slog.Int("done", n))
+		case n == r.cfg.RotationBatchSize:
+			wait = 0
+		case n > 0:
+			slog.Info("перешифрование завершено", slog.Int("rows", n))
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// RotateOnce - перешифровывает один батч.
+func (r *Rotator) RotateOnce(ctx context.Context) (int, error) {
+	n, err := r.store.ReencryptBatch(ctx, r.cfg.RotationBatchSize)
+	metric.ReencryptedRowsTotal.WithLabelValues("success").Add(float64(n))
+	if err != nil {
+		metric.ReencryptedRowsTotal.WithLabelValues("error").Inc()
+	}
+	return n, err
+}
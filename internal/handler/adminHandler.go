@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"wb-project/internal/admin"
+	"wb-project/internal/cache"
+	"wb-project/internal/config"
+	"wb-project/internal/i18n"
+	"wb-project/internal/kafka"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminManager - операции над кэшем и консьюмером во время инцидентов.
+//
+//go:generate mockery --name=AdminManager --output=./mocks --case=underscore
+type AdminManager interface {
+	CacheStats(ctx context.Context) cache.Stats
+	CacheEntries(ctx context.Context, limit int) ([]cache.Entry, int)
+	CacheEntry(ctx context.Context, uid string) (cache.Entry, models.EncodedOrder, error)
+	Evict(ctx context.Context, uid string) error
+	Flush(ctx context.Context) int
+	Reload(ctx context.Context) (int, error)
+	ConsumerPosition(ctx context.Context) (kafka.Position, error)
+	PauseConsumer(ctx context.Context) error
+	ResumeConsumer(ctx context.Context) error
+	SeekConsumer(ctx context.Context, s admin.Seek) (int64, error)
+	ReloadConfig(ctx context.Context) (config.ReloadResult, error)
+}
+
+type AdminHandler struct {
+	manager AdminManager
+}
+
+func NewAdminHandler(m AdminManager) *AdminHandler {
+	return &AdminHandler{manager: m}
+}
+
+// CacheEntriesResponse - страница записей кэша.
+type CacheEntriesResponse struct {
+	Total   int           `json:"total"`
+	Entries []cache.Entry `json:"entries"`
+}
+
+// CacheEntryResponse - запись кэша и заказ в маскированном виде.
+type CacheEntryResponse struct {
+	Entry cache.Entry   `json:"entry"`
+	Order *models.Order `json:"order"`
+}
+
+// CacheFlushResponse - сколько записей удалено.
+type CacheFlushResponse struct {
+	Flushed int `json:"flushed"`
+}
+
+// CacheReloadResponse - размер кэша после загрузки из БД.
+type CacheReloadResponse struct {
+	Items int `json:"items"`
+}
+
+// SeekResponse - offset, с которого консьюмер продолжит чтение.
+type SeekResponse struct {
+	Offset int64 `json:"offset"`
+}
+
+// CacheStats - GET /admin/cache.
+func (h *AdminHandler) CacheStats(c *gin.Context) {
+	c.JSON(http.StatusOK, h.manager.CacheStats(c.Request.Context()))
+}
+
+// CacheEntries - GET /admin/cache/entries, ?limit= (по умолчанию 100, не больше 1000).
+func (h *AdminHandler) CacheEntries(c *gin.Context) {
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "100"))
+	if err != nil || limit <= 0 || limit > 1000 {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidLimit, 1000)
+		return
+	}
+	entries, total := h.manager.CacheEntries(c.Request.Context(), limit)
+	c.JSON(http.StatusOK, CacheEntriesResponse{Total: total, Entries: entries})
+}
+
+// CacheEntry - GET /admin/cache/entries/:order_uid. Персональные данные маскируются:
+// без маскирования заказ доступен через /orders/{order_uid}?view=unmasked.
+func (h *AdminHandler) CacheEntry(c *gin.Context) {
+	entry, encoded, err := h.manager.CacheEntry(c.Request.Context(), c.Param("order_uid"))
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	masked, err := maskOrder(encoded)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, CacheEntryResponse{Entry: entry, Order: masked.Order})
+}
+
+// Evict - DELETE /admin/cache/entries/:order_uid.
+func (h *AdminHandler) Evict(c *gin.Context) {
+	if err := h.manager.Evict(c.Request.Context(), c.Param("order_uid")); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Flush - DELETE /admin/cache/entries.
+func (h *AdminHandler) Flush(c *gin.Context) {
+	c.JSON(http.StatusOK, CacheFlushResponse{Flushed: h.manager.Flush(c.Request.Context())})
+}
+
+// Reload - POST /admin/cache:reload, разогрев кэша из БД.
+func (h *AdminHandler) Reload(c *gin.Context) {
+	items, err := h.manager.Reload(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, CacheReloadResponse{Items: items})
+}
+
+// ConsumerPosition - GET /admin/consumer.
+func (h *AdminHandler) ConsumerPosition(c *gin.Context) {
+	pos, err := h.manager.ConsumerPosition(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pos)
+}
+
+// PauseConsumer - POST /admin/consumer:pause.
+func (h *AdminHandler) PauseConsumer(c *gin.Context) {
+	h.consumerAction(c, h.manager.PauseConsumer)
+}
+
+// ResumeConsumer - POST /admin/consumer:resume.
+func (h *AdminHandler) ResumeConsumer(c *gin.Context) {
+	h.consumerAction(c, h.manager.ResumeConsumer)
+}
+
+// SeekConsumer - POST /admin/consumer:seek, тело {"offset": 42} или {"timestamp": "2026-03-01T10:00:00Z"}.
+func (h *AdminHandler) SeekConsumer(c *gin.Context) {
+	var req admin.Seek
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidBody)
+		return
+	}
+	offset, err := h.manager.SeekConsumer(c.Request.Context(), req)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SeekResponse{Offset: offset})
+}
+
+// ReloadConfig - POST /admin/config:reload, то же, что SIGHUP. Отвечает версией
+// конфигурации и списком примененных изменений.
+func (h *AdminHandler) ReloadConfig(c *gin.Context) {
+	res, err := h.manager.ReloadConfig(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, res)
+}
+
+// consumerAction - пауза и возобновление отвечают текущим положением консьюмера.
+func (h *AdminHandler) consumerAction(c *gin.Context, action func(context.Context) error) {
+	if err := action(c.Request.Context()); err != nil {
+		h.fail(c, err)
+		return
+	}
+	h.ConsumerPosition(c)
+}
+
+func (h *AdminHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		respondError(c, http.StatusNotFound, CodeOrderNotFound, i18n.MsgOrderNotCached)
+	case errors.Is(err, admin.ErrInvalid):
+		respondInvalid(c, err)
+	case errors.Is(err, kafka.ErrOffsetOutOfRange):
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgOffsetOutOfRange)
+	case errors.Is(err, config.ErrReloadRejected):
+		reason := strings.TrimPrefix(err.Error(), config.ErrReloadRejected.Error()+": ")
+		respondError(c, http.StatusUnprocessableEntity, CodeConfigRejected, i18n.MsgConfigReloadRejected, reason)
+	case errors.Is(err, kafka.ErrConsumerStopped):
+		respondError(c, http.StatusConflict, CodeConsumerStopped, i18n.MsgConsumerStopped)
+	default:
+		slog.Error("ошибка admin API", slog.Any("error", err), sl.Traced(c.Request.Context()))
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgInternal)
+	}
+}
@@ -0,0 +1,149 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"wb-project/internal/admin"
+	"wb-project/internal/cache"
+	"wb-project/internal/config"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/kafka"
+	"wb-project/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newAdminRouter(t *testing.T) (*gin.Engine, *mocks.AdminManager) {
+	gin.SetMode(gin.TestMode)
+	manager := mocks.NewAdminManager(t)
+	h := NewAdminHandler(manager)
+	router := gin.New()
+	router.GET("/admin/cache/entries/:order_uid", h.CacheEntry)
+	router.DELETE("/admin/cache/entries/:order_uid", h.Evict)
+	router.POST("/admin/consumer:method", customMethods(map[string]gin.HandlerFunc{
+		":pause": h.PauseConsumer,
+		":seek":  h.SeekConsumer,
+	}))
+	router.POST("/admin/config:method", customMethods(map[string]gin.HandlerFunc{
+		":reload": h.ReloadConfig,
+	}))
+	return router, manager
+}
+
+func TestAdminHandler_CacheEntry(t *testing.T) {
+	doc := NewOpenAPI()
+
+	t.Run("Заказ отдается маскированным", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, manager := newAdminRouter(t)
+		encoded := loadEncodedOrder(t)
+		entry := cache.Entry{OrderUID: encoded.Order.OrderUID, ETag: encoded.ETag, SizeBytes: len(encoded.JSON)}
+		manager.On("CacheEntry", mock.Anything, encoded.Order.OrderUID).Return(entry, encoded, nil)
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/cache/entries/"+encoded.Order.OrderUID, nil))
+
+		//3. Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/admin/cache/entries/{order_uid}", "get", "200"), w.Body.Bytes()))
+		assert.NotContains(t, w.Body.String(), encoded.Order.Delivery.Phone)
+	})
+
+	t.Run("Заказа нет в кэше", func(t *testing.T) {
+		router, manager := newAdminRouter(t)
+		manager.On("Evict", mock.Anything, "unknown").Return(models.ErrNotFound)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/admin/cache/entries/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/admin/cache/entries/{order_uid}", "delete", "404"), w.Body.Bytes()))
+	})
+}
+
+func TestAdminHandler_Consumer(t *testing.T) {
+	t.Run("Перемотка за пределы партиции", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, manager := newAdminRouter(t)
+		manager.On("SeekConsumer", mock.Anything, mock.MatchedBy(func(s admin.Seek) bool {
+			return s.Offset != nil && *s.Offset == 1000
+		})).Return(int64(0), kafka.ErrOffsetOutOfRange)
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer:seek", strings.NewReader(`{"offset":1000}`)))
+
+		//3. Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Contains(t, w.Body.String(), CodeInvalidRequest)
+	})
+
+	t.Run("Пауза остановленного консьюмера", func(t *testing.T) {
+		router, manager := newAdminRouter(t)
+		manager.On("PauseConsumer", mock.Anything).Return(kafka.ErrConsumerStopped)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer:pause", nil))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+		assert.Contains(t, w.Body.String(), CodeConsumerStopped)
+	})
+
+	t.Run("Пауза возвращает положение", func(t *testing.T) {
+		router, manager := newAdminRouter(t)
+		manager.On("PauseConsumer", mock.Anything).Return(nil)
+		manager.On("ConsumerPosition", mock.Anything).
+			Return(kafka.Position{Topic: "orders", Running: true, Paused: true, Offset: 10, HighWaterMark: 15, Lag: 5}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/consumer:pause", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"topic":"orders","partition":0,"running":true,"paused":true,"offset":10,"oldest":0,"high_water_mark":15,"lag":5}`, w.Body.String())
+	})
+}
+
+func TestAdminHandler_ReloadConfig(t *testing.T) {
+	doc := NewOpenAPI()
+
+	t.Run("Изменения применены", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, manager := newAdminRouter(t)
+		manager.On("ReloadConfig", mock.Anything).Return(config.ReloadResult{
+			Version: 3,
+			Changes: []config.Change{{Path: "cache.ttl", Old: "1m0s", New: "5m0s"}},
+		}, nil)
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/admin/config:reload", nil))
+
+		//3. Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/admin/config:reload", "post", "200"), w.Body.Bytes()))
+		assert.JSONEq(t, `{"version":3,"changes":[{"path":"cache.ttl","old":"1m0s","new":"5m0s"}]}`, w.Body.String())
+	})
+
+	t.Run("Отказ с причиной", func(t *testing.T) {
+		router, manager := newAdminRouter(t)
+		manager.On("ReloadConfig", mock.Anything).Return(config.ReloadResult{Version: 1},
+			fmt.Errorf("%w: %w", config.ErrReloadRejected, fmt.Errorf("параметры меняются только перезапуском: db.host")))
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/admin/config:reload", nil)
+		req.Header.Set("Accept-Language", "en")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+		resp := decodeError(t, w)
+		assert.Equal(t, CodeConfigRejected, resp.Code)
+		assert.Equal(t, "Configuration was not applied, the previous one stays in effect: параметры меняются только перезапуском: db.host", resp.Error)
+	})
+}
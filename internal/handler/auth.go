@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"wb-project/internal/auth"
+	"wb-project/internal/i18n"
+	"wb-project/internal/metric"
+
+	"github.com/gin-gonic/gin"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// HeaderAPIKey - заголовок со статическим API-ключом (альтернатива Authorization: ApiKey <ключ>).
+const HeaderAPIKey = "X-API-Key"
+
+// Authenticate - проверяет API-ключ или JWT и кладет вызывающего в контекст запроса.
+func Authenticate(a *auth.Authenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p, method, err := a.Authenticate(c.GetHeader("Authorization"), c.GetHeader(HeaderAPIKey))
+		if err != nil {
+			metric.AuthAttemptsTotal.WithLabelValues(auth.MetricMethod(method), auth.FailureReason(err)).Inc()
+			c.Header("WWW-Authenticate", `Bearer realm="wb-order-service"`)
+			message := i18n.MsgCredentialsRequired
+			if !errors.Is(err, auth.ErrMissingCredentials) {
+				message = i18n.MsgInvalidCredentials
+			}
+			respondError(c, http.StatusUnauthorized, CodeUnauthorized, message)
+			return
+		}
+		if a.Enabled() {
+			metric.AuthAttemptsTotal.WithLabelValues(p.Method, "success").Inc()
+		}
+
+		trace.SpanFromContext(c.Request.Context()).SetAttributes(
+			attribute.String("enduser.id", p.Subject),
+			attribute.String("enduser.role", p.Role.String()),
+		)
+		c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), p))
+		c.Next()
+	}
+}
+
+// RequireRole - пропускает только вызывающих с ролью не ниже role.
+func RequireRole(role auth.Role) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		p := principal(c)
+		if !p.Allows(role) {
+			metric.AuthAttemptsTotal.WithLabelValues(auth.MetricMethod(p.Method), "forbidden").Inc()
+			respondError(c, http.StatusForbidden, CodeForbidden, i18n.MsgRoleRequired, role.String())
+			return
+		}
+		c.Next()
+	}
+}
+
+// principal - вызывающий текущего запроса; без аутентификации - без роли.
+func principal(c *gin.Context) auth.Principal {
+	p, _ := auth.FromContext(c.Request.Context())
+	return p
+}
@@ -0,0 +1,135 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/health"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
+	"wb-project/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newSecuredRouter(t *testing.T) (*gin.Engine, *mocks.OrderReader, *mocks.Auditor) {
+	gin.SetMode(gin.TestMode)
+	keys, err := auth.ParseAPIKeys("ui:viewer:" + auth.HashKey("viewer-key") +
+		",desk:support:" + auth.HashKey("support-key") + ",ops:admin:" + auth.HashKey("admin-key"))
+	require.NoError(t, err)
+
+	mockService := mocks.NewOrderReader(t)
+	auditor := mocks.NewAuditor(t)
+	router := NewRouter(
+		NewOrderHandler(mockService, auditor, testOrdersConfig),
+		NewWebhookHandler(nil),
+		NewStreamHandler(stream.NewHub(1, 1), time.Second),
+		NewHealthHandler(health.NewChecker(time.Second)),
+		NewAdminHandler(nil),
+		auth.NewAuthenticator(true, keys, nil),
+		nil,
+	)
+	return router, mockService, auditor
+}
+
+func TestAuth_Routes(t *testing.T) {
+	encoded := loadEncodedOrder(t)
+	orderPath := APIPrefix + "/orders/" + encoded.Order.OrderUID
+
+	cases := []struct {
+		name   string
+		path   string
+		key    string
+		status int
+	}{
+		{"Без ключа", orderPath, "", http.StatusUnauthorized},
+		{"Неверный ключ", orderPath, "wrong", http.StatusUnauthorized},
+		{"viewer читает заказ", orderPath, "viewer-key", http.StatusOK},
+		{"viewer не управляет вебхуками", APIPrefix + "/admin/webhooks", "viewer-key", http.StatusForbidden},
+		{"viewer получает маскированный телефон", orderPath + "?fields=delivery.phone", "viewer-key", http.StatusOK},
+		{"viewer не снимает маскирование", orderPath + "?view=unmasked", "viewer-key", http.StatusForbidden},
+		{"Неизвестный view", orderPath + "?view=raw", "admin-key", http.StatusBadRequest},
+		{"Документация открыта", APIPrefix + "/openapi.json", "", http.StatusOK},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			router, mockService, _ := newSecuredRouter(t)
+			mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil).Maybe()
+
+			req := httptest.NewRequest(http.MethodGet, tc.path, nil)
+			if tc.key != "" {
+				req.Header.Set(HeaderAPIKey, tc.key)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			assert.Equal(t, tc.status, w.Code)
+		})
+	}
+}
+
+// По умолчанию персональные данные маскируются для всех ролей, без маскирования -
+// только support/admin по ?view=unmasked и с записью в журнал аудита.
+func TestAuth_PIIMasking(t *testing.T) {
+	encoded := loadEncodedOrder(t)
+	uid := encoded.Order.OrderUID
+
+	get := func(t *testing.T, key, query string, audit error) (*httptest.ResponseRecorder, map[string]any) {
+		router, mockService, auditor := newSecuredRouter(t)
+		mockService.On("GetOrderEncoded", mock.Anything, uid).Return(encoded, nil)
+		if query == "?view=unmasked" {
+			auditor.On("RecordPIIAccess", mock.Anything, mock.MatchedBy(func(a models.PIIAccess) bool {
+				return a.Subject == "desk" && a.Channel == "http" && a.Reason == "тикет 42" &&
+					assert.ObjectsAreEqual([]string{uid}, a.OrderUIDs)
+			})).Return(audit)
+		}
+
+		req := httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+uid+query, nil)
+		req.Header.Set("Authorization", "ApiKey "+key)
+		req.Header.Set(HeaderAccessReason, "тикет 42")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != http.StatusOK {
+			return w, nil
+		}
+		var doc map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+		return w, doc["delivery"].(map[string]any)
+	}
+
+	t.Run("Маскирование по умолчанию", func(t *testing.T) {
+		for _, key := range []string{"viewer-key", "admin-key"} {
+			_, delivery := get(t, key, "", nil)
+			assert.Equal(t, pii.Mask(pii.KindPhone, encoded.Order.Delivery.Phone), delivery["phone"])
+			assert.Equal(t, pii.Mask(pii.KindName, encoded.Order.Delivery.Name), delivery["name"])
+			assert.Equal(t, "***", delivery["address"])
+			assert.Equal(t, encoded.Order.Delivery.City, delivery["city"])
+		}
+	})
+
+	t.Run("support видит данные с записью в журнал", func(t *testing.T) {
+		w, delivery := get(t, "support-key", "?view=unmasked", nil)
+		assert.Equal(t, encoded.Order.Delivery.Phone, delivery["phone"])
+		assert.Equal(t, "private, no-store", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("Без журнала аудита данные не отдаются", func(t *testing.T) {
+		unmasked := metric.PIIUnmaskedTotal.WithLabelValues("http", "support")
+		before := testutil.ToFloat64(unmasked)
+
+		w, _ := get(t, "support-key", "?view=unmasked", errors.New("db down"))
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+		assert.NotContains(t, w.Body.String(), encoded.Order.Delivery.Phone)
+		assert.Equal(t, before, testutil.ToFloat64(unmasked), "неудачный доступ не считается выдачей данных")
+	})
+}
@@ -0,0 +1,132 @@
+package handler
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+)
+
+// Тела меньше этого размера не сжимаем: выигрыш меньше накладных расходов.
+const minCompressSize = 512
+
+// Поддерживаемые кодировки в порядке предпочтения сервера при равных q.
+var supportedEncodings = []string{"br", "gzip"}
+
+var compressors = map[string]*sync.Pool{
+	"br": {New: func() any { return brotli.NewWriterLevel(nil, brotli.DefaultCompression) }},
+	"gzip": {New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, gzip.DefaultCompression)
+		return w
+	}},
+}
+
+type resettableWriter interface {
+	io.WriteCloser
+	Reset(w io.Writer)
+}
+
+// negotiateEncoding - выбирает кодировку по Accept-Encoding, "" означает identity.
+func negotiateEncoding(header string) string {
+	if header == "" {
+		return ""
+	}
+	q := map[string]float64{}
+	for _, part := range strings.Split(header, ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		weight := 1.0
+		if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		q[strings.ToLower(strings.TrimSpace(name))] = weight
+	}
+
+	best, bestQ := "", 0.0
+	for _, enc := range supportedEncodings {
+		weight, ok := q[enc]
+		if !ok {
+			weight, ok = q["*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = enc, weight
+		}
+	}
+	return best
+}
+
+// variantETag - сильный ETag сжатого представления отличается от исходного.
+func variantETag(etag, encoding string) string {
+	if encoding == "" {
+		return etag
+	}
+	return strings.TrimSuffix(etag, `"`) + "-" + encoding + `"`
+}
+
+// etagMatches - слабое сравнение для If-None-Match (RFC 9110, 13.1.2):
+// ETag любого сжатого варианта совпадает с ETag исходного представления.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		for _, enc := range supportedEncodings {
+			tag = strings.Replace(tag, "-"+enc+`"`, `"`, 1)
+		}
+		if tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// respondCacheable - отдает готовое тело с ETag: 304, если клиент уже имеет
+// эту версию, иначе тело в согласованной с клиентом кодировке.
+func respondCacheable(c *gin.Context, contentType string, body []byte, etag string) {
+	encoding := ""
+	if len(body) >= minCompressSize {
+		encoding = negotiateEncoding(c.GetHeader("Accept-Encoding"))
+	}
+	c.Header("Vary", "Accept, Accept-Encoding")
+	c.Header("ETag", variantETag(etag, encoding))
+
+	if etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	if encoding != "" {
+		compressed, err := compress(encoding, body)
+		if err == nil {
+			c.Header("Content-Encoding", encoding)
+			body = compressed
+		} else {
+			c.Header("ETag", etag)
+		}
+	}
+	c.Data(http.StatusOK, contentType, body)
+}
+
+func compress(encoding string, body []byte) ([]byte, error) {
+	pool := compressors[encoding]
+	w := pool.Get().(resettableWriter)
+	defer pool.Put(w)
+
+	var buf bytes.Buffer
+	w.Reset(&buf)
+	if _, err := w.Write(body); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,176 @@
+package handler
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"wb-project/internal/models"
+
+	"github.com/andybalholm/brotli"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func loadEncodedOrder(t testing.TB) models.EncodedOrder {
+	data, err := os.ReadFile("testdata/order.json")
+	require.NoError(t, err)
+	var order models.Order
+	require.NoError(t, json.Unmarshal(data, &order))
+	encoded, err := models.EncodeOrder(&order)
+	require.NoError(t, err)
+	return encoded
+}
+
+func TestNegotiateEncoding(t *testing.T) {
+	cases := map[string]string{
+		"":                      "",
+		"identity":              "",
+		"gzip":                  "gzip",
+		"gzip, deflate, br":     "br",
+		"br;q=0.5, gzip":        "gzip",
+		"br;q=0, gzip;q=0":      "",
+		"*":                     "br",
+		"*;q=0.1, gzip;q=0.2":   "gzip",
+		"deflate, GZIP;q=0.9":   "gzip",
+		"br;q=0.8, gzip;q=0.8":  "br",
+		"compress, x-unknown=1": "",
+	}
+	for header, want := range cases {
+		assert.Equal(t, want, negotiateEncoding(header), "Accept-Encoding: %q", header)
+	}
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`W/"abc"`, `"abc"`))
+	assert.True(t, etagMatches(`"x", "abc-gzip"`, `"abc"`))
+	assert.True(t, etagMatches(`*`, `"abc"`))
+	assert.False(t, etagMatches(`"abd"`, `"abc"`))
+	assert.False(t, etagMatches(``, `"abc"`))
+}
+
+func TestGetOrderHandler_Conditional(t *testing.T) {
+	encoded := loadEncodedOrder(t)
+	masked := *encoded.Masked // по умолчанию отдается маскированное представление
+	path := APIPrefix + "/orders/" + encoded.Order.OrderUID
+	get := func(router http.Handler, headers map[string]string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("ETag и Cache-Control", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+
+		w := get(router, nil)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, masked.ETag, w.Header().Get("ETag"))
+		assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+		assert.JSONEq(t, string(masked.JSON), w.Body.String())
+	})
+
+	t.Run("If-None-Match возвращает 304 без тела", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+
+		w := get(router, map[string]string{"If-None-Match": masked.ETag, "Accept-Encoding": "gzip"})
+
+		assert.Equal(t, http.StatusNotModified, w.Code)
+		assert.Empty(t, w.Body.Bytes())
+		assert.Equal(t, variantETag(masked.ETag, "gzip"), w.Header().Get("ETag"))
+	})
+
+	t.Run("Устаревший ETag", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+
+		w := get(router, map[string]string{"If-None-Match": `"stale"`})
+
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	for encoding, reader := range map[string]func(io.Reader) (io.Reader, error){
+		"gzip": func(r io.Reader) (io.Reader, error) { return gzip.NewReader(r) },
+		"br":   func(r io.Reader) (io.Reader, error) { return brotli.NewReader(r), nil },
+	} {
+		t.Run("Content-Encoding "+encoding, func(t *testing.T) {
+			router, mockService := newTestRouter(t)
+			mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+
+			w := get(router, map[string]string{"Accept-Encoding": encoding})
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, encoding, w.Header().Get("Content-Encoding"))
+			assert.Equal(t, "Accept, Accept-Encoding", w.Header().Get("Vary"))
+			r, err := reader(w.Body)
+			require.NoError(t, err)
+			body, err := io.ReadAll(r)
+			require.NoError(t, err)
+			assert.Equal(t, masked.JSON, body)
+		})
+	}
+}
+
+// Сравнение с прежним путем: повторная сериализация заказа через c.JSON на каждый запрос.
+func BenchmarkGetOrder_Marshal(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	encoded := loadEncodedOrder(b)
+	b.ReportAllocs()
+	for b.Loop() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.JSON(http.StatusOK, encoded.Order)
+	}
+}
+
+func BenchmarkGetOrder_Preserialized(b *testing.B) {
+	benchmarkRespond(b, "")
+}
+
+func BenchmarkGetOrder_Preserialized_Gzip(b *testing.B) {
+	benchmarkRespond(b, "gzip")
+}
+
+func BenchmarkGetOrder_Preserialized_Brotli(b *testing.B) {
+	benchmarkRespond(b, "br")
+}
+
+func BenchmarkGetOrder_NotModified(b *testing.B) {
+	gin.SetMode(gin.TestMode)
+	encoded := loadEncodedOrder(b)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("If-None-Match", encoded.ETag)
+	b.ReportAllocs()
+	for b.Loop() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		respondCacheable(c, "application/json; charset=utf-8", encoded.JSON, encoded.ETag)
+	}
+}
+
+func benchmarkRespond(b *testing.B, encoding string) {
+	gin.SetMode(gin.TestMode)
+	encoded := loadEncodedOrder(b)
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Accept-Encoding", encoding)
+	b.ReportAllocs()
+	for b.Loop() {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = req
+		respondCacheable(c, "application/json; charset=utf-8", encoded.JSON, encoded.ETag)
+	}
+}
@@ -0,0 +1,78 @@
+package handler
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"wb-project/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+// Машиночитаемые коды ошибок API. Клиенты (в том числе веб-консоль)
+// ориентируются на code, текст в error предназначен для человека.
+const (
+	CodeInvalidID            = "invalid_id"
+	CodeInvalidRequest       = "invalid_request"
+	CodeOrderNotFound        = "order_not_found"
+	CodeSubscriptionNotFound = "subscription_not_found"
+	CodeNotFound             = "not_found"
+	CodeNotAcceptable        = "not_acceptable"
+	CodeUnauthorized         = "unauthorized"
+	CodeForbidden            = "forbidden"
+	CodeRateLimited          = "rate_limited"
+	CodeConsumerStopped      = "consumer_stopped"
+	CodeConfigRejected       = "config_rejected"
+	CodeUnavailable          = "unavailable"
+	CodeInternal             = "internal_error"
+)
+
+// ErrorResponse - единый формат ошибки во всех ответах API.
+// Текст error на языке из Accept-Language (русский или английский).
+type ErrorResponse struct {
+	Code    string           `json:"code"`
+	Error   string           `json:"error"`
+	Details []i18n.Violation `json:"details,omitempty"` // ошибки валидации по полям
+}
+
+// respondError - прерывает обработку запроса и отдает ошибку в едином формате,
+// текст берется из каталога сообщений на языке клиента.
+func respondError(c *gin.Context, status int, code string, key i18n.Key, args ...any) {
+	tag := clientLanguage(c)
+	abortWithError(c, status, tag, ErrorResponse{Code: code, Error: i18n.T(tag, key, args...)})
+}
+
+// respondInvalid - 400 с причиной из err: переведенные ошибки validator по полям
+// или ошибка с текстом из каталога. Прочие ошибки не раскрываются.
+func respondInvalid(c *gin.Context, err error) {
+	tag := clientLanguage(c)
+	if violations, ok := i18n.Violations(err, tag); ok {
+		messages := make([]string, len(violations))
+		for i, v := range violations {
+			messages[i] = v.Message
+		}
+		abortWithError(c, http.StatusBadRequest, tag, ErrorResponse{
+			Code:    CodeInvalidRequest,
+			Error:   i18n.T(tag, i18n.MsgValidationFailed, strings.Join(messages, "; ")),
+			Details: violations,
+		})
+		return
+	}
+	var keyed *i18n.Error
+	if errors.As(err, &keyed) {
+		abortWithError(c, http.StatusBadRequest, tag, ErrorResponse{Code: CodeInvalidRequest, Error: keyed.Translate(tag)})
+		return
+	}
+	respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidBody)
+}
+
+func abortWithError(c *gin.Context, status int, tag language.Tag, resp ErrorResponse) {
+	c.Header("Content-Language", tag.String())
+	c.AbortWithStatusJSON(status, resp)
+}
+
+// clientLanguage - язык сообщений для клиента по Accept-Language.
+func clientLanguage(c *gin.Context) language.Tag {
+	return i18n.Match(c.GetHeader("Accept-Language"))
+}
@@ -0,0 +1,92 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wb-project/internal/i18n"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func errorContext(acceptLanguage string) (*gin.Context, *httptest.ResponseRecorder) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/", nil)
+	if acceptLanguage != "" {
+		c.Request.Header.Set("Accept-Language", acceptLanguage)
+	}
+	return c, w
+}
+
+func decodeError(t *testing.T, w *httptest.ResponseRecorder) ErrorResponse {
+	var resp ErrorResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp
+}
+
+func TestRespondError_AcceptLanguage(t *testing.T) {
+	tests := []struct {
+		acceptLanguage string
+		language       string
+		message        string
+	}{
+		{"", "ru", "Введен неверный ID: заказ не найден"},
+		{"en-GB,en;q=0.8", "en", "Order not found"},
+		{"ja", "ru", "Введен неверный ID: заказ не найден"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.acceptLanguage, func(t *testing.T) {
+			c, w := errorContext(tt.acceptLanguage)
+
+			respondError(c, http.StatusNotFound, CodeOrderNotFound, i18n.MsgOrderNotFound)
+
+			assert.Equal(t, http.StatusNotFound, w.Code)
+			assert.Equal(t, tt.language, w.Header().Get("Content-Language"))
+			resp := decodeError(t, w)
+			assert.Equal(t, CodeOrderNotFound, resp.Code)
+			assert.Equal(t, tt.message, resp.Error)
+		})
+	}
+}
+
+func TestRespondInvalid(t *testing.T) {
+	t.Run("Ошибки валидации по полям", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		c, w := errorContext("en")
+		err := i18n.Validator().Struct(CreateSubscriptionRequest{URL: "ftp//nope", Events: []string{"order.created"}})
+
+		//2. Act(Действие)
+		respondInvalid(c, fmt.Errorf("wrapped: %w", err))
+
+		//3. Assert
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		resp := decodeError(t, w)
+		assert.Equal(t, CodeInvalidRequest, resp.Code)
+		assert.Equal(t, "Request validation failed: url must be a valid URL", resp.Error)
+		assert.Equal(t, []i18n.Violation{{Field: "url", Message: "url must be a valid URL"}}, resp.Details)
+	})
+
+	t.Run("Ошибка из каталога", func(t *testing.T) {
+		c, w := errorContext("en")
+
+		respondInvalid(c, i18n.Errorf(i18n.MsgUnknownField, "payment.cost"))
+
+		resp := decodeError(t, w)
+		assert.Equal(t, `Unknown field "payment.cost"`, resp.Error)
+		assert.Empty(t, resp.Details)
+	})
+
+	t.Run("Прочие ошибки не раскрываются", func(t *testing.T) {
+		c, w := errorContext("")
+
+		respondInvalid(c, fmt.Errorf("pq: syntax error"))
+
+		assert.Equal(t, "Некорректное тело запроса", decodeError(t, w).Error)
+	})
+}
@@ -0,0 +1,210 @@
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/auth"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
+	"wb-project/internal/stream"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errUnavailable - БД недоступна, клиенту стоит повторить запрос позже.
+var errUnavailable = status.Error(codes.Unavailable, "хранилище заказов временно недоступно")
+
+// OrderReader - операции чтения заказов, общие для HTTP и gRPC.
+//
+//go:generate mockery --name=OrderReader --output=./mocks --case=underscore
+type OrderReader interface {
+	OrderProvider
+	GetOrderEncoded(ctx context.Context, uid string) (models.EncodedOrder, error)
+	GetOrders(ctx context.Context, uids []string) ([]models.Order, []string, error)
+	ListOrders(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error)
+}
+
+// GRPCOrderHandler - реализация gRPC OrderService поверх того же сервисного слоя, что и HTTP.
+type GRPCOrderHandler struct {
+	orderv1.UnimplementedOrderServiceServer
+	service  OrderReader
+	auditor  Auditor
+	hub      *stream.Hub
+	maxBatch int // ограничение количества UID в одном BatchGetOrders
+}
+
+func NewGRPCOrderHandler(s OrderReader, auditor Auditor, hub *stream.Hub, maxBatch int) *GRPCOrderHandler {
+	return &GRPCOrderHandler{service: s, auditor: auditor, hub: hub, maxBatch: maxBatch}
+}
+
+func (h *GRPCOrderHandler) GetOrder(ctx context.Context, req *orderv1.GetOrderRequest) (*orderv1.Order, error) {
+	if req.GetOrderUid() == "" {
+		return nil, status.Error(codes.InvalidArgument, "Неправильный ID")
+	}
+	unmasked, err := h.view(ctx)
+	if err != nil {
+		return nil, err
+	}
+	order, err := h.service.GetOrder(ctx, req.GetOrderUid())
+	if err != nil {
+		slog.Error("order не найден", slog.String("uid", req.GetOrderUid()), slog.Any("error", err), sl.Traced(ctx))
+		if errors.Is(err, models.ErrUnavailable) {
+			return nil, errUnavailable
+		}
+		return nil, status.Error(codes.NotFound, "заказ не найден")
+	}
+	if unmasked {
+		if err := h.audit(ctx, "GetOrder", []string{order.OrderUID}); err != nil {
+			return nil, err
+		}
+	}
+	return orderProto(&order, unmasked), nil
+}
+
+func (h *GRPCOrderHandler) BatchGetOrders(ctx context.Context, req *orderv1.BatchGetOrdersRequest) (*orderv1.BatchGetOrdersResponse, error) {
+	uids := req.GetOrderUids()
+	if len(uids) == 0 || len(uids) > h.maxBatch {
+		return nil, status.Errorf(codes.InvalidArgument, "количество UID должно быть от 1 до %d", h.maxBatch)
+	}
+	unmasked, err := h.view(ctx)
+	if err != nil {
+		return nil, err
+	}
+	orders, missing, err := h.service.GetOrders(ctx, uids)
+	if errors.Is(err, models.ErrUnavailable) {
+		return nil, errUnavailable
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "не удалось получить заказы")
+	}
+	if unmasked {
+		if err := h.audit(ctx, "BatchGetOrders", orderUIDs(orders)); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &orderv1.BatchGetOrdersResponse{MissingUids: missing}
+	for i := range orders {
+		resp.Orders = append(resp.Orders, orderProto(&orders[i], unmasked))
+	}
+	return resp, nil
+}
+
+func (h *GRPCOrderHandler) ListOrders(ctx context.Context, req *orderv1.ListOrdersRequest) (*orderv1.ListOrdersResponse, error) {
+	after, err := models.ParseOrderCursor(req.GetPageToken())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	unmasked, err := h.view(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// поиск по контактам раскрывает, есть ли заказы на телефон или email
+	if p, _ := auth.FromContext(ctx); (req.GetPhone() != "" || req.GetEmail() != "") && !p.Allows(auth.RoleSupport) {
+		return nil, status.Error(codes.PermissionDenied, "поиск по телефону и email доступен ролям support и admin")
+	}
+	page, err := h.service.ListOrders(ctx, models.OrderListQuery{
+		Limit:           int(req.GetPageSize()),
+		After:           after,
+		Entry:           req.GetEntry(),
+		DeliveryService: req.GetDeliveryService(),
+		CustomerID:      req.GetCustomerId(),
+		Phone:           req.GetPhone(),
+		Email:           req.GetEmail(),
+	})
+	if errors.Is(err, models.ErrUnavailable) {
+		return nil, errUnavailable
+	}
+	if err != nil {
+		return nil, status.Error(codes.Internal, "не удалось получить список заказов")
+	}
+	if unmasked {
+		if err := h.audit(ctx, "ListOrders", orderUIDs(page.Orders)); err != nil {
+			return nil, err
+		}
+	}
+
+	resp := &orderv1.ListOrdersResponse{}
+	for i := range page.Orders {
+		resp.Orders = append(resp.Orders, orderProto(&page.Orders[i], unmasked))
+	}
+	if page.Next != nil {
+		resp.NextPageToken = page.Next.Token()
+	}
+	return resp, nil
+}
+
+// view - запрошен ли вид без маскирования; он доступен только ролям support и admin.
+func (h *GRPCOrderHandler) view(ctx context.Context) (bool, error) {
+	unmasked, _ := grpcView(ctx)
+	if !unmasked {
+		return false, nil
+	}
+	if p, _ := auth.FromContext(ctx); !p.Allows(auth.RoleSupport) {
+		return false, status.Error(codes.PermissionDenied, "персональные данные без маскирования доступны ролям support и admin")
+	}
+	return true, nil
+}
+
+// audit - запись в журнал аудита; без нее данные без маскирования не отдаются.
+func (h *GRPCOrderHandler) audit(ctx context.Context, method string, uids []string) error {
+	p, _ := auth.FromContext(ctx)
+	_, reason := grpcView(ctx)
+	if err := recordUnmasked(ctx, h.auditor, p, "grpc", method, reason, uids); err != nil {
+		slog.Error("не удалось записать доступ в журнал аудита", slog.Any("error", err), sl.Traced(ctx))
+		return status.Error(codes.Internal, "журнал аудита недоступен")
+	}
+	return nil
+}
+
+// orderProto - заказ для ответа; без явного запроса вида unmasked персональные данные маскируются.
+func orderProto(order *models.Order, unmasked bool) *orderv1.Order {
+	if unmasked {
+		return OrderToProto(order)
+	}
+	masked := pii.Redact(*order)
+	return OrderToProto(&masked)
+}
+
+// WatchOrders - подписка на хаб новых заказов. Медленный клиент отключается хабом
+// и получает Unavailable, чтобы переподключиться с last_event_id.
+// Как и в SSE, персональные данные в потоке всегда маскируются.
+func (h *GRPCOrderHandler) WatchOrders(req *orderv1.WatchOrdersRequest, srv orderv1.OrderService_WatchOrdersServer) error {
+	sub, backlog := h.hub.Subscribe(stream.Filter{
+		Entry:           req.GetEntry(),
+		DeliveryService: req.GetDeliveryService(),
+	}, req.GetLastEventId())
+	defer h.hub.Unsubscribe(sub)
+
+	send := func(e stream.Event) error {
+		var order models.Order
+		if err := json.Unmarshal(e.Data, &order); err != nil {
+			return status.Error(codes.Internal, "не удалось прочитать заказ")
+		}
+		return srv.Send(&orderv1.OrderEvent{EventId: e.ID, Order: orderProto(&order, false)})
+	}
+
+	for _, e := range backlog {
+		if err := send(e); err != nil {
+			return err
+		}
+	}
+	for {
+		select {
+		case <-srv.Context().Done():
+			return srv.Context().Err()
+		case e, ok := <-sub.C:
+			if !ok {
+				return status.Error(codes.Unavailable, "подписка закрыта, переподключитесь с last_event_id")
+			}
+			if err := send(e); err != nil {
+				return err
+			}
+		}
+	}
+}
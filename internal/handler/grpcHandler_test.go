@@ -0,0 +1,66 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"testing"
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/models"
+	"wb-project/internal/stream"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGRPCOrderHandler_GetOrder(t *testing.T) {
+	t.Run("Заказ найден", func(t *testing.T) {
+		mockService := mocks.NewOrderReader(t)
+		mockService.On("GetOrder", mock.Anything, "uid").Return(models.Order{OrderUID: "uid", SmID: 99}, nil)
+
+		h := NewGRPCOrderHandler(mockService, nil, stream.NewHub(1, 1), 100)
+		resp, err := h.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: "uid"})
+
+		assert.NoError(t, err)
+		assert.Equal(t, "uid", resp.GetOrderUid())
+		assert.Equal(t, int64(99), resp.GetSmId())
+	})
+
+	t.Run("Заказ не найден", func(t *testing.T) {
+		mockService := mocks.NewOrderReader(t)
+		mockService.On("GetOrder", mock.Anything, "unknown").Return(models.Order{}, errors.New("not found"))
+
+		h := NewGRPCOrderHandler(mockService, nil, stream.NewHub(1, 1), 100)
+		_, err := h.GetOrder(context.Background(), &orderv1.GetOrderRequest{OrderUid: "unknown"})
+
+		assert.Equal(t, codes.NotFound, status.Code(err))
+	})
+
+	t.Run("Пустой ID", func(t *testing.T) {
+		mockService := mocks.NewOrderReader(t)
+
+		h := NewGRPCOrderHandler(mockService, nil, stream.NewHub(1, 1), 100)
+		_, err := h.GetOrder(context.Background(), &orderv1.GetOrderRequest{})
+
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+		mockService.AssertNotCalled(t, "GetOrder")
+	})
+}
+
+func TestGRPCOrderHandler_BatchGetOrders(t *testing.T) {
+	mockService := mocks.NewOrderReader(t)
+	mockService.On("GetOrders", mock.Anything, []string{"1", "2"}).
+		Return([]models.Order{{OrderUID: "1"}}, []string{"2"}, nil)
+
+	h := NewGRPCOrderHandler(mockService, nil, stream.NewHub(1, 1), 100)
+	resp, err := h.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{OrderUids: []string{"1", "2"}})
+
+	assert.NoError(t, err)
+	assert.Len(t, resp.GetOrders(), 1)
+	assert.Equal(t, []string{"2"}, resp.GetMissingUids())
+
+	_, err = h.BatchGetOrders(context.Background(), &orderv1.BatchGetOrdersRequest{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -0,0 +1,51 @@
+package handler
+
+import (
+	"context"
+	"net/http"
+	"wb-project/internal/health"
+
+	"github.com/gin-gonic/gin"
+)
+
+//go:generate mockery --name=Prober --output=./mocks --case=underscore
+type Prober interface {
+	Live(ctx context.Context) health.Report
+	Ready(ctx context.Context) health.Report
+	Startup(ctx context.Context) health.Report
+}
+
+// HealthHandler - пробы для оркестратора и балансировщика: /healthz, /readyz, /startupz.
+// Открыты без аутентификации и ограничения частоты.
+type HealthHandler struct {
+	probes Prober
+}
+
+func NewHealthHandler(probes Prober) *HealthHandler {
+	return &HealthHandler{probes: probes}
+}
+
+// Live - GET /healthz: процесс жив.
+func (h *HealthHandler) Live(c *gin.Context) {
+	respondHealth(c, h.probes.Live(c.Request.Context()))
+}
+
+// Ready - GET /readyz: БД, Kafka и консьюмер доступны, запуск завершен и сервис не останавливается.
+func (h *HealthHandler) Ready(c *gin.Context) {
+	respondHealth(c, h.probes.Ready(c.Request.Context()))
+}
+
+// Startup - GET /startupz: кэш разогрет.
+func (h *HealthHandler) Startup(c *gin.Context) {
+	respondHealth(c, h.probes.Startup(c.Request.Context()))
+}
+
+// respondHealth - 200 или 503 с разбивкой по компонентам; результат не кэшируется.
+func respondHealth(c *gin.Context, report health.Report) {
+	status := http.StatusOK
+	if !report.Up() {
+		status = http.StatusServiceUnavailable
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(status, report)
+}
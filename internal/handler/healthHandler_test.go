@@ -0,0 +1,48 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/health"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	down := health.Report{Status: health.StatusDown, Components: map[string]health.Component{
+		"database":               {Status: health.StatusUp, LatencyMS: 1.5},
+		health.ComponentShutdown: {Status: health.StatusDown, Error: health.ErrDraining.Error()},
+	}}
+
+	//1. Arrange(подготовка)
+	probes := mocks.NewProber(t)
+	probes.On("Live", mock.Anything).Return(health.Report{Status: health.StatusUp})
+	probes.On("Ready", mock.Anything).Return(down)
+	h := NewHealthHandler(probes)
+	router := gin.New()
+	router.GET("/healthz", h.Live)
+	router.GET("/readyz", h.Ready)
+
+	//2. Act(Действие)
+	live := httptest.NewRecorder()
+	router.ServeHTTP(live, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	ready := httptest.NewRecorder()
+	router.ServeHTTP(ready, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	//3. Assert
+	assert.Equal(t, http.StatusOK, live.Code)
+	assert.JSONEq(t, `{"status":"up"}`, live.Body.String())
+
+	assert.Equal(t, http.StatusServiceUnavailable, ready.Code)
+	assert.Equal(t, "no-store", ready.Header().Get("Cache-Control"))
+	var got health.Report
+	require.NoError(t, json.Unmarshal(ready.Body.Bytes(), &got))
+	assert.Equal(t, down, got)
+}
@@ -2,12 +2,19 @@ package handler
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/config"
+	"wb-project/internal/i18n"
 	"wb-project/internal/logger/sl"
 	"wb-project/internal/metric"
 	"wb-project/internal/models"
+	"wb-project/internal/receipt"
 
 	"github.com/gin-gonic/gin"
 	"go.opentelemetry.io/otel/attribute"
@@ -22,11 +29,27 @@ type OrderProvider interface {
 }
 
 type OrderHandler struct {
-	service OrderProvider // Используем интерфейс
+	service      OrderReader // Используем интерфейс
+	auditor      Auditor
+	maxBatch     int
+	cacheControl string
+	receipts     *receipt.Renderer
 }
 
-func NewOrderHandler(s OrderProvider) *OrderHandler {
-	return &OrderHandler{service: s}
+func NewOrderHandler(s OrderReader, auditor Auditor, cfg config.OrdersConfig) *OrderHandler {
+	return &OrderHandler{
+		service:      s,
+		auditor:      auditor,
+		maxBatch:     cfg.BatchMaxSize,
+		cacheControl: fmt.Sprintf("private, max-age=%d", int(cfg.CacheMaxAge.Seconds())),
+		receipts:     receipt.Default(),
+	}
+}
+
+// WithReceipts - шаблоны печатных форм вместо встроенных.
+func (s *OrderHandler) WithReceipts(r *receipt.Renderer) *OrderHandler {
+	s.receipts = r
+	return s
 }
 
 //Запустить HTTP-сервер для выдачи данных по ID: реализовать HTTP-эндпоинт, который по order_id будет
@@ -36,7 +59,12 @@ func (s *OrderHandler) GetOrderHandler(c *gin.Context) {
 	ctx := c.Request.Context()
 	uid := c.Param("order_uid")
 	if uid == "" {
-		c.JSON(http.StatusBadRequest, gin.H{"error": "Неправильный ID"})
+		respondError(c, http.StatusBadRequest, CodeInvalidID, i18n.MsgInvalidOrderID)
+		return
+	}
+
+	rep, ok := negotiateRepresentation(c)
+	if !ok {
 		return
 	}
 
@@ -47,17 +75,162 @@ func (s *OrderHandler) GetOrderHandler(c *gin.Context) {
 	span := trace.SpanFromContext(ctx)
 	span.SetAttributes(attribute.String("http.request.order_uid", uid))
 
-	order, err := s.service.GetOrder(ctx, uid)
+	order, err := s.service.GetOrderEncoded(ctx, uid)
 	if err != nil {
 		slog.Error("order не найден",
 			slog.String("uid", uid),
 			slog.Any("error", err),
 			sl.Traced(ctx))
 		span.RecordError(err)
-		c.JSON(http.StatusNotFound, gin.H{"error": "Введен неверный ID: заказ не найден"})
+		if errors.Is(err, models.ErrUnavailable) {
+			respondError(c, http.StatusServiceUnavailable, CodeUnavailable, i18n.MsgStorageUnavailable)
+			return
+		}
+		respondError(c, http.StatusNotFound, CodeOrderNotFound, i18n.MsgOrderNotFound)
+		return
+	}
+	if rep.unmasked {
+		if !s.auditUnmasked(c, []string{uid}) {
+			return
+		}
+		// данные без маскирования не должны оседать даже в кэше браузера
+		c.Header("Cache-Control", "private, no-store")
+	} else {
+		// Заказ содержит персональные данные: кэшировать можно только на клиенте
+		c.Header("Cache-Control", s.cacheControl)
+	}
+	rep.respondOrder(c, order)
+}
+
+// BatchGetOrdersRequest - тело POST /orders:batchGet.
+type BatchGetOrdersRequest struct {
+	OrderUIDs []string `json:"order_uids" validate:"required,min=1,dive,required"`
+}
+
+// BatchGetOrdersResponse - найденные заказы в порядке запроса и UID, которых нет.
+type BatchGetOrdersResponse struct {
+	Orders      []models.Order `json:"orders"`
+	MissingUIDs []string       `json:"missing_uids"`
+}
+
+// BatchGetHandler - несколько заказов за один запрос вместо N вызовов GetOrderHandler.
+func (s *OrderHandler) BatchGetHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	var req BatchGetOrdersRequest
+	if err := c.ShouldBindJSON(&req); err != nil || len(req.OrderUIDs) == 0 {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgOrderUIDsRequired)
+		return
+	}
+	if len(req.OrderUIDs) > s.maxBatch {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgTooManyOrderUIDs, s.maxBatch)
+		return
+	}
+	for _, uid := range req.OrderUIDs {
+		if uid == "" {
+			respondError(c, http.StatusBadRequest, CodeInvalidID, i18n.MsgInvalidOrderID)
+			return
+		}
+	}
+	rep, ok := negotiateRepresentation(c)
+	if !ok {
+		return
+	}
+
+	span := trace.SpanFromContext(ctx)
+	span.SetAttributes(attribute.Int("http.request.batch_size", len(req.OrderUIDs)))
+
+	orders, missing, err := s.service.GetOrders(ctx, req.OrderUIDs)
+	if err != nil {
+		slog.Error("не удалось получить заказы", slog.Any("error", err), sl.Traced(ctx))
+		span.RecordError(err)
+		respondOrdersError(c, err)
+		return
+	}
+	if rep.unmasked && !s.auditUnmasked(c, orderUIDs(orders)) {
 		return
 	}
-	c.JSON(http.StatusOK, order)
+	rep.respondBatch(c, orders, missing)
+}
+
+// ListOrdersResponse - страница заказов от новых к старым.
+type ListOrdersResponse struct {
+	Orders        []models.Order `json:"orders"`
+	NextPageToken string         `json:"next_page_token,omitempty"`
+}
+
+// ListOrdersHandler - GET /orders: постраничный список с фильтрами, как ListOrders в gRPC.
+// ?page_size= (по умолчанию и максимум задает хранилище), ?page_token= из предыдущего ответа,
+// фильтры entry, delivery_service, customer_id, а также phone и email для ролей support и admin.
+func (s *OrderHandler) ListOrdersHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+
+	pageSize, err := strconv.Atoi(c.DefaultQuery("page_size", "0"))
+	if err != nil || pageSize < 0 {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidPageSize)
+		return
+	}
+	after, err := models.ParseOrderCursor(c.Query("page_token"))
+	if err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidPageToken)
+		return
+	}
+	q := models.OrderListQuery{
+		Limit:           pageSize,
+		After:           after,
+		Entry:           c.Query("entry"),
+		DeliveryService: c.Query("delivery_service"),
+		CustomerID:      c.Query("customer_id"),
+		Phone:           c.Query("phone"),
+		Email:           c.Query("email"),
+	}
+	// поиск по контактам раскрывает, есть ли заказы на телефон или email
+	if (q.Phone != "" || q.Email != "") && !principal(c).Allows(auth.RoleSupport) {
+		respondError(c, http.StatusForbidden, CodeForbidden, i18n.MsgContactSearchForbidden)
+		return
+	}
+	rep, ok := negotiateRepresentation(c)
+	if !ok {
+		return
+	}
+
+	page, err := s.service.ListOrders(ctx, q)
+	if err != nil {
+		slog.Error("не удалось получить список заказов", slog.Any("error", err), sl.Traced(ctx))
+		trace.SpanFromContext(ctx).RecordError(err)
+		respondOrdersError(c, err)
+		return
+	}
+	if rep.unmasked && !s.auditUnmasked(c, orderUIDs(page.Orders)) {
+		return
+	}
+	var next string
+	if page.Next != nil {
+		next = page.Next.Token()
+	}
+	rep.respondPage(c, page.Orders, next)
+}
+
+// respondOrdersError - 503, если БД недоступна и запрос стоит повторить, иначе 500.
+func respondOrdersError(c *gin.Context, err error) {
+	if errors.Is(err, models.ErrUnavailable) {
+		respondError(c, http.StatusServiceUnavailable, CodeUnavailable, i18n.MsgStorageUnavailable)
+		return
+	}
+	respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgOrdersUnavailable)
+}
+
+// auditUnmasked - запись в журнал аудита перед выдачей данных без маскирования.
+// Если журнал недоступен, данные не отдаются (ответ уже отправлен).
+func (s *OrderHandler) auditUnmasked(c *gin.Context, uids []string) bool {
+	ctx := c.Request.Context()
+	err := recordUnmasked(ctx, s.auditor, principal(c), "http", c.FullPath(), c.GetHeader(HeaderAccessReason), uids)
+	if err != nil {
+		slog.Error("не удалось записать доступ в журнал аудита", slog.Any("error", err), sl.Traced(ctx))
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgAuditUnavailable)
+		return false
+	}
+	return true
 }
 
 func MetricsMiddleware() gin.HandlerFunc {
@@ -3,9 +3,12 @@ package handler
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"time"
+	"wb-project/internal/config"
 	"wb-project/internal/handler/mocks"
 	"wb-project/internal/models"
 
@@ -14,22 +17,25 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+var testOrdersConfig = config.OrdersConfig{BatchMaxSize: 100, CacheMaxAge: time.Minute}
+
 func TestOrderHandler_GetOrderHandler(t *testing.T) {
 	gin.SetMode(gin.TestMode)
 
 	t.Run("Заказ найден", func(t *testing.T) {
-		mockService := mocks.NewOrderProvider(t)
+		mockService := mocks.NewOrderReader(t)
 		orderUID := "test_uid"
 		expectedOrder := models.Order{OrderUID: orderUID}
 
-		mockService.On("GetOrder", mock.Anything, orderUID).Return(expectedOrder, nil)
+		encoded, _ := models.EncodeOrder(&expectedOrder)
+		mockService.On("GetOrderEncoded", mock.Anything, orderUID).Return(encoded, nil)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Request, _ = http.NewRequest("GET", "/", nil)
 		c.Params = []gin.Param{{Key: "order_uid", Value: orderUID}}
 
-		h := NewOrderHandler(mockService)
+		h := NewOrderHandler(mockService, nil, testOrdersConfig)
 		h.GetOrderHandler(c)
 
 		assert.Equal(t, http.StatusOK, w.Code)
@@ -42,24 +48,41 @@ func TestOrderHandler_GetOrderHandler(t *testing.T) {
 	})
 
 	t.Run("Заказ не найден в системе", func(t *testing.T) {
-		mockService := mocks.NewOrderProvider(t)
+		mockService := mocks.NewOrderReader(t)
 
 		badUID := "unknown"
-		mockService.On("GetOrder", mock.Anything, badUID).Return(models.Order{}, errors.New("not found"))
+		mockService.On("GetOrderEncoded", mock.Anything, badUID).Return(models.EncodedOrder{}, errors.New("not found"))
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
 		c.Params = []gin.Param{{Key: "order_uid", Value: badUID}}
 		c.Request, _ = http.NewRequest("GET", "/", nil)
 
-		h := NewOrderHandler(mockService)
+		h := NewOrderHandler(mockService, nil, testOrdersConfig)
 		h.GetOrderHandler(c)
 
 		assert.Equal(t, 404, w.Code)
 	})
 
+	t.Run("БД недоступна", func(t *testing.T) {
+		mockService := mocks.NewOrderReader(t)
+		mockService.On("GetOrderEncoded", mock.Anything, "uid").
+			Return(models.EncodedOrder{}, fmt.Errorf("order не найден в БД %w", models.ErrUnavailable))
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Params = []gin.Param{{Key: "order_uid", Value: "uid"}}
+		c.Request, _ = http.NewRequest("GET", "/", nil)
+
+		h := NewOrderHandler(mockService, nil, testOrdersConfig)
+		h.GetOrderHandler(c)
+
+		assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+		assert.Contains(t, w.Body.String(), CodeUnavailable)
+	})
+
 	t.Run("Пустой ID", func(t *testing.T) {
-		mockService := mocks.NewOrderProvider(t)
+		mockService := mocks.NewOrderReader(t)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -67,10 +90,10 @@ func TestOrderHandler_GetOrderHandler(t *testing.T) {
 		c.Params = []gin.Param{{Key: "order_uid", Value: ""}}
 		c.Request, _ = http.NewRequest("GET", "/", nil)
 
-		h := NewOrderHandler(mockService)
+		h := NewOrderHandler(mockService, nil, testOrdersConfig)
 		h.GetOrderHandler(c)
 
 		assert.Equal(t, http.StatusBadRequest, w.Code)
-		mockService.AssertNotCalled(t, "GetOrder")
+		mockService.AssertNotCalled(t, "GetOrderEncoded")
 	})
 }
@@ -0,0 +1,304 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	admin "wb-project/internal/admin"
+	cache "wb-project/internal/cache"
+
+	config "wb-project/internal/config"
+
+	context "context"
+
+	kafka "wb-project/internal/kafka"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "wb-project/internal/models"
+)
+
+// AdminManager is an autogenerated mock type for the AdminManager type
+type AdminManager struct {
+	mock.Mock
+}
+
+// CacheEntries provides a mock function with given fields: ctx, limit
+func (_m *AdminManager) CacheEntries(ctx context.Context, limit int) ([]cache.Entry, int) {
+	ret := _m.Called(ctx, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheEntries")
+	}
+
+	var r0 []cache.Entry
+	var r1 int
+	if rf, ok := ret.Get(0).(func(context.Context, int) ([]cache.Entry, int)); ok {
+		return rf(ctx, limit)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int) []cache.Entry); ok {
+		r0 = rf(ctx, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]cache.Entry)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int) int); ok {
+		r1 = rf(ctx, limit)
+	} else {
+		r1 = ret.Get(1).(int)
+	}
+
+	return r0, r1
+}
+
+// CacheEntry provides a mock function with given fields: ctx, uid
+func (_m *AdminManager) CacheEntry(ctx context.Context, uid string) (cache.Entry, models.EncodedOrder, error) {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheEntry")
+	}
+
+	var r0 cache.Entry
+	var r1 models.EncodedOrder
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (cache.Entry, models.EncodedOrder, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) cache.Entry); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(cache.Entry)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) models.EncodedOrder); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Get(1).(models.EncodedOrder)
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, string) error); ok {
+		r2 = rf(ctx, uid)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// CacheStats provides a mock function with given fields: ctx
+func (_m *AdminManager) CacheStats(ctx context.Context) cache.Stats {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CacheStats")
+	}
+
+	var r0 cache.Stats
+	if rf, ok := ret.Get(0).(func(context.Context) cache.Stats); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(cache.Stats)
+	}
+
+	return r0
+}
+
+// ConsumerPosition provides a mock function with given fields: ctx
+func (_m *AdminManager) ConsumerPosition(ctx context.Context) (kafka.Position, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ConsumerPosition")
+	}
+
+	var r0 kafka.Position
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (kafka.Position, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) kafka.Position); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(kafka.Position)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Evict provides a mock function with given fields: ctx, uid
+func (_m *AdminManager) Evict(ctx context.Context, uid string) error {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Evict")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) error); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Flush provides a mock function with given fields: ctx
+func (_m *AdminManager) Flush(ctx context.Context) int {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Flush")
+	}
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	return r0
+}
+
+// PauseConsumer provides a mock function with given fields: ctx
+func (_m *AdminManager) PauseConsumer(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PauseConsumer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// Reload provides a mock function with given fields: ctx
+func (_m *AdminManager) Reload(ctx context.Context) (int, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Reload")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (int, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) int); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ReloadConfig provides a mock function with given fields: ctx
+func (_m *AdminManager) ReloadConfig(ctx context.Context) (config.ReloadResult, error) {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ReloadConfig")
+	}
+
+	var r0 config.ReloadResult
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context) (config.ReloadResult, error)); ok {
+		return rf(ctx)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context) config.ReloadResult); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(config.ReloadResult)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ResumeConsumer provides a mock function with given fields: ctx
+func (_m *AdminManager) ResumeConsumer(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeConsumer")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// SeekConsumer provides a mock function with given fields: ctx, s
+func (_m *AdminManager) SeekConsumer(ctx context.Context, s admin.Seek) (int64, error) {
+	ret := _m.Called(ctx, s)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SeekConsumer")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, admin.Seek) (int64, error)); ok {
+		return rf(ctx, s)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, admin.Seek) int64); ok {
+		r0 = rf(ctx, s)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, admin.Seek) error); ok {
+		r1 = rf(ctx, s)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewAdminManager creates a new instance of AdminManager. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAdminManager(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *AdminManager {
+	mock := &AdminManager{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
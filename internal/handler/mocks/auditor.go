@@ -0,0 +1,48 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "wb-project/internal/models"
+)
+
+// Auditor is an autogenerated mock type for the Auditor type
+type Auditor struct {
+	mock.Mock
+}
+
+// RecordPIIAccess provides a mock function with given fields: ctx, a
+func (_m *Auditor) RecordPIIAccess(ctx context.Context, a models.PIIAccess) error {
+	ret := _m.Called(ctx, a)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordPIIAccess")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.PIIAccess) error); ok {
+		r0 = rf(ctx, a)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// NewAuditor creates a new instance of Auditor. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewAuditor(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Auditor {
+	mock := &Auditor{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,153 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	mock "github.com/stretchr/testify/mock"
+
+	models "wb-project/internal/models"
+)
+
+// OrderReader is an autogenerated mock type for the OrderReader type
+type OrderReader struct {
+	mock.Mock
+}
+
+// GetOrder provides a mock function with given fields: ctx, uid
+func (_m *OrderReader) GetOrder(ctx context.Context, uid string) (models.Order, error) {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrder")
+	}
+
+	var r0 models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Order, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Order); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(models.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrderEncoded provides a mock function with given fields: ctx, uid
+func (_m *OrderReader) GetOrderEncoded(ctx context.Context, uid string) (models.EncodedOrder, error) {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrderEncoded")
+	}
+
+	var r0 models.EncodedOrder
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.EncodedOrder, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.EncodedOrder); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(models.EncodedOrder)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetOrders provides a mock function with given fields: ctx, uids
+func (_m *OrderReader) GetOrders(ctx context.Context, uids []string) ([]models.Order, []string, error) {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetOrders")
+	}
+
+	var r0 []models.Order
+	var r1 []string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]models.Order, []string, error)); ok {
+		return rf(ctx, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []models.Order); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) []string); ok {
+		r1 = rf(ctx, uids)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]string)
+		}
+	}
+
+	if rf, ok := ret.Get(2).(func(context.Context, []string) error); ok {
+		r2 = rf(ctx, uids)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListOrders provides a mock function with given fields: ctx, q
+func (_m *OrderReader) ListOrders(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListOrders")
+	}
+
+	var r0 models.OrderPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderListQuery) (models.OrderPage, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderListQuery) models.OrderPage); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Get(0).(models.OrderPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.OrderListQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrderReader creates a new instance of OrderReader. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrderReader(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *OrderReader {
+	mock := &OrderReader{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
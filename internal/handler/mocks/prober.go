@@ -0,0 +1,84 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+
+	health "wb-project/internal/health"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Prober is an autogenerated mock type for the Prober type
+type Prober struct {
+	mock.Mock
+}
+
+// Live provides a mock function with given fields: ctx
+func (_m *Prober) Live(ctx context.Context) health.Report {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Live")
+	}
+
+	var r0 health.Report
+	if rf, ok := ret.Get(0).(func(context.Context) health.Report); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(health.Report)
+	}
+
+	return r0
+}
+
+// Ready provides a mock function with given fields: ctx
+func (_m *Prober) Ready(ctx context.Context) health.Report {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Ready")
+	}
+
+	var r0 health.Report
+	if rf, ok := ret.Get(0).(func(context.Context) health.Report); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(health.Report)
+	}
+
+	return r0
+}
+
+// Startup provides a mock function with given fields: ctx
+func (_m *Prober) Startup(ctx context.Context) health.Report {
+	ret := _m.Called(ctx)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Startup")
+	}
+
+	var r0 health.Report
+	if rf, ok := ret.Get(0).(func(context.Context) health.Report); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(health.Report)
+	}
+
+	return r0
+}
+
+// NewProber creates a new instance of Prober. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewProber(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Prober {
+	mock := &Prober{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
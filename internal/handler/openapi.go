@@ -0,0 +1,449 @@
+package handler
+
+import (
+	_ "embed"
+	"net/http"
+	"wb-project/internal/admin"
+	"wb-project/internal/cache"
+	"wb-project/internal/config"
+	"wb-project/internal/kafka"
+	"wb-project/internal/models"
+	"wb-project/internal/openapi"
+	"wb-project/internal/receipt"
+	"wb-project/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// APIPrefix - префикс версионированного API.
+const APIPrefix = "/api/v1"
+
+//go:embed docs/index.html
+var docsPage []byte
+
+// NewOpenAPI - описание /api/v1. Схемы тел выводятся из тех же структур,
+// которые отдают хендлеры; тест роутера сверяет документ с зарегистрированными маршрутами.
+func NewOpenAPI() *openapi.Document {
+	doc := &openapi.Document{
+		OpenAPI: "3.1.0",
+		Info: openapi.Info{
+			Title:   "WB Order Service API",
+			Version: "1.0.0",
+			Description: "Получение заказов, поток новых заказов, управление вебхуками, кэшем и консьюмером. " +
+				"Текст ошибок выбирается по Accept-Language (ru по умолчанию, en).",
+		},
+		Servers: []openapi.Server{{URL: APIPrefix}},
+		Paths:   map[string]*openapi.PathItem{},
+	}
+
+	order := doc.Register(models.Order{})
+	errResp := doc.Register(ErrorResponse{})
+	subscription := doc.Register(models.WebhookSubscription{})
+	delivery := doc.Register(models.WebhookDelivery{})
+
+	errorResponse := func(description string) *openapi.Response {
+		return &openapi.Response{Description: description, Content: openapi.JSON(errResp)}
+	}
+	idParam := openapi.PathParam("id", "ID подписки")
+	fieldsParam := openapi.QueryParam("fields", "Выбор полей через запятую, например order_uid,payment.amount,items.name",
+		&openapi.Schema{Type: "string"})
+	// персональные данные маскируются, view=unmasked - только support/admin с записью в журнал аудита
+	viewParams := []openapi.Parameter{
+		openapi.QueryParam(QueryView, "masked (по умолчанию) или unmasked - персональные данные без маскирования, роли support и admin",
+			&openapi.Schema{Type: "string", Enum: []any{ViewMasked, ViewUnmasked}}),
+		{Name: HeaderAccessReason, In: "header", Description: "Основание доступа без маскирования, попадает в журнал аудита", Schema: &openapi.Schema{Type: "string"}},
+	}
+	// negotiated - JSON-схема и альтернативные форматы, выбираемые заголовком Accept.
+	negotiated := func(s *openapi.Schema) map[string]openapi.MediaType {
+		content := openapi.JSON(s)
+		content[MediaMsgPack] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary", Description: "MessagePack с той же структурой, что и JSON"}}
+		content[MediaProtobuf] = openapi.MediaType{Schema: &openapi.Schema{Type: "string", Format: "binary", Description: "Сообщение из api/order/v1/order.proto"}}
+		return content
+	}
+	cacheHeaders := map[string]openapi.Header{
+		"ETag":          {Description: "Версия заказа, для сжатых ответов с суффиксом кодировки", Schema: &openapi.Schema{Type: "string"}},
+		"Cache-Control": {Schema: &openapi.Schema{Type: "string"}},
+	}
+
+	doc.Paths["/orders/{order_uid}"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getOrder",
+			Summary:     "Заказ по UID из кэша или БД",
+			Tags:        []string{"orders"},
+			Parameters: []openapi.Parameter{
+				openapi.PathParam("order_uid", "UID заказа"),
+				fieldsParam,
+				viewParams[0],
+				viewParams[1],
+				{Name: "If-None-Match", In: "header", Description: "ETag ранее полученной версии", Schema: &openapi.Schema{Type: "string"}},
+			},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Заказ", Headers: cacheHeaders, Content: negotiated(order)},
+				"304": {Description: "Заказ не изменился", Headers: cacheHeaders},
+				"400": errorResponse("Пустой или некорректный UID, неизвестное поле в fields или view"),
+				"403": errorResponse("view=unmasked без роли support или admin"),
+				"406": errorResponse("Формат из Accept не поддерживается"),
+				"404": errorResponse("Заказ не найден"),
+				"500": errorResponse("Журнал аудита недоступен"),
+				"503": errorResponse("БД недоступна, а в кэше заказа нет"),
+			},
+		},
+	}
+	doc.Paths["/orders/{order_uid}/receipt"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getOrderReceipt",
+			Summary:     "Печатная форма заказа: чек или упаковочный лист",
+			Description: "Цены со скидкой, доставка и итог в валюте заказа по правилам его locale. " +
+				"Упаковочный лист группирует товары по трек-номерам. Шаблоны переопределяются из RECEIPT_TEMPLATES_DIR.",
+			Tags: []string{"orders"},
+			Parameters: []openapi.Parameter{
+				openapi.PathParam("order_uid", "UID заказа"),
+				openapi.QueryParam(QueryVariant, "receipt (по умолчанию) или packing_slip",
+					&openapi.Schema{Type: "string", Enum: []any{receipt.KindReceipt, receipt.KindPackingSlip}}),
+				openapi.QueryParam(QueryFormat, "html (по умолчанию) или text",
+					&openapi.Schema{Type: "string", Enum: []any{receipt.FormatHTML, receipt.FormatText}}),
+				viewParams[0],
+				viewParams[1],
+			},
+			Responses: map[string]*openapi.Response{
+				"200": {
+					Description: "Печатная форма",
+					Headers:     map[string]openapi.Header{"Cache-Control": {Schema: &openapi.Schema{Type: "string"}}},
+					Content: map[string]openapi.MediaType{
+						"text/html":  {Schema: &openapi.Schema{Type: "string"}},
+						"text/plain": {Schema: &openapi.Schema{Type: "string"}},
+					},
+				},
+				"400": errorResponse("Некорректный variant, format или view"),
+				"403": errorResponse("view=unmasked без роли support или admin"),
+				"404": errorResponse("Заказ не найден"),
+				"500": errorResponse("Ошибка шаблона или журнала аудита"),
+				"503": errorResponse("БД недоступна, а в кэше заказа нет"),
+			},
+		},
+	}
+	doc.Paths["/orders:batchGet"] = &openapi.PathItem{
+		"post": {
+			OperationID: "batchGetOrders",
+			Summary:     "Несколько заказов за один запрос",
+			Description: "Кэшированные заказы берутся из памяти, остальные одним запросом из БД. " +
+				"Максимум UID в запросе задается ORDERS_BATCH_MAX_SIZE.",
+			Tags:        []string{"orders"},
+			Parameters:  append([]openapi.Parameter{fieldsParam}, viewParams...),
+			RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(doc.Register(BatchGetOrdersRequest{}))},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Найденные заказы в порядке запроса и ненайденные UID", Content: negotiated(doc.Register(BatchGetOrdersResponse{}))},
+				"400": errorResponse("Пустой или слишком большой список UID, неизвестное поле в fields или view"),
+				"403": errorResponse("view=unmasked без роли support или admin"),
+				"406": errorResponse("Формат из Accept не поддерживается"),
+				"500": errorResponse("Ошибка БД или журнала аудита"),
+				"503": errorResponse("БД недоступна, повторите запрос позже"),
+			},
+		},
+	}
+	doc.Paths["/orders"] = &openapi.PathItem{
+		"get": {
+			OperationID: "listOrders",
+			Summary:     "Заказы от новых к старым, постранично",
+			Tags:        []string{"orders"},
+			Parameters: append([]openapi.Parameter{
+				openapi.QueryParam("page_size", "Размер страницы, по умолчанию и максимум задает сервис", &openapi.Schema{Type: "integer"}),
+				openapi.QueryParam("page_token", "next_page_token из предыдущего ответа", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("entry", "Фильтр по entry", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("delivery_service", "Фильтр по службе доставки", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("customer_id", "Фильтр по покупателю", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("phone", "Точный телефон получателя, роли support и admin", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("email", "Точный email получателя, роли support и admin", &openapi.Schema{Type: "string"}),
+				fieldsParam,
+			}, viewParams...),
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Страница заказов и токен следующей", Content: negotiated(doc.Register(ListOrdersResponse{}))},
+				"400": errorResponse("Некорректный page_size, page_token, fields или view"),
+				"403": errorResponse("Поиск по phone/email или view=unmasked без роли support или admin"),
+				"406": errorResponse("Формат из Accept не поддерживается"),
+				"500": errorResponse("Ошибка БД или журнала аудита"),
+				"503": errorResponse("БД недоступна, повторите запрос позже"),
+			},
+		},
+	}
+	doc.Paths["/orders/stream"] = &openapi.PathItem{
+		"get": {
+			OperationID: "streamOrders",
+			Summary:     "Server-Sent Events с новыми заказами",
+			Tags:        []string{"orders"},
+			Parameters: []openapi.Parameter{
+				openapi.QueryParam("entry", "Фильтр по entry", &openapi.Schema{Type: "string"}),
+				openapi.QueryParam("delivery_service", "Фильтр по службе доставки", &openapi.Schema{Type: "string"}),
+				{Name: "Last-Event-ID", In: "header", Description: "ID последнего полученного события", Schema: &openapi.Schema{Type: "string"}},
+			},
+			Responses: map[string]*openapi.Response{
+				"200": {
+					Description: "Поток событий `order`, data - JSON заказа",
+					Content:     map[string]openapi.MediaType{"text/event-stream": {Schema: &openapi.Schema{Type: "string"}}},
+				},
+				"400": errorResponse("Некорректный Last-Event-ID"),
+			},
+		},
+	}
+
+	doc.Paths["/admin/webhooks"] = &openapi.PathItem{
+		"get": {
+			OperationID: "listWebhooks",
+			Summary:     "Список подписок",
+			Tags:        []string{"webhooks"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Подписки (без секретов)", Content: openapi.JSON(openapi.ArrayOf(subscription))},
+			},
+		},
+		"post": {
+			OperationID: "createWebhook",
+			Summary:     "Создать подписку",
+			Tags:        []string{"webhooks"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(doc.Register(CreateSubscriptionRequest{}))},
+			Responses: map[string]*openapi.Response{
+				"201": {Description: "Подписка, секрет возвращается только здесь", Content: openapi.JSON(subscription)},
+				"400": errorResponse("Некорректная подписка"),
+			},
+		},
+	}
+	doc.Paths["/admin/webhooks/{id}"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getWebhook",
+			Tags:        []string{"webhooks"},
+			Parameters:  []openapi.Parameter{idParam},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Подписка", Content: openapi.JSON(subscription)},
+				"404": errorResponse("Подписка не найдена"),
+			},
+		},
+		"patch": {
+			OperationID: "updateWebhook",
+			Tags:        []string{"webhooks"},
+			Parameters:  []openapi.Parameter{idParam},
+			RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(doc.Register(webhook.SubscriptionPatch{}))},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Обновленная подписка", Content: openapi.JSON(subscription)},
+				"400": errorResponse("Некорректные изменения"),
+				"404": errorResponse("Подписка не найдена"),
+			},
+		},
+		"delete": {
+			OperationID: "deleteWebhook",
+			Tags:        []string{"webhooks"},
+			Parameters:  []openapi.Parameter{idParam},
+			Responses: map[string]*openapi.Response{
+				"204": {Description: "Удалена"},
+				"404": errorResponse("Подписка не найдена"),
+			},
+		},
+	}
+	doc.Paths["/admin/webhooks/{id}/deliveries"] = &openapi.PathItem{
+		"get": {
+			OperationID: "listWebhookDeliveries",
+			Summary:     "Журнал доставок",
+			Tags:        []string{"webhooks"},
+			Parameters: []openapi.Parameter{idParam,
+				openapi.QueryParam("limit", "Количество записей (1..500)", &openapi.Schema{Type: "integer"})},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Доставки, новые первыми", Content: openapi.JSON(openapi.ArrayOf(delivery))},
+				"404": errorResponse("Подписка не найдена"),
+			},
+		},
+	}
+	doc.Paths["/admin/webhooks/{id}/test"] = &openapi.PathItem{
+		"post": {
+			OperationID: "testWebhook",
+			Summary:     "Отправить подписчику ping",
+			Tags:        []string{"webhooks"},
+			Parameters:  []openapi.Parameter{idParam},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Результат попытки", Content: openapi.JSON(doc.Register(TestResult{}))},
+				"404": errorResponse("Подписка не найдена"),
+			},
+		},
+	}
+
+	uidParam := openapi.PathParam("order_uid", "UID заказа")
+	position := doc.Register(kafka.Position{})
+	consumerStopped := errorResponse("Консьюмер не запущен")
+	doc.Paths["/admin/cache"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getCacheStats",
+			Summary:     "Статистика кэша",
+			Tags:        []string{"admin"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Размер, попадания и промахи", Content: openapi.JSON(doc.Register(cache.Stats{}))},
+			},
+		},
+	}
+	doc.Paths["/admin/cache/entries"] = &openapi.PathItem{
+		"get": {
+			OperationID: "listCacheEntries",
+			Summary:     "Записи кэша по UID",
+			Tags:        []string{"admin"},
+			Parameters: []openapi.Parameter{
+				openapi.QueryParam("limit", "Количество записей (1..1000)", &openapi.Schema{Type: "integer"})},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Записи без заказов и их общее число", Content: openapi.JSON(doc.Register(CacheEntriesResponse{}))},
+				"400": errorResponse("Некорректный limit"),
+			},
+		},
+		"delete": {
+			OperationID: "flushCache",
+			Summary:     "Очистить кэш",
+			Tags:        []string{"admin"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Число удаленных записей", Content: openapi.JSON(doc.Register(CacheFlushResponse{}))},
+			},
+		},
+	}
+	doc.Paths["/admin/cache/entries/{order_uid}"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getCacheEntry",
+			Summary:     "Запись кэша с заказом",
+			Description: "Персональные данные маскируются, без маскирования - GET /orders/{order_uid}?view=unmasked.",
+			Tags:        []string{"admin"},
+			Parameters:  []openapi.Parameter{uidParam},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Запись и заказ", Content: openapi.JSON(doc.Register(CacheEntryResponse{}))},
+				"404": errorResponse("Заказа нет в кэше"),
+			},
+		},
+		"delete": {
+			OperationID: "evictCacheEntry",
+			Summary:     "Удалить заказ из кэша",
+			Tags:        []string{"admin"},
+			Parameters:  []openapi.Parameter{uidParam},
+			Responses: map[string]*openapi.Response{
+				"204": {Description: "Удален, следующий запрос прочитает заказ из БД"},
+				"404": errorResponse("Заказа нет в кэше"),
+			},
+		},
+	}
+	doc.Paths["/admin/cache:reload"] = &openapi.PathItem{
+		"post": {
+			OperationID: "reloadCache",
+			Summary:     "Разогреть кэш из БД",
+			Tags:        []string{"admin"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Размер кэша после загрузки", Content: openapi.JSON(doc.Register(CacheReloadResponse{}))},
+				"500": errorResponse("Ошибка чтения из БД"),
+			},
+		},
+	}
+	doc.Paths["/admin/consumer"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getConsumerPosition",
+			Summary:     "Положение консьюмера и отставание",
+			Tags:        []string{"admin"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Offset, high water mark и lag", Content: openapi.JSON(position)},
+				"500": errorResponse("Брокер Kafka недоступен"),
+			},
+		},
+	}
+	for _, action := range []struct{ method, id, summary string }{
+		{"pause", "pauseConsumer", "Приостановить чтение топика заказов"},
+		{"resume", "resumeConsumer", "Продолжить чтение топика заказов"},
+	} {
+		doc.Paths["/admin/consumer:"+action.method] = &openapi.PathItem{
+			"post": {
+				OperationID: action.id,
+				Summary:     action.summary,
+				Tags:        []string{"admin"},
+				Responses: map[string]*openapi.Response{
+					"200": {Description: "Положение консьюмера после действия", Content: openapi.JSON(position)},
+					"409": consumerStopped,
+					"500": errorResponse("Брокер Kafka недоступен"),
+				},
+			},
+		}
+	}
+	doc.Paths["/admin/consumer:seek"] = &openapi.PathItem{
+		"post": {
+			OperationID: "seekConsumer",
+			Summary:     "Перемотать консьюмер для повторной обработки",
+			Description: "Задается offset или timestamp: во втором случае чтение начнется с первого сообщения не раньше этого времени.",
+			Tags:        []string{"admin"},
+			RequestBody: &openapi.RequestBody{Required: true, Content: openapi.JSON(doc.Register(admin.Seek{}))},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Offset, с которого продолжится чтение", Content: openapi.JSON(doc.Register(SeekResponse{}))},
+				"400": errorResponse("Не задан offset или timestamp, либо offset вне партиции"),
+				"409": consumerStopped,
+				"500": errorResponse("Брокер Kafka недоступен"),
+			},
+		},
+	}
+	doc.Paths["/admin/config:reload"] = &openapi.PathItem{
+		"post": {
+			OperationID: "reloadConfig",
+			Summary:     "Перечитать конфигурацию без перезапуска",
+			Description: "То же, что SIGHUP. Применяются только параметры, помеченные как изменяемые на лету; " +
+				"при любой ошибке продолжает действовать прежняя конфигурация.",
+			Tags: []string{"admin"},
+			Responses: map[string]*openapi.Response{
+				"200": {Description: "Версия конфигурации и примененные изменения", Content: openapi.JSON(doc.Register(config.ReloadResult{}))},
+				"422": errorResponse("Конфигурация не прошла проверку или меняет параметры, требующие перезапуска"),
+			},
+		},
+	}
+
+	doc.Paths["/openapi.json"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getOpenAPI",
+			Summary:     "Этот документ",
+			Tags:        []string{"meta"},
+			Responses:   map[string]*openapi.Response{"200": {Description: "OpenAPI 3.1"}},
+		},
+	}
+	doc.Paths["/docs"] = &openapi.PathItem{
+		"get": {
+			OperationID: "getDocs",
+			Summary:     "Документация API (HTML)",
+			Tags:        []string{"meta"},
+			Responses:   map[string]*openapi.Response{"200": {Description: "HTML-страница"}},
+		},
+	}
+
+	// Все, кроме самой документации, требует API-ключ или JWT,
+	doc.Components.SecuritySchemes = map[string]*openapi.SecurityScheme{
+		"apiKey": {Type: "apiKey", In: "header", Name: HeaderAPIKey},
+		"bearer": {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+	}
+	// и ограничено по частоте запросов
+	rateLimited := &openapi.Response{
+		Description: "Превышен лимит запросов клиента или сервис перегружен",
+		Headers: map[string]openapi.Header{
+			"Retry-After":         {Description: "Через сколько секунд повторить", Schema: &openapi.Schema{Type: "integer"}},
+			"RateLimit-Limit":     {Schema: &openapi.Schema{Type: "integer"}},
+			"RateLimit-Remaining": {Schema: &openapi.Schema{Type: "integer"}},
+			"RateLimit-Reset":     {Description: "Через сколько секунд лимит восстановится полностью", Schema: &openapi.Schema{Type: "integer"}},
+		},
+		Content: openapi.JSON(errResp),
+	}
+	for path, item := range doc.Paths {
+		if path == "/openapi.json" || path == "/docs" {
+			continue
+		}
+		for _, op := range *item {
+			op.Security = []map[string][]string{{"apiKey": {}}, {"bearer": {}}}
+			op.Responses["401"] = errorResponse("Нет или неверные учетные данные")
+			if _, ok := op.Responses["403"]; !ok {
+				op.Responses["403"] = errorResponse("Недостаточно прав")
+			}
+			op.Responses["429"] = rateLimited
+		}
+	}
+	return doc
+}
+
+// OpenAPIHandler - отдает документ и встроенную страницу документации.
+func OpenAPIHandler(doc *openapi.Document) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+func DocsHandler(c *gin.Context) {
+	c.Data(http.StatusOK, "text/html; charset=utf-8", docsPage)
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/models"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// OrderToProto - преобразует заказ в protobuf-сообщение gRPC API.
+func OrderToProto(o *models.Order) *orderv1.Order {
+	items := make([]*orderv1.Items, len(o.Items))
+	for i, it := range o.Items {
+		items[i] = &orderv1.Items{
+			ChrtId:      int64(it.ChrtID),
+			TrackNumber: it.TrackNumber,
+			Price:       int64(it.Price),
+			Rid:         it.Rid,
+			Name:        it.Name,
+			Sale:        int64(it.Sale),
+			Size:        it.Size,
+			TotalPrice:  int64(it.TotalPrice),
+			NmId:        int64(it.NmID),
+			Brand:       it.Brand,
+			Status:      int64(it.Status),
+		}
+	}
+
+	return &orderv1.Order{
+		OrderUid:    o.OrderUID,
+		TrackNumber: o.TrackNumber,
+		Entry:       o.Entry,
+		Delivery: &orderv1.Delivery{
+			Name:    o.Delivery.Name,
+			Phone:   o.Delivery.Phone,
+			Zip:     o.Delivery.Zip,
+			City:    o.Delivery.City,
+			Address: o.Delivery.Address,
+			Region:  o.Delivery.Region,
+			Email:   o.Delivery.Email,
+		},
+		Payment: &orderv1.Payment{
+			Transaction:  o.Payment.Transaction,
+			RequestId:    o.Payment.RequestID,
+			Currency:     o.Payment.Currency,
+			Provider:     o.Payment.Provider,
+			Amount:       int64(o.Payment.Amount),
+			PaymentDt:    int64(o.Payment.PaymentDt),
+			Bank:         o.Payment.Bank,
+			DeliveryCost: int64(o.Payment.DeliveryCost),
+			GoodsTotal:   int64(o.Payment.GoodsTotal),
+			CustomFee:    int64(o.Payment.CustomFee),
+		},
+		Items:             items,
+		Locale:            o.Locale,
+		InternalSignature: o.InternalSignature,
+		CustomerId:        o.CustomerID,
+		DeliveryService:   o.DeliveryService,
+		ShardKey:          o.ShardKey,
+		SmId:              int64(o.SmID),
+		DateCreated:       timestamppb.New(o.DateCreated),
+		OofShard:          o.OofShard,
+	}
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+
+	"google.golang.org/grpc/metadata"
+)
+
+// Персональные данные получателя по умолчанию маскируются для всех ролей.
+// Без маскирования их отдают только ролям support и admin по явному запросу
+// (?view=unmasked или метаданные x-pii-view: unmasked), и каждый такой доступ
+// попадает в журнал аудита.
+const (
+	QueryView          = "view"
+	ViewMasked         = "masked"
+	ViewUnmasked       = "unmasked"
+	HeaderAccessReason = "X-Access-Reason"
+
+	mdView         = "x-pii-view"
+	mdAccessReason = "x-access-reason"
+)
+
+// Auditor - журнал доступа к персональным данным без маскирования.
+//
+//go:generate mockery --name=Auditor --output=./mocks --case=underscore
+type Auditor interface {
+	RecordPIIAccess(ctx context.Context, a models.PIIAccess) error
+}
+
+// maskOrder - маскированное представление заказа (со своим ETag). Обычно оно уже
+// подготовлено кэшем, иначе заказ сериализуется заново.
+func maskOrder(encoded models.EncodedOrder) (models.EncodedOrder, error) {
+	if encoded.Masked != nil {
+		return *encoded.Masked, nil
+	}
+	m, err := models.EncodeOrder(encoded.Order)
+	if err != nil {
+		return models.EncodedOrder{}, err
+	}
+	return *m.Masked, nil
+}
+
+// recordUnmasked - пишет в журнал аудита, что p получил данные заказов uids без маскирования.
+// Ошибка означает, что отдавать данные нельзя: доступ без записи в журнале запрещен.
+func recordUnmasked(ctx context.Context, auditor Auditor, p auth.Principal, channel, operation, reason string, uids []string) error {
+	if len(uids) == 0 {
+		return nil
+	}
+	slog.Info("доступ к персональным данным без маскирования",
+		slog.String("subject", p.Subject),
+		slog.String("role", p.Role.String()),
+		slog.String("operation", operation),
+		slog.Any("order_uids", uids),
+		slog.String("reason", reason),
+		sl.Traced(ctx))
+
+	err := auditor.RecordPIIAccess(ctx, models.PIIAccess{
+		Subject:   p.Subject,
+		Role:      p.Role.String(),
+		Channel:   channel,
+		Operation: operation,
+		OrderUIDs: uids,
+		Reason:    reason,
+		At:        time.Now().UTC(),
+	})
+	if err != nil {
+		return err
+	}
+	// считаются только выданные данные: без записи в журнале запрос отклоняется
+	metric.PIIUnmaskedTotal.WithLabelValues(channel, p.Role.String()).Inc()
+	return nil
+}
+
+// grpcView - запрошен ли в метаданных gRPC-вызова вид без маскирования, и основание.
+func grpcView(ctx context.Context) (unmasked bool, reason string) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(key string) string {
+		if v := md.Get(key); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	return first(mdView) == ViewUnmasked, first(mdAccessReason)
+}
+
+func orderUIDs(orders []models.Order) []string {
+	uids := make([]string, len(orders))
+	for i := range orders {
+		uids[i] = orders[i].OrderUID
+	}
+	return uids
+}
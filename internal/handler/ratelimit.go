@@ -0,0 +1,153 @@
+package handler
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/i18n"
+	"wb-project/internal/metric"
+	"wb-project/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+)
+
+// TierAnonymous - уровень клиентов без учетных данных (аутентификация отключена)
+// и ролей, для которых уровень не задан. Такие клиенты различаются по IP.
+const TierAnonymous = "anonymous"
+
+// Limits - ограничители HTTP API. nil-значение (как и nil-поля) ничего не ограничивает.
+// Tiers и InFlight после запуска меняются только через Update.
+type Limits struct {
+	Rate     *ratelimit.Limiter
+	Tiers    map[string]ratelimit.Tier
+	InFlight *ratelimit.InFlight
+
+	mu sync.RWMutex
+}
+
+// Update - новые уровни и ограничение одновременных запросов при перезагрузке конфигурации.
+// Счетчики корзин клиентов сохраняются; запросы, уже занявшие место, освобождают его в прежнем ограничителе.
+func (l *Limits) Update(tiers map[string]ratelimit.Tier, inFlight *ratelimit.InFlight) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.Tiers = tiers
+	l.InFlight = inFlight
+}
+
+func (l *Limits) inFlight() *ratelimit.InFlight {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.InFlight
+}
+
+// authFailureTier - корзина неудачных попыток входа с IP вызывающего по уровню anonymous.
+func (l *Limits) authFailureTier(c *gin.Context) (string, ratelimit.Tier) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return "auth:" + c.ClientIP(), l.Tiers[TierAnonymous]
+}
+
+// clientTier - ключ корзины и уровень вызывающего: аутентифицированные клиенты
+// считаются по учетной записи, анонимные - по IP.
+func (l *Limits) clientTier(c *gin.Context) (string, ratelimit.Tier) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	p := principal(c)
+	if p.Method != "" && p.Method != auth.MethodAnonymous {
+		if tier, ok := l.Tiers[p.Role.String()]; ok {
+			return p.Method + ":" + p.Subject, tier
+		}
+	}
+	return "ip:" + c.ClientIP(), l.Tiers[TierAnonymous]
+}
+
+// RateLimit - token bucket на клиента. Ставится после Authenticate, чтобы знать роль.
+func RateLimit(l *Limits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || l.Rate == nil {
+			c.Next()
+			return
+		}
+		key, tier := l.clientTier(c)
+		d := l.Rate.Allow(key, tier)
+		if tier.Unlimited() {
+			c.Next()
+			return
+		}
+		c.Header("RateLimit-Limit", strconv.Itoa(d.Limit))
+		c.Header("RateLimit-Remaining", strconv.Itoa(d.Remaining))
+		c.Header("RateLimit-Reset", ceilSeconds(d.Reset))
+		if !d.Allowed {
+			metric.RateLimitDecisionsTotal.WithLabelValues("rate", tier.Name, "limited").Inc()
+			c.Header("Retry-After", ceilSeconds(d.RetryAfter))
+			respondError(c, http.StatusTooManyRequests, CodeRateLimited, i18n.MsgRateLimited)
+			return
+		}
+		metric.RateLimitDecisionsTotal.WithLabelValues("rate", tier.Name, "allowed").Inc()
+		c.Next()
+	}
+}
+
+// LimitAuthFailures - защита от перебора учетных данных. Ставится перед Authenticate:
+// каждый ответ 401 списывает токен из отдельной корзины IP по уровню anonymous, и пока она
+// пуста, запросы с этого IP отклоняются еще до проверки ключа или токена.
+func LimitAuthFailures(l *Limits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil || l.Rate == nil {
+			c.Next()
+			return
+		}
+		key, tier := l.authFailureTier(c)
+		if tier.Unlimited() {
+			c.Next()
+			return
+		}
+		if d := l.Rate.Peek(key, tier); !d.Allowed {
+			metric.RateLimitDecisionsTotal.WithLabelValues("auth", tier.Name, "limited").Inc()
+			c.Header("Retry-After", ceilSeconds(d.RetryAfter))
+			respondError(c, http.StatusTooManyRequests, CodeRateLimited, i18n.MsgRateLimited)
+			return
+		}
+		c.Next()
+		if c.Writer.Status() == http.StatusUnauthorized {
+			l.Rate.Allow(key, tier)
+		}
+	}
+}
+
+// LimitInFlight - ограничение одновременных запросов на маршрутах, которые могут дойти до БД.
+func LimitInFlight(l *Limits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if l == nil {
+			c.Next()
+			return
+		}
+		inFlight := l.inFlight()
+		if inFlight == nil {
+			c.Next()
+			return
+		}
+		release, ok := inFlight.Acquire(c.Request.Context())
+		if !ok {
+			metric.RateLimitDecisionsTotal.WithLabelValues("inflight", "", "limited").Inc()
+			c.Header("Retry-After", "1")
+			respondError(c, http.StatusTooManyRequests, CodeRateLimited, i18n.MsgOverloaded)
+			return
+		}
+		metric.RateLimitDecisionsTotal.WithLabelValues("inflight", "", "allowed").Inc()
+		metric.InFlightRequests.Inc()
+		defer func() {
+			metric.InFlightRequests.Dec()
+			release()
+		}()
+		c.Next()
+	}
+}
+
+// ceilSeconds - целые секунды с округлением вверх, как требуют Retry-After и RateLimit-Reset.
+func ceilSeconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,113 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/health"
+	"wb-project/internal/ratelimit"
+	"wb-project/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimit(t *testing.T) {
+	//1. Arrange(подготовка)
+	gin.SetMode(gin.TestMode)
+	encoded := loadEncodedOrder(t)
+	mockService := mocks.NewOrderReader(t)
+	mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+	router := NewRouter(
+		NewOrderHandler(mockService, nil, testOrdersConfig),
+		NewWebhookHandler(nil),
+		NewStreamHandler(stream.NewHub(1, 1), time.Second),
+		NewHealthHandler(health.NewChecker(time.Second)),
+		NewAdminHandler(nil),
+		auth.Disabled(),
+		&Limits{
+			Rate:  ratelimit.NewLimiter(time.Minute),
+			Tiers: map[string]ratelimit.Tier{TierAnonymous: {Name: TierAnonymous, Rate: 0.5, Burst: 2}},
+		},
+	)
+	get := func(ip string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+encoded.Order.OrderUID, nil)
+		req.RemoteAddr = ip + ":1234"
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	//2. Act(Действие)
+	first := get("10.0.0.1")
+	get("10.0.0.1")
+	limited := get("10.0.0.1")
+	otherClient := get("10.0.0.2")
+
+	//3. Assert
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.Equal(t, "2", first.Header().Get("RateLimit-Limit"))
+	assert.Equal(t, "1", first.Header().Get("RateLimit-Remaining"))
+
+	assert.Equal(t, http.StatusTooManyRequests, limited.Code)
+	assert.Equal(t, "2", limited.Header().Get("Retry-After"))
+	assert.Equal(t, "0", limited.Header().Get("RateLimit-Remaining"))
+	assert.JSONEq(t, `{"code":"rate_limited","error":"Слишком много запросов, повторите позже"}`, limited.Body.String())
+
+	assert.Equal(t, http.StatusOK, otherClient.Code)
+}
+
+// Неудачные попытки входа списываются из корзины IP, и после ее исчерпания запросы
+// с этого IP отклоняются до проверки учетных данных. X-Forwarded-For без доверенных
+// прокси не учитывается, поэтому подменой заголовка корзину не обойти.
+func TestLimitAuthFailures(t *testing.T) {
+	//1. Arrange(подготовка)
+	gin.SetMode(gin.TestMode)
+	encoded := loadEncodedOrder(t)
+	keys, err := auth.ParseAPIKeys("ui:viewer:" + auth.HashKey("viewer-key"))
+	require.NoError(t, err)
+	mockService := mocks.NewOrderReader(t)
+	mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+	router := NewRouter(
+		NewOrderHandler(mockService, nil, testOrdersConfig),
+		NewWebhookHandler(nil),
+		NewStreamHandler(stream.NewHub(1, 1), time.Second),
+		NewHealthHandler(health.NewChecker(time.Second)),
+		NewAdminHandler(nil),
+		auth.NewAuthenticator(true, keys, nil),
+		&Limits{
+			Rate: ratelimit.NewLimiter(time.Minute),
+			Tiers: map[string]ratelimit.Tier{
+				TierAnonymous: {Name: TierAnonymous, Rate: 0.1, Burst: 2},
+				"viewer":      {Name: "viewer"},
+			},
+		},
+	)
+	get := func(ip, forwarded, key string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+encoded.Order.OrderUID, nil)
+		req.RemoteAddr = ip + ":1234"
+		req.Header.Set("X-Forwarded-For", forwarded)
+		req.Header.Set(HeaderAPIKey, key)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	//2. Act(Действие)
+	first := get("10.0.0.1", "1.1.1.1", "wrong")
+	second := get("10.0.0.1", "2.2.2.2", "wrong")
+	blocked := get("10.0.0.1", "3.3.3.3", "viewer-key")
+	otherClient := get("10.0.0.2", "", "viewer-key")
+
+	//3. Assert
+	assert.Equal(t, http.StatusUnauthorized, first.Code)
+	assert.Equal(t, http.StatusUnauthorized, second.Code)
+	assert.Equal(t, http.StatusTooManyRequests, blocked.Code)
+	assert.Equal(t, "10", blocked.Header().Get("Retry-After"))
+	assert.Equal(t, http.StatusOK, otherClient.Code)
+}
@@ -0,0 +1,95 @@
+package handler
+
+import (
+	"bytes"
+	"errors"
+	"log/slog"
+	"net/http"
+	"wb-project/internal/i18n"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/models"
+	"wb-project/internal/receipt"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Параметры печатной формы.
+const (
+	QueryVariant = "variant"
+	QueryFormat  = "format"
+)
+
+// ReceiptHandler - GET /orders/:order_uid/receipt: чек (?variant=receipt) или упаковочный
+// лист по трек-номерам (?variant=packing_slip) в HTML или тексте (?format=html|text).
+// Персональные данные маскируются так же, как в JSON-ответе.
+func (s *OrderHandler) ReceiptHandler(c *gin.Context) {
+	ctx := c.Request.Context()
+	uid := c.Param("order_uid")
+	if uid == "" {
+		respondError(c, http.StatusBadRequest, CodeInvalidID, i18n.MsgInvalidOrderID)
+		return
+	}
+
+	//1. Разбираем вид формы
+	variant := c.DefaultQuery(QueryVariant, receipt.KindReceipt)
+	if variant != receipt.KindReceipt && variant != receipt.KindPackingSlip {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidVariant)
+		return
+	}
+	format := c.DefaultQuery(QueryFormat, receipt.FormatHTML)
+	contentType := "text/html; charset=utf-8"
+	switch format {
+	case receipt.FormatHTML:
+	case receipt.FormatText:
+		contentType = "text/plain; charset=utf-8"
+	default:
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidFormat)
+		return
+	}
+	unmasked, ok := parseView(c)
+	if !ok {
+		return
+	}
+
+	//2. Достаем заказ
+	encoded, err := s.service.GetOrderEncoded(ctx, uid)
+	if err != nil {
+		slog.Error("order не найден", slog.String("uid", uid), slog.Any("error", err), sl.Traced(ctx))
+		if errors.Is(err, models.ErrUnavailable) {
+			respondError(c, http.StatusServiceUnavailable, CodeUnavailable, i18n.MsgStorageUnavailable)
+			return
+		}
+		respondError(c, http.StatusNotFound, CodeOrderNotFound, i18n.MsgOrderNotFound)
+		return
+	}
+	if unmasked {
+		if !s.auditUnmasked(c, []string{uid}) {
+			return
+		}
+	} else if encoded, err = maskOrder(encoded); err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgReceiptFailed)
+		return
+	}
+
+	//3. Рендерим в буфер: ошибка шаблона не должна оборвать ответ на середине.
+	// Подписи - на языке из Accept-Language, без него - на языке заказа
+	lang := i18n.Match(c.GetHeader("Accept-Language"), encoded.Order.Locale)
+	var buf bytes.Buffer
+	if err := s.receipts.Render(&buf, variant, format, encoded.Order, lang); err != nil {
+		slog.Error("не удалось отрендерить печатную форму",
+			slog.String("uid", uid),
+			slog.String("variant", variant),
+			slog.Any("error", err),
+			sl.Traced(ctx))
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgReceiptFailed)
+		return
+	}
+	if unmasked {
+		c.Header("Cache-Control", "private, no-store")
+	} else {
+		c.Header("Cache-Control", s.cacheControl)
+	}
+	c.Header("Content-Language", lang.String())
+	c.Header("Vary", "Accept-Language")
+	c.Data(http.StatusOK, contentType, buf.Bytes())
+}
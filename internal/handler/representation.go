@@ -0,0 +1,443 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"mime"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/auth"
+	"wb-project/internal/i18n"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
+
+	"github.com/gin-gonic/gin"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Форматы ответа с заказами. JSON - формат по умолчанию, MessagePack повторяет
+// структуру JSON-документа, Protobuf использует сообщения gRPC API.
+const (
+	MediaJSON     = "application/json"
+	MediaMsgPack  = "application/msgpack"
+	MediaProtobuf = "application/x-protobuf"
+)
+
+// mediaAliases - принятые в обиходе синонимы поддерживаемых типов.
+var mediaAliases = map[string]string{
+	MediaJSON:                  MediaJSON,
+	MediaMsgPack:               MediaMsgPack,
+	"application/x-msgpack":    MediaMsgPack,
+	"application/vnd.msgpack":  MediaMsgPack,
+	MediaProtobuf:              MediaProtobuf,
+	"application/protobuf":     MediaProtobuf,
+	"application/vnd.protobuf": MediaProtobuf,
+}
+
+// Порядок предпочтения сервера при равных q.
+var supportedMedia = []string{MediaJSON, MediaMsgPack, MediaProtobuf}
+
+// negotiateMedia - выбирает формат ответа по Accept. ok=false означает,
+// что ни один из поддерживаемых форматов клиенту не подходит (406).
+func negotiateMedia(accept string) (string, bool) {
+	if strings.TrimSpace(accept) == "" {
+		return MediaJSON, true
+	}
+	q := map[string]float64{}
+	for _, part := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		weight := 1.0
+		if v, ok := params["q"]; ok {
+			if f, err := strconv.ParseFloat(v, 64); err == nil {
+				weight = f
+			}
+		}
+		if canonical, ok := mediaAliases[mediaType]; ok {
+			mediaType = canonical
+		}
+		// при повторах берем наибольший вес
+		if w, seen := q[mediaType]; !seen || weight > w {
+			q[mediaType] = weight
+		}
+	}
+
+	best, bestQ := "", 0.0
+	for _, media := range supportedMedia {
+		weight, ok := q[media]
+		if !ok {
+			weight, ok = q["application/*"]
+		}
+		if !ok {
+			weight, ok = q["*/*"]
+		}
+		if ok && weight > bestQ {
+			best, bestQ = media, weight
+		}
+	}
+	return best, best != ""
+}
+
+// fieldTree - выбранные поля: nil-поддерево означает поле целиком.
+type fieldTree map[string]fieldTree
+
+// orderFields - допустимые пути ?fields=, выводятся из json-тегов models.Order.
+var orderFields = sync.OnceValue(func() fieldTree {
+	return fieldsOf(reflect.TypeFor[models.Order]())
+})
+
+func fieldsOf(t reflect.Type) fieldTree {
+	for t.Kind() == reflect.Slice || t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t == reflect.TypeFor[time.Time]() {
+		return nil
+	}
+	tree := fieldTree{}
+	for i := range t.NumField() {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name == "" || name == "-" {
+			continue
+		}
+		tree[name] = fieldsOf(t.Field(i).Type)
+	}
+	return tree
+}
+
+// parseFields - разбирает ?fields=order_uid,payment.amount,items.name.
+// Пустая строка означает весь заказ (nil, nil).
+func parseFields(raw string) (fieldTree, error) {
+	if strings.TrimSpace(raw) == "" {
+		return nil, nil
+	}
+	selected := fieldTree{}
+	for _, path := range strings.Split(raw, ",") {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		known, node := orderFields(), selected
+		parts := strings.Split(path, ".")
+		for i, part := range parts {
+			sub, ok := known[part]
+			if !ok {
+				return nil, i18n.Errorf(i18n.MsgUnknownField, path)
+			}
+			if i == len(parts)-1 {
+				node[part] = nil // поле целиком
+				break
+			}
+			child, seen := node[part]
+			if seen && child == nil {
+				break // родитель уже выбран целиком
+			}
+			if !seen {
+				child = fieldTree{}
+				node[part] = child
+			}
+			known, node = sub, child
+		}
+	}
+	if len(selected) == 0 {
+		return nil, nil
+	}
+	return selected, nil
+}
+
+// key - каноническая запись выбора для вычисления ETag представления.
+func (f fieldTree) key() string {
+	names := make([]string, 0, len(f))
+	for name, sub := range f {
+		if sub != nil {
+			name += "(" + sub.key() + ")"
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ",")
+}
+
+// projectJSON - оставляет в JSON-дереве только выбранные поля.
+func projectJSON(v any, fields fieldTree) any {
+	if fields == nil {
+		return v
+	}
+	switch node := v.(type) {
+	case map[string]any:
+		for name := range node {
+			sub, ok := fields[name]
+			if !ok {
+				delete(node, name)
+				continue
+			}
+			node[name] = projectJSON(node[name], sub)
+		}
+	case []any:
+		for i := range node {
+			node[i] = projectJSON(node[i], fields)
+		}
+	}
+	return v
+}
+
+// projectProto - то же для protobuf-сообщения; имена полей proto совпадают с json-тегами.
+func projectProto(m protoreflect.Message, fields fieldTree) {
+	if fields == nil {
+		return
+	}
+	m.Range(func(fd protoreflect.FieldDescriptor, v protoreflect.Value) bool {
+		sub, ok := fields[string(fd.Name())]
+		switch {
+		case !ok:
+			m.Clear(fd)
+		case fd.Message() == nil || sub == nil:
+		case fd.IsList():
+			for i := range v.List().Len() {
+				projectProto(v.List().Get(i).Message(), sub)
+			}
+		default:
+			projectProto(v.Message(), sub)
+		}
+		return true
+	})
+}
+
+// orderDocument - заказ в виде JSON-дерева с учетом выбранных полей.
+func orderDocument(data []byte, fields fieldTree) (any, error) {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var doc any
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return projectJSON(doc, fields), nil
+}
+
+// plainNumbers - json.Number в int64/float64, чтобы MessagePack кодировал числа, а не строки.
+func plainNumbers(v any) any {
+	switch node := v.(type) {
+	case map[string]any:
+		for k, sub := range node {
+			node[k] = plainNumbers(sub)
+		}
+	case []any:
+		for i := range node {
+			node[i] = plainNumbers(node[i])
+		}
+	case json.Number:
+		if n, err := node.Int64(); err == nil {
+			return n
+		}
+		f, _ := node.Float64()
+		return f
+	}
+	return v
+}
+
+// representation - согласованный с клиентом вид ответа: формат, выбранные поля
+// и маскирование персональных данных.
+type representation struct {
+	media    string
+	fields   fieldTree
+	unmasked bool
+}
+
+// negotiateRepresentation - разбирает ?fields=, ?view= и Accept. При ошибке ответ уже отправлен.
+func negotiateRepresentation(c *gin.Context) (representation, bool) {
+	fields, err := parseFields(c.Query("fields"))
+	if err != nil {
+		respondInvalid(c, err)
+		return representation{}, false
+	}
+	unmasked, ok := parseView(c)
+	if !ok {
+		return representation{}, false
+	}
+	media, ok := negotiateMedia(c.GetHeader("Accept"))
+	if !ok {
+		respondError(c, http.StatusNotAcceptable, CodeNotAcceptable, i18n.MsgNotAcceptable, strings.Join(supportedMedia, ", "))
+		return representation{}, false
+	}
+	return representation{media: media, fields: fields, unmasked: unmasked}, true
+}
+
+// parseView - разбирает ?view= и проверяет права на вид без маскирования. При ошибке ответ уже отправлен.
+func parseView(c *gin.Context) (unmasked bool, ok bool) {
+	switch c.Query(QueryView) {
+	case "", ViewMasked:
+		return false, true
+	case ViewUnmasked:
+		if !principal(c).Allows(auth.RoleSupport) {
+			respondError(c, http.StatusForbidden, CodeForbidden, i18n.MsgUnmaskedForbidden)
+			return false, false
+		}
+		return true, true
+	default:
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidView)
+		return false, false
+	}
+}
+
+func (r representation) contentType() string {
+	if r.media == MediaJSON {
+		return MediaJSON + "; charset=utf-8"
+	}
+	return r.media
+}
+
+// encodeOrder - заказ в выбранном представлении. Полный JSON берется из кэша как есть.
+func (r representation) encodeOrder(encoded models.EncodedOrder) ([]byte, error) {
+	switch r.media {
+	case MediaProtobuf:
+		msg := OrderToProto(encoded.Order)
+		projectProto(msg.ProtoReflect(), r.fields)
+		return proto.MarshalOptions{Deterministic: true}.Marshal(msg)
+	case MediaMsgPack:
+		doc, err := orderDocument(encoded.JSON, r.fields)
+		if err != nil {
+			return nil, err
+		}
+		return msgpack.Marshal(plainNumbers(doc))
+	default:
+		if r.fields == nil {
+			return encoded.JSON, nil
+		}
+		doc, err := orderDocument(encoded.JSON, r.fields)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(doc)
+	}
+}
+
+// etag - ETag представления: у полного JSON это ETag из кэша, у остальных
+// представлений он зависит от формата и выбранных полей.
+func (r representation) etag(encoded models.EncodedOrder) string {
+	if r.media == MediaJSON && r.fields == nil {
+		return encoded.ETag
+	}
+	sum := sha256.Sum256([]byte(encoded.ETag + "|" + r.media + "|" + r.fields.key()))
+	return `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`
+}
+
+// respondOrder - отдает один заказ с поддержкой ETag и сжатия.
+func (r representation) respondOrder(c *gin.Context, encoded models.EncodedOrder) {
+	var err error
+	if !r.unmasked {
+		if encoded, err = maskOrder(encoded); err != nil {
+			respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgEncodeOrderFailed)
+			return
+		}
+	}
+	body, err := r.encodeOrder(encoded)
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgEncodeOrderFailed)
+		return
+	}
+	respondCacheable(c, r.contentType(), body, r.etag(encoded))
+}
+
+// respondBatch - ответ пакетного запроса в выбранном представлении.
+func (r representation) respondBatch(c *gin.Context, orders []models.Order, missing []string) {
+	if !r.unmasked {
+		orders = pii.Redact(orders)
+	}
+	var body []byte
+	var err error
+	switch r.media {
+	case MediaProtobuf:
+		resp := &orderv1.BatchGetOrdersResponse{MissingUids: missing}
+		for i := range orders {
+			msg := OrderToProto(&orders[i])
+			projectProto(msg.ProtoReflect(), r.fields)
+			resp.Orders = append(resp.Orders, msg)
+		}
+		body, err = proto.MarshalOptions{Deterministic: true}.Marshal(resp)
+	default:
+		body, err = r.encodeBatchDocument(orders, missing)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgEncodeOrdersFailed)
+		return
+	}
+	c.Header("Vary", "Accept")
+	c.Data(http.StatusOK, r.contentType(), body)
+}
+
+func (r representation) encodeBatchDocument(orders []models.Order, missing []string) ([]byte, error) {
+	missingDocs := make([]any, len(missing))
+	for i, uid := range missing {
+		missingDocs[i] = uid
+	}
+	return r.encodeOrders(orders, BatchGetOrdersResponse{Orders: orders, MissingUIDs: missing},
+		map[string]any{"missing_uids": missingDocs})
+}
+
+// respondPage - страница списка заказов в выбранном представлении.
+func (r representation) respondPage(c *gin.Context, orders []models.Order, next string) {
+	if !r.unmasked {
+		orders = pii.Redact(orders)
+	}
+	var body []byte
+	var err error
+	switch r.media {
+	case MediaProtobuf:
+		resp := &orderv1.ListOrdersResponse{NextPageToken: next}
+		for i := range orders {
+			msg := OrderToProto(&orders[i])
+			projectProto(msg.ProtoReflect(), r.fields)
+			resp.Orders = append(resp.Orders, msg)
+		}
+		body, err = proto.MarshalOptions{Deterministic: true}.Marshal(resp)
+	default:
+		extra := map[string]any{}
+		if next != "" {
+			extra["next_page_token"] = next
+		}
+		body, err = r.encodeOrders(orders, ListOrdersResponse{Orders: orders, NextPageToken: next}, extra)
+	}
+	if err != nil {
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgEncodeOrdersFailed)
+		return
+	}
+	c.Header("Vary", "Accept")
+	c.Data(http.StatusOK, r.contentType(), body)
+}
+
+// encodeOrders - документ со списком заказов: plain сериализуется как есть, если проекция
+// и другой формат не нужны, иначе заказы проецируются и дополняются полями extra.
+func (r representation) encodeOrders(orders []models.Order, plain any, extra map[string]any) ([]byte, error) {
+	if r.media == MediaJSON && r.fields == nil {
+		return json.Marshal(plain)
+	}
+	docs := make([]any, len(orders))
+	for i := range orders {
+		data, err := json.Marshal(&orders[i])
+		if err != nil {
+			return nil, err
+		}
+		if docs[i], err = orderDocument(data, r.fields); err != nil {
+			return nil, err
+		}
+	}
+	resp := map[string]any{"orders": docs}
+	for k, v := range extra {
+		resp[k] = v
+	}
+	if r.media == MediaMsgPack {
+		return msgpack.Marshal(plainNumbers(resp))
+	}
+	return json.Marshal(resp)
+}
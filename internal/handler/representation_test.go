@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	orderv1 "wb-project/api/order/v1"
+	"wb-project/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/vmihailenco/msgpack/v5"
+	"google.golang.org/protobuf/proto"
+)
+
+func TestNegotiateMedia(t *testing.T) {
+	cases := map[string]string{
+		"":                      MediaJSON,
+		"*/*":                   MediaJSON,
+		"application/*":         MediaJSON,
+		"application/json":      MediaJSON,
+		"application/x-msgpack": MediaMsgPack,
+		"application/protobuf":  MediaProtobuf,
+		"application/json;q=0.5, application/msgpack":       MediaMsgPack,
+		"text/html, */*;q=0.1":                              MediaJSON,
+		"application/*;q=0.2, application/x-protobuf;q=0.9": MediaProtobuf,
+	}
+	for accept, want := range cases {
+		got, ok := negotiateMedia(accept)
+		assert.True(t, ok, "Accept: %q", accept)
+		assert.Equal(t, want, got, "Accept: %q", accept)
+	}
+
+	for _, accept := range []string{"text/html", "application/xml", "application/json;q=0"} {
+		_, ok := negotiateMedia(accept)
+		assert.False(t, ok, "Accept: %q", accept)
+	}
+}
+
+func TestParseFields(t *testing.T) {
+	fields, err := parseFields("order_uid, payment.amount,items.name,items")
+	require.NoError(t, err)
+	assert.Equal(t, fieldTree{
+		"order_uid": nil,
+		"payment":   fieldTree{"amount": nil},
+		"items":     nil,
+	}, fields)
+
+	for _, raw := range []string{"unknown", "payment.unknown", "order_uid.x", "date_created.seconds"} {
+		_, err := parseFields(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func getOrder(t *testing.T, query string, accept string) (*httptest.ResponseRecorder, models.EncodedOrder) {
+	encoded := loadEncodedOrder(t)
+	router, mockService := newTestRouter(t)
+	mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil).Maybe()
+
+	req := httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+encoded.Order.OrderUID+query, nil)
+	req.Header.Set("Accept", accept)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, encoded
+}
+
+func TestGetOrderHandler_Projection(t *testing.T) {
+	w, encoded := getOrder(t, "?fields=order_uid,payment.amount,items.name", MediaJSON)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, map[string]any{
+		"order_uid": encoded.Order.OrderUID,
+		"payment":   map[string]any{"amount": float64(encoded.Order.Payment.Amount)},
+		"items":     []any{map[string]any{"name": encoded.Order.Items[0].Name}},
+	}, doc)
+	assert.NotEqual(t, encoded.ETag, w.Header().Get("ETag"), "у проекции свой ETag")
+}
+
+func TestGetOrderHandler_MessagePack(t *testing.T) {
+	w, encoded := getOrder(t, "?fields=order_uid,sm_id", "application/x-msgpack")
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, MediaMsgPack, w.Header().Get("Content-Type"))
+	var doc map[string]any
+	require.NoError(t, msgpack.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, encoded.Order.OrderUID, doc["order_uid"])
+	assert.EqualValues(t, encoded.Order.SmID, doc["sm_id"])
+	assert.Len(t, doc, 2)
+}
+
+func TestGetOrderHandler_Protobuf(t *testing.T) {
+	w, encoded := getOrder(t, "?fields=order_uid,items.price", MediaProtobuf)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var msg orderv1.Order
+	require.NoError(t, proto.Unmarshal(w.Body.Bytes(), &msg))
+	assert.Equal(t, encoded.Order.OrderUID, msg.GetOrderUid())
+	assert.Empty(t, msg.GetTrackNumber())
+	assert.Nil(t, msg.GetPayment())
+	require.Len(t, msg.GetItems(), len(encoded.Order.Items))
+	assert.Equal(t, int64(encoded.Order.Items[0].Price), msg.GetItems()[0].GetPrice())
+	assert.Empty(t, msg.GetItems()[0].GetName())
+}
+
+func TestGetOrderHandler_NotAcceptable(t *testing.T) {
+	w, _ := getOrder(t, "", "text/html")
+
+	assert.Equal(t, http.StatusNotAcceptable, w.Code)
+	assert.Contains(t, w.Body.String(), CodeNotAcceptable)
+}
+
+func TestGetOrderHandler_UnknownField(t *testing.T) {
+	w, _ := getOrder(t, "?fields=password", MediaJSON)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestBatchGet_Projection(t *testing.T) {
+	encoded := loadEncodedOrder(t)
+	router, mockService := newTestRouter(t)
+	mockService.On("GetOrders", mock.Anything, []string{encoded.Order.OrderUID, "unknown"}).
+		Return([]models.Order{*encoded.Order}, []string{"unknown"}, nil)
+
+	req := httptest.NewRequest(http.MethodPost, APIPrefix+"/orders:batchGet?fields=order_uid",
+		strings.NewReader(`{"order_uids":["`+encoded.Order.OrderUID+`","unknown"]}`))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"orders":[{"order_uid":"`+encoded.Order.OrderUID+`"}],"missing_uids":["unknown"]}`, w.Body.String())
+}
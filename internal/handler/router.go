@@ -1,29 +1,124 @@
 package handler
 
 import (
+	"net/http"
+	"wb-project/internal/auth"
+	"wb-project/internal/i18n"
+	"wb-project/internal/web"
+
 	"github.com/gin-gonic/gin"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.opentelemetry.io/contrib/instrumentation/github.com/gin-gonic/gin/otelgin"
 )
 
-func NewRouter(orderHandler *OrderHandler) *gin.Engine {
+func NewRouter(orderHandler *OrderHandler, webhookHandler *WebhookHandler, streamHandler *StreamHandler, healthHandler *HealthHandler, adminHandler *AdminHandler, authenticator *auth.Authenticator, limits *Limits) *gin.Engine {
 	router := gin.Default()
+	// X-Forwarded-For не учитывается, пока балансировщики не заданы через Server.WithTrustedProxies
+	_ = router.SetTrustedProxies(nil)
 	// "wb-order-service" — это имя, по которому ты будешь искать трейсы в Jaeger
 	router.Use(otelgin.Middleware("wb-order-service"))
 
-	router.Static("/static", "static")
-	router.StaticFile("/", "static/index.html")
+	// веб-консоль зашита в бинарник и не зависит от рабочего каталога
+	router.StaticFS("/static", http.FS(web.Assets()))
+	router.GET("/", consolePage(web.Index()))
 
 	router.Use(MetricsMiddleware())
 
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
 
-	api := router.Group("/order")
+	// пробы Kubernetes и балансировщика
+	router.GET("/healthz", healthHandler.Live)
+	router.GET("/readyz", healthHandler.Ready)
+	router.GET("/startupz", healthHandler.Startup)
+
+	// Устаревший маршрут, оставлен для совместимости: используйте /api/v1/orders/{order_uid}
+	api := router.Group("/order", deprecated(APIPrefix+"/orders/"))
 	{
-		api.GET("/:order_uid", orderHandler.GetOrderHandler)
+		api.GET("/:order_uid", LimitAuthFailures(limits), Authenticate(authenticator), RateLimit(limits), RequireRole(auth.RoleViewer), LimitInFlight(limits), orderHandler.GetOrderHandler)
+		api.GET("/:order_uid/receipt", LimitAuthFailures(limits), Authenticate(authenticator), RateLimit(limits), RequireRole(auth.RoleViewer), LimitInFlight(limits), orderHandler.ReceiptHandler)
 		api.GET("/", func(context *gin.Context) {
 			context.String(200, "Сервер работает")
 		})
 	}
+
+	v1 := router.Group(APIPrefix)
+	{
+		v1.GET("/openapi.json", OpenAPIHandler(NewOpenAPI()))
+		v1.GET("/docs", DocsHandler)
+
+		// Документация открыта, все остальное - после аутентификации и с ограничением частоты;
+		// неудачные попытки входа ограничиваются по IP еще до аутентификации
+		secured := v1.Group("", LimitAuthFailures(limits), Authenticate(authenticator), RateLimit(limits))
+
+		orders := secured.Group("/orders", RequireRole(auth.RoleViewer))
+		orders.GET("", LimitInFlight(limits), orderHandler.ListOrdersHandler)
+		orders.GET("/stream", streamHandler.Stream)
+		// промах кэша идет в БД: число одновременных таких запросов ограничено
+		orders.GET("/:order_uid", LimitInFlight(limits), orderHandler.GetOrderHandler)
+		orders.GET("/:order_uid/receipt", LimitInFlight(limits), orderHandler.ReceiptHandler)
+		secured.POST("/orders:method", RequireRole(auth.RoleViewer), LimitInFlight(limits), customMethods(map[string]gin.HandlerFunc{
+			":batchGet": orderHandler.BatchGetHandler,
+		}))
+
+		webhooks := secured.Group("/admin/webhooks", RequireRole(auth.RoleAdmin))
+		webhooks.POST("", webhookHandler.Create)
+		webhooks.GET("", webhookHandler.List)
+		webhooks.GET("/:id", webhookHandler.Get)
+		webhooks.PATCH("/:id", webhookHandler.Update)
+		webhooks.DELETE("/:id", webhookHandler.Delete)
+		webhooks.GET("/:id/deliveries", webhookHandler.Deliveries)
+		webhooks.POST("/:id/test", webhookHandler.Test)
+
+		// операции во время инцидентов без перезапуска пода
+		adminOps := secured.Group("/admin", RequireRole(auth.RoleAdmin))
+		adminOps.GET("/cache", adminHandler.CacheStats)
+		adminOps.GET("/cache/entries", adminHandler.CacheEntries)
+		adminOps.DELETE("/cache/entries", adminHandler.Flush)
+		adminOps.GET("/cache/entries/:order_uid", adminHandler.CacheEntry)
+		adminOps.DELETE("/cache/entries/:order_uid", adminHandler.Evict)
+		adminOps.POST("/cache:method", customMethods(map[string]gin.HandlerFunc{
+			":reload": adminHandler.Reload,
+		}))
+		adminOps.GET("/consumer", adminHandler.ConsumerPosition)
+		adminOps.POST("/consumer:method", customMethods(map[string]gin.HandlerFunc{
+			":pause":  adminHandler.PauseConsumer,
+			":resume": adminHandler.ResumeConsumer,
+			":seek":   adminHandler.SeekConsumer,
+		}))
+		adminOps.POST("/config:method", customMethods(map[string]gin.HandlerFunc{
+			":reload": adminHandler.ReloadConfig,
+		}))
+	}
 	return router
 }
+
+// consolePage - стартовая страница консоли, всегда перепроверяется браузером.
+func consolePage(page []byte) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", "no-cache")
+		c.Data(http.StatusOK, "text/html; charset=utf-8", page)
+	}
+}
+
+// customMethods - маршрутизация "пользовательских методов" вида /orders:batchGet.
+// Экранированный ":" gin поддерживает только при запуске через engine.Run,
+// поэтому суффикс ловим параметром и выбираем обработчик сами.
+func customMethods(handlers map[string]gin.HandlerFunc) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		h, ok := handlers[c.Param("method")]
+		if !ok {
+			respondError(c, http.StatusNotFound, CodeNotFound, i18n.MsgMethodNotFound)
+			return
+		}
+		h(c)
+	}
+}
+
+// deprecated - помечает ответы устаревших маршрутов заголовками Deprecation и Link.
+func deprecated(successor string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Deprecation", "true")
+		c.Header("Link", "<"+successor+">; rel=\"successor-version\"")
+		c.Next()
+	}
+}
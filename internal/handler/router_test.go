@@ -0,0 +1,315 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+	"wb-project/internal/auth"
+	"wb-project/internal/handler/mocks"
+	"wb-project/internal/health"
+	"wb-project/internal/models"
+	"wb-project/internal/openapi"
+	"wb-project/internal/stream"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(t *testing.T) (*gin.Engine, *mocks.OrderReader) {
+	gin.SetMode(gin.TestMode)
+	mockService := mocks.NewOrderReader(t)
+	router := NewRouter(
+		NewOrderHandler(mockService, nil, testOrdersConfig),
+		NewWebhookHandler(nil),
+		NewStreamHandler(stream.NewHub(1, 1), time.Second),
+		NewHealthHandler(health.NewChecker(time.Second)),
+		NewAdminHandler(nil),
+		auth.Disabled(),
+		nil,
+	)
+	return router, mockService
+}
+
+var ginParam = regexp.MustCompile(`:([a-z_]+)`)
+
+// Маршруты пользовательских методов и пути, которые они обслуживают.
+var customMethodPaths = map[string][]string{
+	"/orders:method":         {"/orders:batchGet"},
+	"/admin/cache:method":    {"/admin/cache:reload"},
+	"/admin/consumer:method": {"/admin/consumer:pause", "/admin/consumer:resume", "/admin/consumer:seek"},
+	"/admin/config:method":   {"/admin/config:reload"},
+}
+
+// Каждый маршрут /api/v1 должен быть описан в спецификации, и наоборот:
+// расхождение документа и роутера ломает сборку.
+func TestOpenAPI_MatchesRoutes(t *testing.T) {
+	router, _ := newTestRouter(t)
+	doc := NewOpenAPI()
+
+	registered := map[string]bool{}
+	for _, r := range router.Routes() {
+		if !strings.HasPrefix(r.Path, APIPrefix+"/") {
+			continue
+		}
+		paths, ok := customMethodPaths[strings.TrimPrefix(r.Path, APIPrefix)]
+		if !ok {
+			paths = []string{ginParam.ReplaceAllString(strings.TrimPrefix(r.Path, APIPrefix), "{$1}")}
+		}
+		for _, path := range paths {
+			key := strings.ToLower(r.Method) + " " + path
+			registered[key] = true
+
+			item, ok := doc.Paths[path]
+			if assert.True(t, ok, "путь %s не описан в OpenAPI", path) {
+				_, ok = (*item)[strings.ToLower(r.Method)]
+				assert.True(t, ok, "операция %s не описана в OpenAPI", key)
+			}
+		}
+	}
+
+	for path, item := range doc.Paths {
+		for method := range *item {
+			assert.True(t, registered[method+" "+path], "операция %s %s описана, но не зарегистрирована", method, path)
+		}
+	}
+}
+
+func responseSchema(t *testing.T, doc *openapi.Document, path, method, status string) *openapi.Schema {
+	op := (*doc.Paths[path])[method]
+	require.NotNil(t, op)
+	resp, ok := op.Responses[status]
+	require.True(t, ok, "ответ %s не описан для %s %s", status, method, path)
+	return resp.Content["application/json"].Schema
+}
+
+func TestOpenAPI_GetOrderResponses(t *testing.T) {
+	doc := NewOpenAPI()
+
+	t.Run("200 соответствует схеме Order", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		data, err := os.ReadFile("testdata/order.json")
+		require.NoError(t, err)
+		var order models.Order
+		require.NoError(t, json.Unmarshal(data, &order))
+		encoded, err := models.EncodeOrder(&order)
+		require.NoError(t, err)
+		mockService.On("GetOrderEncoded", mock.Anything, order.OrderUID).Return(encoded, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+order.OrderUID, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/orders/{order_uid}", "get", "200"), w.Body.Bytes()))
+	})
+
+	t.Run("404 соответствует схеме ErrorResponse", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		mockService.On("GetOrderEncoded", mock.Anything, "unknown").Return(models.EncodedOrder{}, errors.New("not found"))
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/unknown", nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/orders/{order_uid}", "get", "404"), w.Body.Bytes()))
+	})
+}
+
+func TestOrderHandler_BatchGet(t *testing.T) {
+	doc := NewOpenAPI()
+	batchGet := func(router http.Handler, body string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, APIPrefix+"/orders:batchGet", strings.NewReader(body)))
+		return w
+	}
+
+	t.Run("Найденные и отсутствующие заказы", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, mockService := newTestRouter(t)
+		data, err := os.ReadFile("testdata/order.json")
+		require.NoError(t, err)
+		var order models.Order
+		require.NoError(t, json.Unmarshal(data, &order))
+		mockService.On("GetOrders", mock.Anything, []string{order.OrderUID, "unknown"}).
+			Return([]models.Order{order}, []string{"unknown"}, nil)
+
+		//2. Act(Действие)
+		w := batchGet(router, `{"order_uids":["`+order.OrderUID+`","unknown"]}`)
+
+		//3. Assert
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/orders:batchGet", "post", "200"), w.Body.Bytes()))
+		var resp BatchGetOrdersResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Len(t, resp.Orders, 1)
+		assert.Equal(t, []string{"unknown"}, resp.MissingUIDs)
+	})
+
+	t.Run("Превышен размер пакета", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+		uids := make([]string, 101)
+		for i := range uids {
+			uids[i] = strconv.Itoa(i)
+		}
+		body, _ := json.Marshal(BatchGetOrdersRequest{OrderUIDs: uids})
+
+		w := batchGet(router, string(body))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/orders:batchGet", "post", "400"), w.Body.Bytes()))
+		mockService.AssertNotCalled(t, "GetOrders")
+	})
+
+	t.Run("Пустой список", func(t *testing.T) {
+		router, _ := newTestRouter(t)
+
+		assert.Equal(t, http.StatusBadRequest, batchGet(router, `{"order_uids":[]}`).Code)
+		assert.Equal(t, http.StatusBadRequest, batchGet(router, `{"order_uids":[""]}`).Code)
+	})
+
+	t.Run("Неизвестный метод", func(t *testing.T) {
+		router, _ := newTestRouter(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, APIPrefix+"/orders:delete", strings.NewReader(`{}`)))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+}
+
+// Схема запроса выводится из validate-тегов: невалидные тела должны отвергаться.
+func TestOpenAPI_CreateWebhookRequest(t *testing.T) {
+	doc := NewOpenAPI()
+	schema := (*doc.Paths["/admin/webhooks"])["post"].RequestBody.Content["application/json"].Schema
+
+	assert.NoError(t, doc.Validate(schema, []byte(`{"url":"https://partner.example/hook","events":["order.created"]}`)))
+	assert.Error(t, doc.Validate(schema, []byte(`{"url":"https://partner.example/hook","events":["order.deleted"]}`)))
+	assert.Error(t, doc.Validate(schema, []byte(`{"events":["order.created"]}`)))
+}
+
+func TestOpenAPI_Served(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/openapi.json", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var doc map[string]any
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &doc))
+	assert.Equal(t, "3.1.0", doc["openapi"])
+}
+
+func TestOrderHandler_ListOrders(t *testing.T) {
+	doc := NewOpenAPI()
+
+	t.Run("Фильтры, курсор и маскирование", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, mockService := newTestRouter(t)
+		encoded := loadEncodedOrder(t)
+		next := models.OrderCursor{DateCreated: encoded.Order.DateCreated, OrderUID: encoded.Order.OrderUID}
+		mockService.On("ListOrders", mock.Anything, mock.MatchedBy(func(q models.OrderListQuery) bool {
+			return q.Limit == 20 && q.Entry == "WBIL" && q.After == nil
+		})).Return(models.OrderPage{Orders: []models.Order{*encoded.Order}, Next: &next}, nil)
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders?page_size=20&entry=WBIL", nil))
+
+		//3. Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.NoError(t, doc.Validate(responseSchema(t, doc, "/orders", "get", "200"), w.Body.Bytes()))
+		var resp ListOrdersResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, next.Token(), resp.NextPageToken)
+		require.Len(t, resp.Orders, 1)
+		assert.NotEqual(t, encoded.Order.Delivery.Phone, resp.Orders[0].Delivery.Phone)
+	})
+
+	t.Run("Некорректный page_token", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders?page_token=%21%21", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ListOrders", mock.Anything, mock.Anything)
+	})
+}
+
+func TestOrderHandler_Receipt(t *testing.T) {
+	t.Run("Чек в HTML с маскированием", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, mockService := newTestRouter(t)
+		encoded := loadEncodedOrder(t)
+		mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/"+encoded.Order.OrderUID+"/receipt", nil))
+
+		//3. Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/html; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "private, max-age=60", w.Header().Get("Cache-Control"))
+		body := w.Body.String()
+		assert.Contains(t, body, "₽ 2\u00a0528,00") // locale ru
+		assert.Equal(t, "ru", w.Header().Get("Content-Language"))
+		assert.NotContains(t, body, encoded.Order.Delivery.Name)
+	})
+
+	t.Run("Упаковочный лист текстом по старому маршруту", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		router, mockService := newTestRouter(t)
+		encoded := loadEncodedOrder(t)
+		mockService.On("GetOrderEncoded", mock.Anything, encoded.Order.OrderUID).Return(encoded, nil)
+		req := httptest.NewRequest(http.MethodGet, "/order/"+encoded.Order.OrderUID+"/receipt?variant=packing_slip&format=text", nil)
+		req.Header.Set("Accept-Language", "en")
+
+		//2. Act(Действие)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		//3. Assert
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "text/plain; charset=utf-8", w.Header().Get("Content-Type"))
+		assert.Equal(t, "true", w.Header().Get("Deprecation"))
+		assert.Contains(t, w.Body.String(), "== "+encoded.Order.Items[0].TrackNumber+" ==")
+		assert.Contains(t, w.Body.String(), "Packing slip")
+	})
+
+	t.Run("Неизвестный вид формы", func(t *testing.T) {
+		router, mockService := newTestRouter(t)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, APIPrefix+"/orders/uid/receipt?variant=invoice", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "GetOrderEncoded", mock.Anything, mock.Anything)
+	})
+}
+
+// Консоль зашита в бинарник: тест запускается из internal/handler, где каталога static нет.
+func TestConsole_Embedded(t *testing.T) {
+	router, _ := newTestRouter(t)
+
+	for path, contentType := range map[string]string{
+		"/":                   "text/html",
+		"/static/console.js":  "javascript",
+		"/static/console.css": "text/css",
+	} {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code, path)
+		assert.Contains(t, w.Header().Get("Content-Type"), contentType, path)
+		assert.NotEmpty(t, w.Body.Bytes(), path)
+	}
+}
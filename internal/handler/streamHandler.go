@@ -0,0 +1,84 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+	"wb-project/internal/i18n"
+	"wb-project/internal/stream"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StreamHandler struct {
+	hub       *stream.Hub
+	heartbeat time.Duration
+}
+
+func NewStreamHandler(hub *stream.Hub, heartbeat time.Duration) *StreamHandler {
+	return &StreamHandler{hub: hub, heartbeat: heartbeat}
+}
+
+// Stream - GET /orders/stream: Server-Sent Events с новыми заказами.
+// Фильтры: ?entry=, ?delivery_service=. Докачка пропущенного - по заголовку
+// Last-Event-ID (или ?last_event_id= для клиентов без поддержки заголовка).
+func (h *StreamHandler) Stream(c *gin.Context) {
+	lastID := c.GetHeader("Last-Event-ID")
+	if lastID == "" {
+		lastID = c.Query("last_event_id")
+	}
+	var lastEventID uint64
+	if lastID != "" {
+		id, err := strconv.ParseUint(lastID, 10, 64)
+		if err != nil {
+			respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidLastEventID)
+			return
+		}
+		lastEventID = id
+	}
+
+	sub, backlog := h.hub.Subscribe(stream.Filter{
+		Entry:           c.Query("entry"),
+		DeliveryService: c.Query("delivery_service"),
+	}, lastEventID)
+	defer h.hub.Unsubscribe(sub)
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // отключаем буферизацию в nginx
+	w.WriteHeader(http.StatusOK)
+
+	for _, e := range backlog {
+		writeEvent(w, e)
+	}
+	w.Flush()
+
+	heartbeat := time.NewTicker(h.heartbeat)
+	defer heartbeat.Stop()
+
+	ctx := c.Request.Context()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-sub.C:
+			if !ok {
+				// хаб отключил медленного клиента
+				return
+			}
+			writeEvent(w, e)
+			w.Flush()
+		case <-heartbeat.C:
+			_, _ = fmt.Fprint(w, ": ping\n\n")
+			w.Flush()
+		}
+	}
+}
+
+// writeEvent - событие с заказом; хаб уже замаскировал персональные данные.
+func writeEvent(w gin.ResponseWriter, e stream.Event) {
+	_, _ = fmt.Fprintf(w, "id: %d\nevent: order\ndata: %s\n\n", e.ID, e.Data)
+}
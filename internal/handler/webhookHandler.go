@@ -0,0 +1,179 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"wb-project/internal/i18n"
+	"wb-project/internal/logger/sl"
+	"wb-project/internal/models"
+	"wb-project/internal/webhook"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookManager - управление подписками на вебхуки.
+type WebhookManager interface {
+	Create(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	Get(ctx context.Context, id int64) (models.WebhookSubscription, error)
+	List(ctx context.Context) ([]models.WebhookSubscription, error)
+	Update(ctx context.Context, id int64, patch webhook.SubscriptionPatch) (models.WebhookSubscription, error)
+	Delete(ctx context.Context, id int64) error
+	Deliveries(ctx context.Context, id int64, limit int) ([]models.WebhookDelivery, error)
+	Test(ctx context.Context, id int64) (models.WebhookAttempt, error)
+}
+
+type WebhookHandler struct {
+	manager WebhookManager
+}
+
+func NewWebhookHandler(m WebhookManager) *WebhookHandler {
+	return &WebhookHandler{manager: m}
+}
+
+// CreateSubscriptionRequest - тело запроса на создание подписки.
+type CreateSubscriptionRequest struct {
+	URL    string   `json:"url" validate:"required,url"`
+	Events []string `json:"events" validate:"required,gt=0,dive,oneof=order.created order.updated"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+func (h *WebhookHandler) Create(c *gin.Context) {
+	var req CreateSubscriptionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidBody)
+		return
+	}
+	sub, err := h.manager.Create(c.Request.Context(), models.WebhookSubscription{
+		URL:    req.URL,
+		Events: req.Events,
+		Secret: req.Secret,
+	})
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+func (h *WebhookHandler) List(c *gin.Context) {
+	subs, err := h.manager.List(c.Request.Context())
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, subs)
+}
+
+func (h *WebhookHandler) Get(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+	sub, err := h.manager.Get(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) Update(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+	var patch webhook.SubscriptionPatch
+	if err := c.ShouldBindJSON(&patch); err != nil {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidBody)
+		return
+	}
+	sub, err := h.manager.Update(c.Request.Context(), id, patch)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WebhookHandler) Delete(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+	if err := h.manager.Delete(c.Request.Context(), id); err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// Deliveries - журнал доставок подписки, ?limit= (по умолчанию 50, не больше 500).
+func (h *WebhookHandler) Deliveries(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+	limit, err := strconv.Atoi(c.DefaultQuery("limit", "50"))
+	if err != nil || limit <= 0 || limit > 500 {
+		respondError(c, http.StatusBadRequest, CodeInvalidRequest, i18n.MsgInvalidLimit, 500)
+		return
+	}
+	deliveries, err := h.manager.Deliveries(c.Request.Context(), id, limit)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, deliveries)
+}
+
+// Test - отправляет подписчику ping. Ответ 200 содержит результат попытки,
+// даже если подписчик ответил ошибкой.
+func (h *WebhookHandler) Test(c *gin.Context) {
+	id, ok := subscriptionID(c)
+	if !ok {
+		return
+	}
+	attempt, err := h.manager.Test(c.Request.Context(), id)
+	if err != nil {
+		h.fail(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TestResult{
+		Success:    attempt.Error == "",
+		StatusCode: attempt.StatusCode,
+		Error:      attempt.Error,
+		DurationMs: attempt.Duration.Milliseconds(),
+	})
+}
+
+// TestResult - результат тестовой отправки ping подписчику.
+type TestResult struct {
+	Success    bool   `json:"success"`
+	StatusCode int    `json:"status_code,omitempty"`
+	Error      string `json:"error,omitempty"`
+	DurationMs int64  `json:"duration_ms"`
+}
+
+func subscriptionID(c *gin.Context) (int64, bool) {
+	id, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || id <= 0 {
+		respondError(c, http.StatusBadRequest, CodeInvalidID, i18n.MsgInvalidSubscriptionID)
+		return 0, false
+	}
+	return id, true
+}
+
+func (h *WebhookHandler) fail(c *gin.Context, err error) {
+	switch {
+	case errors.Is(err, models.ErrNotFound):
+		respondError(c, http.StatusNotFound, CodeSubscriptionNotFound, i18n.MsgSubscriptionNotFound)
+	case errors.Is(err, webhook.ErrInvalid):
+		respondInvalid(c, err)
+	default:
+		slog.Error("ошибка управления вебхуками", slog.Any("error", err), sl.Traced(c.Request.Context()))
+		respondError(c, http.StatusInternalServerError, CodeInternal, i18n.MsgInternal)
+	}
+}
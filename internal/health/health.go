@@ -0,0 +1,202 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+	"wb-project/internal/metric"
+)
+
+// Status - состояние компонента или пробы целиком.
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+	// StatusDegraded - только для readiness целиком: запуск в деградированном режиме,
+	// БД доступна и кэш разогрет, а остальные зависимости еще подключаются.
+	StatusDegraded Status = "degraded"
+)
+
+// Компоненты, которые проверяет сам Checker.
+const (
+	ComponentStartup  = "startup"
+	ComponentShutdown = "shutdown"
+)
+
+var (
+	ErrNotStarted = errors.New("запуск еще не завершен")
+	ErrDraining   = errors.New("сервис останавливается, новые запросы не принимаются")
+)
+
+// Check - проверка одного компонента, должна уважать дедлайн ctx.
+type Check func(ctx context.Context) error
+
+// Component - результат проверки одного компонента.
+type Component struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report - ответ пробы: общий статус и разбивка по компонентам.
+type Report struct {
+	Status     Status               `json:"status"`
+	Components map[string]Component `json:"components,omitempty"`
+}
+
+// Up - проба проходит, в том числе в деградированном режиме.
+func (r Report) Up() bool {
+	return r.Status != StatusDown
+}
+
+// Checker - liveness, readiness и startup пробы сервиса.
+// Проверки регистрируются при сборке приложения, до начала обслуживания запросов.
+type Checker struct {
+	timeout  time.Duration
+	names    []string
+	checks   map[string]Check
+	started  atomic.Bool
+	warmed   atomic.Bool
+	draining atomic.Bool
+	degraded bool     // после разогрева и до завершения запуска readiness отвечает degraded, а не отказом
+	required []string // компоненты, без которых degraded невозможен
+}
+
+func NewChecker(timeout time.Duration) *Checker {
+	return &Checker{timeout: timeout, checks: map[string]Check{}}
+}
+
+// Register - добавляет компонент в readiness.
+func (h *Checker) Register(name string, check Check) *Checker {
+	if _, ok := h.checks[name]; !ok {
+		h.names = append(h.names, name)
+	}
+	h.checks[name] = check
+	return h
+}
+
+// WithDegradedStartup - когда кэш разогрет (MarkWarmed), а запуск еще не завершен,
+// readiness проходит со статусом degraded: инстанс получает трафик и отдает заказы,
+// пока подключаются остальные зависимости. Компоненты required при этом должны
+// отвечать. До разогрева и после запуска упавшая зависимость означает отказ.
+func (h *Checker) WithDegradedStartup(required ...string) *Checker {
+	h.degraded = true
+	h.required = required
+	return h
+}
+
+// MarkWarmed - БД доступна и кэш разогрет, деградированный режим может принимать трафик.
+func (h *Checker) MarkWarmed() {
+	h.warmed.Store(true)
+}
+
+// MarkStarted - запуск завершен (кэш разогрет), startup проба начинает проходить.
+func (h *Checker) MarkStarted() {
+	h.started.Store(true)
+}
+
+// Drain - readiness начинает отвечать отказом, чтобы балансировщик вывел инстанс
+// до остановки серверов. Обратно не переключается.
+func (h *Checker) Drain() {
+	h.draining.Store(true)
+}
+
+// Live - процесс жив и способен отвечать; зависимости не проверяются,
+// иначе падение БД привело бы к перезапуску всех инстансов.
+func (h *Checker) Live(context.Context) Report {
+	return Report{Status: StatusUp}
+}
+
+// Startup - завершен ли запуск.
+func (h *Checker) Startup(context.Context) Report {
+	report := Report{Status: StatusUp, Components: map[string]Component{}}
+	report.add(ComponentStartup, h.startupComponent())
+	return report
+}
+
+// Ready - готовность принимать трафик: запуск завершен, сервис не останавливается
+// и все зарегистрированные зависимости отвечают. Проверки идут параллельно.
+func (h *Checker) Ready(ctx context.Context) Report {
+	report := Report{Status: StatusUp, Components: make(map[string]Component, len(h.checks)+2)}
+	report.add(ComponentStartup, h.startupComponent())
+	shutdown := Component{Status: StatusUp}
+	if h.draining.Load() {
+		shutdown = Component{Status: StatusDown, Error: ErrDraining.Error()}
+	}
+	report.add(ComponentShutdown, shutdown)
+
+	results := make([]Component, len(h.names))
+	var wg sync.WaitGroup
+	for i, name := range h.names {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = h.run(ctx, h.checks[name])
+		}()
+	}
+	wg.Wait()
+
+	for i, name := range h.names {
+		report.add(name, results[i])
+		metric.HealthComponentUp.WithLabelValues(name).Set(boolToFloat(results[i].Status == StatusUp))
+		metric.HealthCheckDuration.WithLabelValues(name).Observe(results[i].LatencyMS / 1000)
+	}
+	if report.Status == StatusDown && h.servesDegraded(report) {
+		report.Status = StatusDegraded
+	}
+	return report
+}
+
+// servesDegraded - можно ли пропускать трафик, пока запуск не завершен.
+func (h *Checker) servesDegraded(report Report) bool {
+	if !h.degraded || !h.warmed.Load() || h.started.Load() || h.draining.Load() {
+		return false
+	}
+	for _, name := range h.required {
+		if report.Components[name].Status != StatusUp {
+			return false
+		}
+	}
+	return true
+}
+
+// run - выполняет проверку с таймаутом и замеряет время.
+func (h *Checker) run(ctx context.Context, check Check) Component {
+	if h.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, h.timeout)
+		defer cancel()
+	}
+	start := time.Now()
+	err := check(ctx)
+	latency := float64(time.Since(start).Microseconds()) / 1000
+	if err != nil {
+		return Component{Status: StatusDown, LatencyMS: latency, Error: err.Error()}
+	}
+	return Component{Status: StatusUp, LatencyMS: latency}
+}
+
+func (h *Checker) startupComponent() Component {
+	if !h.started.Load() {
+		return Component{Status: StatusDown, Error: ErrNotStarted.Error()}
+	}
+	return Component{Status: StatusUp}
+}
+
+// add - один упавший компонент роняет всю пробу.
+func (r *Report) add(name string, c Component) {
+	r.Components[name] = c
+	if c.Status != StatusUp {
+		r.Status = StatusDown
+	}
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChecker_Ready(t *testing.T) {
+	//1. Arrange(подготовка)
+	checker := NewChecker(50 * time.Millisecond)
+	checker.Register("database", func(context.Context) error { return nil }).
+		Register("kafka", func(ctx context.Context) error {
+			<-ctx.Done() // брокер не отвечает
+			return ctx.Err()
+		})
+
+	//2. Act(Действие)
+	notStarted := checker.Ready(context.Background())
+	checker.MarkStarted()
+	ready := checker.Ready(context.Background())
+
+	//3. Assert
+	assert.False(t, notStarted.Up())
+	assert.Equal(t, StatusDown, notStarted.Components[ComponentStartup].Status)
+
+	assert.False(t, ready.Up())
+	assert.Equal(t, StatusUp, ready.Components[ComponentStartup].Status)
+	assert.Equal(t, StatusUp, ready.Components["database"].Status)
+	kafka := ready.Components["kafka"]
+	assert.Equal(t, StatusDown, kafka.Status)
+	assert.Contains(t, kafka.Error, context.DeadlineExceeded.Error())
+	assert.GreaterOrEqual(t, kafka.LatencyMS, float64(50))
+}
+
+func TestChecker_DegradedStartup(t *testing.T) {
+	//1. Arrange(подготовка)
+	var dbErr error
+	checker := NewChecker(time.Second).
+		Register("database", func(context.Context) error { return dbErr }).
+		Register("kafka", func(context.Context) error {
+			return errors.New("нет подключения к брокерам Kafka")
+		}).
+		WithDegradedStartup("database")
+
+	//2. Act(Действие)
+	cold := checker.Ready(context.Background())
+	checker.MarkWarmed()
+	warmed := checker.Ready(context.Background())
+	dbErr = errors.New("connection refused")
+	dbDown := checker.Ready(context.Background())
+	dbErr = nil
+	checker.MarkStarted()
+	started := checker.Ready(context.Background())
+
+	//3. Assert
+	assert.False(t, cold.Up(), "пока кэш не разогрет, трафик не принимается")
+	assert.True(t, warmed.Up())
+	assert.Equal(t, StatusDegraded, warmed.Status)
+	assert.Equal(t, StatusDown, warmed.Components["kafka"].Status)
+	assert.False(t, dbDown.Up(), "без БД degraded невозможен")
+	assert.False(t, started.Up(), "после запуска упавшая зависимость снова означает отказ")
+}
+
+func TestChecker_Drain(t *testing.T) {
+	//1. Arrange(подготовка)
+	checker := NewChecker(time.Second).Register("database", func(context.Context) error { return nil })
+	checker.MarkStarted()
+	require.True(t, checker.Ready(context.Background()).Up())
+
+	//2. Act(Действие)
+	checker.Drain()
+	report := checker.Ready(context.Background())
+
+	//3. Assert
+	assert.False(t, report.Up())
+	assert.Equal(t, Component{Status: StatusDown, Error: ErrDraining.Error()}, report.Components[ComponentShutdown])
+	assert.True(t, checker.Live(context.Background()).Up(), "liveness не зависит от остановки")
+	assert.True(t, checker.Startup(context.Background()).Up())
+}
+
+func TestChecker_FailedComponent(t *testing.T) {
+	//1. Arrange(подготовка)
+	checker := NewChecker(time.Second).Register("consumer", func(context.Context) error {
+		return errors.New("консьюмер не читает топик")
+	})
+	checker.MarkStarted()
+
+	//2. Act(Действие)
+	report := checker.Ready(context.Background())
+
+	//3. Assert
+	assert.Equal(t, StatusDown, report.Status)
+	assert.Equal(t, "консьюмер не читает топик", report.Components["consumer"].Error)
+	assert.Equal(t, StatusUp, report.Components[ComponentShutdown].Status)
+}
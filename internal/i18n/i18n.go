@@ -0,0 +1,86 @@
+// Package i18n - каталог сообщений API на русском и английском и выбор языка
+// по заголовку Accept-Language.
+package i18n
+
+import (
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/message/catalog"
+)
+
+// Default - язык, если клиент не попросил другой: исторически API отвечает по-русски.
+var Default = language.Russian
+
+// supported - языки каталога, первый - по умолчанию.
+var supported = []language.Tag{language.Russian, language.English}
+
+var (
+	matcher  = language.NewMatcher(supported)
+	messages = buildCatalog()
+)
+
+func buildCatalog() catalog.Catalog {
+	b := catalog.NewBuilder(catalog.Fallback(Default))
+	for tag, texts := range map[language.Tag]map[Key]string{language.Russian: russian, language.English: english} {
+		for key, text := range texts {
+			if err := b.SetString(tag, key, text); err != nil {
+				panic(err) // каталог статический, ошибка видна в тестах
+			}
+		}
+	}
+	return b
+}
+
+// Match - язык ответа: первый поддерживаемый из Accept-Language, иначе первый
+// поддерживаемый из fallbacks (например, locale заказа), иначе Default.
+func Match(acceptLanguage string, fallbacks ...string) language.Tag {
+	if tags, _, err := language.ParseAcceptLanguage(acceptLanguage); err == nil {
+		if tag, ok := match(tags...); ok {
+			return tag
+		}
+	}
+	for _, f := range fallbacks {
+		if tag, err := language.Parse(f); err == nil {
+			if tag, ok := match(tag); ok {
+				return tag
+			}
+		}
+	}
+	return Default
+}
+
+func match(tags ...language.Tag) (language.Tag, bool) {
+	if len(tags) == 0 {
+		return language.Tag{}, false
+	}
+	_, i, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return language.Tag{}, false
+	}
+	return supported[i], true
+}
+
+// T - сообщение key на языке tag, args подставляются как в fmt.Sprintf.
+func T(tag language.Tag, key Key, args ...any) string {
+	return message.NewPrinter(tag, message.Catalog(messages)).Sprintf(key, args...)
+}
+
+// Error - ошибка с текстом из каталога: клиенту она отдается на его языке.
+type Error struct {
+	Key  Key
+	Args []any
+}
+
+// Errorf - ошибка с сообщением key.
+func Errorf(key Key, args ...any) *Error {
+	return &Error{Key: key, Args: args}
+}
+
+func (e *Error) Error() string {
+	return T(Default, e.Key, e.Args...)
+}
+
+// Translate - текст ошибки на языке tag.
+func (e *Error) Translate(tag language.Tag) string {
+	return T(tag, e.Key, e.Args...)
+}
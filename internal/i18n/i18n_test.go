@@ -0,0 +1,76 @@
+package i18n
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		fallbacks      []string
+		want           language.Tag
+	}{
+		{"Без заголовка - русский", "", nil, language.Russian},
+		{"Английский с регионом", "en-US,en;q=0.9", nil, language.English},
+		{"Первый поддерживаемый по весу", "de-DE, fr;q=0.8, en;q=0.5, ru;q=0.3", nil, language.English},
+		{"Неподдерживаемый - locale заказа", "de", []string{"en"}, language.English},
+		{"Заголовок важнее locale заказа", "ru", []string{"en"}, language.Russian},
+		{"Мусор в заголовке и locale", "%%%", []string{"xx-invalid-"}, language.Russian},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, Match(tt.acceptLanguage, tt.fallbacks...))
+		})
+	}
+}
+
+// Каждое сообщение должно быть переведено на все языки каталога.
+func TestCatalog_Complete(t *testing.T) {
+	for key := range russian {
+		assert.Contains(t, english, key)
+	}
+	for key := range english {
+		assert.Contains(t, russian, key)
+	}
+}
+
+func TestT(t *testing.T) {
+	//1. Arrange(подготовка)
+	err := Errorf(MsgUnknownField, "payment.cost")
+
+	//2. Act(Действие)
+	//3. Assert
+	assert.Equal(t, "At most 100 UIDs per request", T(language.English, MsgTooManyOrderUIDs, 100))
+	assert.Equal(t, `Неизвестное поле "payment.cost"`, err.Error())
+	assert.Equal(t, `Unknown field "payment.cost"`, err.Translate(language.English))
+	assert.Equal(t, "no.such.key", T(language.English, "no.such.key"))
+}
+
+func TestViolations(t *testing.T) {
+	//1. Arrange(подготовка)
+	type subscription struct {
+		URL    string   `json:"url" validate:"required,url"`
+		Events []string `json:"events" validate:"required,gt=0,dive,oneof=order.created order.updated"`
+	}
+	err := Validator().Struct(subscription{URL: "not a url", Events: []string{"order.deleted"}})
+	require.Error(t, err)
+
+	//2. Act(Действие)
+	en, ok := Violations(err, language.English)
+	ru, _ := Violations(err, language.Russian)
+	_, plain := Violations(assert.AnError, language.English)
+
+	//3. Assert
+	require.True(t, ok)
+	require.Len(t, en, 2)
+	assert.Equal(t, Violation{Field: "url", Message: "url must be a valid URL"}, en[0])
+	assert.Equal(t, "events[0]", en[1].Field)
+	assert.Contains(t, en[1].Message, "must be one of")
+	assert.Contains(t, ru[0].Message, "url должен быть")
+	assert.False(t, plain)
+}
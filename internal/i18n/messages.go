@@ -0,0 +1,173 @@
+package i18n
+
+// Key - идентификатор сообщения в каталоге. Для ключа без перевода печатается сам ключ.
+type Key = string
+
+// Сообщения об ошибках API.
+const (
+	MsgInvalidOrderID         Key = "invalid_order_id"
+	MsgOrderNotFound          Key = "order_not_found"
+	MsgOrderNotCached         Key = "order_not_cached"
+	MsgOrderUIDsRequired      Key = "order_uids_required"
+	MsgTooManyOrderUIDs       Key = "too_many_order_uids"
+	MsgOrdersUnavailable      Key = "orders_unavailable"
+	MsgStorageUnavailable     Key = "storage_unavailable"
+	MsgInvalidPageSize        Key = "invalid_page_size"
+	MsgInvalidPageToken       Key = "invalid_page_token"
+	MsgContactSearchForbidden Key = "contact_search_forbidden"
+	MsgUnknownField           Key = "unknown_field"
+	MsgNotAcceptable          Key = "not_acceptable"
+	MsgInvalidView            Key = "invalid_view"
+	MsgUnmaskedForbidden      Key = "unmasked_forbidden"
+	MsgAuditUnavailable       Key = "audit_unavailable"
+	MsgEncodeOrderFailed      Key = "encode_order_failed"
+	MsgEncodeOrdersFailed     Key = "encode_orders_failed"
+	MsgInvalidVariant         Key = "invalid_variant"
+	MsgInvalidFormat          Key = "invalid_format"
+	MsgReceiptFailed          Key = "receipt_failed"
+	MsgInvalidLastEventID     Key = "invalid_last_event_id"
+	MsgInvalidBody            Key = "invalid_body"
+	MsgInvalidLimit           Key = "invalid_limit"
+	MsgValidationFailed       Key = "validation_failed"
+	MsgInvalidSubscriptionID  Key = "invalid_subscription_id"
+	MsgSubscriptionNotFound   Key = "subscription_not_found"
+	MsgSeekTargetRequired     Key = "seek_target_required"
+	MsgOffsetOutOfRange       Key = "offset_out_of_range"
+	MsgConsumerStopped        Key = "consumer_stopped"
+	MsgConfigReloadRejected   Key = "config_reload_rejected"
+	MsgCredentialsRequired    Key = "credentials_required"
+	MsgInvalidCredentials     Key = "invalid_credentials"
+	MsgRoleRequired           Key = "role_required"
+	MsgRateLimited            Key = "rate_limited"
+	MsgOverloaded             Key = "overloaded"
+	MsgMethodNotFound         Key = "method_not_found"
+	MsgInternal               Key = "internal"
+)
+
+// russian - язык API по умолчанию, в нем есть все ключи.
+var russian = map[Key]string{
+	MsgInvalidOrderID:         "Неправильный ID",
+	MsgOrderNotFound:          "Введен неверный ID: заказ не найден",
+	MsgOrderNotCached:         "Заказа нет в кэше",
+	MsgOrderUIDsRequired:      "Ожидается непустой список order_uids",
+	MsgTooManyOrderUIDs:       "Не больше %d UID за запрос",
+	MsgOrdersUnavailable:      "Не удалось получить заказы",
+	MsgStorageUnavailable:     "Хранилище заказов временно недоступно, повторите запрос позже",
+	MsgInvalidPageSize:        "page_size должен быть неотрицательным числом",
+	MsgInvalidPageToken:       "Некорректный page_token",
+	MsgContactSearchForbidden: "Поиск по телефону и email доступен ролям support и admin",
+	MsgUnknownField:           "Неизвестное поле %q",
+	MsgNotAcceptable:          "Поддерживаются форматы: %s",
+	MsgInvalidView:            "view: ожидается masked или unmasked",
+	MsgUnmaskedForbidden:      "Персональные данные без маскирования доступны ролям support и admin",
+	MsgAuditUnavailable:       "Журнал аудита недоступен",
+	MsgEncodeOrderFailed:      "Не удалось сериализовать заказ",
+	MsgEncodeOrdersFailed:     "Не удалось сериализовать заказы",
+	MsgInvalidVariant:         "variant: ожидается receipt или packing_slip",
+	MsgInvalidFormat:          "format: ожидается html или text",
+	MsgReceiptFailed:          "Не удалось сформировать печатную форму",
+	MsgInvalidLastEventID:     "Неправильный Last-Event-ID",
+	MsgInvalidBody:            "Некорректное тело запроса",
+	MsgInvalidLimit:           "limit должен быть от 1 до %d",
+	MsgValidationFailed:       "Запрос не прошел проверку: %s",
+	MsgInvalidSubscriptionID:  "Неправильный ID подписки",
+	MsgSubscriptionNotFound:   "Подписка не найдена",
+	MsgSeekTargetRequired:     "Нужно задать offset или timestamp",
+	MsgOffsetOutOfRange:       "offset вне диапазона партиции, границы - в GET /admin/consumer",
+	MsgConsumerStopped:        "Консьюмер не запущен",
+	MsgConfigReloadRejected:   "Конфигурация не применена, действует прежняя: %s",
+	MsgCredentialsRequired:    "Требуется API-ключ или bearer-токен",
+	MsgInvalidCredentials:     "Неверные учетные данные",
+	MsgRoleRequired:           "Недостаточно прав: нужна роль %s",
+	MsgRateLimited:            "Слишком много запросов, повторите позже",
+	MsgOverloaded:             "Сервис перегружен, повторите позже",
+	MsgMethodNotFound:         "Метод не найден",
+	MsgInternal:               "Внутренняя ошибка",
+
+	// печатные формы
+	"receipt.title":       "Чек по заказу %s",
+	"receipt.track":       "Трек-номер",
+	"receipt.created":     "Создан",
+	"receipt.printed":     "Напечатан",
+	"receipt.recipient":   "Получатель",
+	"receipt.address":     "Адрес",
+	"receipt.delivery":    "Доставка",
+	"receipt.item":        "Товар",
+	"receipt.size":        "Размер",
+	"receipt.price":       "Цена",
+	"receipt.sale":        "Скидка",
+	"receipt.sale_price":  "Со скидкой",
+	"receipt.sum":         "Сумма",
+	"receipt.goods":       "Товары",
+	"receipt.custom_fee":  "Таможенный сбор",
+	"receipt.total":       "Итого",
+	"receipt.payment":     "Оплата",
+	"receipt.transaction": "транзакция",
+	"slip.title":          "Упаковочный лист %s",
+	"slip.summary":        "Позиций: %d, посылок: %d",
+	"slip.article":        "Артикул",
+	"slip.brand":          "Бренд",
+}
+
+var english = map[Key]string{
+	MsgInvalidOrderID:         "Invalid order ID",
+	MsgOrderNotFound:          "Order not found",
+	MsgOrderNotCached:         "Order is not in the cache",
+	MsgOrderUIDsRequired:      "order_uids must be a non-empty list",
+	MsgTooManyOrderUIDs:       "At most %d UIDs per request",
+	MsgOrdersUnavailable:      "Failed to get orders",
+	MsgStorageUnavailable:     "Order storage is temporarily unavailable, retry later",
+	MsgInvalidPageSize:        "page_size must be a non-negative number",
+	MsgInvalidPageToken:       "Invalid page_token",
+	MsgContactSearchForbidden: "Search by phone and email is available to support and admin roles",
+	MsgUnknownField:           "Unknown field %q",
+	MsgNotAcceptable:          "Supported formats: %s",
+	MsgInvalidView:            "view: expected masked or unmasked",
+	MsgUnmaskedForbidden:      "Unmasked personal data is available to support and admin roles",
+	MsgAuditUnavailable:       "Audit log is unavailable",
+	MsgEncodeOrderFailed:      "Failed to encode the order",
+	MsgEncodeOrdersFailed:     "Failed to encode the orders",
+	MsgInvalidVariant:         "variant: expected receipt or packing_slip",
+	MsgInvalidFormat:          "format: expected html or text",
+	MsgReceiptFailed:          "Failed to render the document",
+	MsgInvalidLastEventID:     "Invalid Last-Event-ID",
+	MsgInvalidBody:            "Invalid request body",
+	MsgInvalidLimit:           "limit must be between 1 and %d",
+	MsgValidationFailed:       "Request validation failed: %s",
+	MsgInvalidSubscriptionID:  "Invalid subscription ID",
+	MsgSubscriptionNotFound:   "Subscription not found",
+	MsgSeekTargetRequired:     "Either offset or timestamp is required",
+	MsgOffsetOutOfRange:       "offset is out of the partition range, see GET /admin/consumer for bounds",
+	MsgConsumerStopped:        "Consumer is not running",
+	MsgConfigReloadRejected:   "Configuration was not applied, the previous one stays in effect: %s",
+	MsgCredentialsRequired:    "An API key or bearer token is required",
+	MsgInvalidCredentials:     "Invalid credentials",
+	MsgRoleRequired:           "Insufficient permissions: role %s required",
+	MsgRateLimited:            "Too many requests, retry later",
+	MsgOverloaded:             "Service is overloaded, retry later",
+	MsgMethodNotFound:         "Method not found",
+	MsgInternal:               "Internal error",
+
+	"receipt.title":       "Receipt for order %s",
+	"receipt.track":       "Tracking number",
+	"receipt.created":     "Created",
+	"receipt.printed":     "Printed",
+	"receipt.recipient":   "Recipient",
+	"receipt.address":     "Address",
+	"receipt.delivery":    "Delivery",
+	"receipt.item":        "Item",
+	"receipt.size":        "Size",
+	"receipt.price":       "Price",
+	"receipt.sale":        "Discount",
+	"receipt.sale_price":  "Discounted",
+	"receipt.sum":         "Amount",
+	"receipt.goods":       "Goods",
+	"receipt.custom_fee":  "Customs fee",
+	"receipt.total":       "Total",
+	"receipt.payment":     "Payment",
+	"receipt.transaction": "transaction",
+	"slip.title":          "Packing slip %s",
+	"slip.summary":        "Items: %d, parcels: %d",
+	"slip.article":        "Article",
+	"slip.brand":          "Brand",
+}
@@ -0,0 +1,88 @@
+package i18n
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/go-playground/locales/en"
+	"github.com/go-playground/locales/ru"
+	ut "github.com/go-playground/universal-translator"
+	"github.com/go-playground/validator/v10"
+	entranslations "github.com/go-playground/validator/v10/translations/en"
+	rutranslations "github.com/go-playground/validator/v10/translations/ru"
+	"golang.org/x/text/language"
+)
+
+// Violation - нарушение правила валидации одним полем.
+type Violation struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+var (
+	validatorOnce sync.Once
+	validate      *validator.Validate
+	translators   map[language.Tag]ut.Translator
+)
+
+// Validator - общий validator: поля называются по тегам json, у правил есть
+// переводы на языки каталога. Переводы регистрируются на конкретном экземпляре,
+// поэтому ошибки для Violations должны приходить от него.
+func Validator() *validator.Validate {
+	validatorOnce.Do(func() {
+		validate = validator.New()
+		validate.RegisterTagNameFunc(jsonName)
+
+		uni := ut.New(en.New(), en.New(), ru.New())
+		enTrans, _ := uni.GetTranslator("en")
+		ruTrans, _ := uni.GetTranslator("ru")
+		if err := entranslations.RegisterDefaultTranslations(validate, enTrans); err != nil {
+			panic(err)
+		}
+		if err := rutranslations.RegisterDefaultTranslations(validate, ruTrans); err != nil {
+			panic(err)
+		}
+		translators = map[language.Tag]ut.Translator{language.English: enTrans, language.Russian: ruTrans}
+	})
+	return validate
+}
+
+// Violations - переведенные на язык tag ошибки полей, если err содержит ошибки validator.
+func Violations(err error, tag language.Tag) ([]Violation, bool) {
+	var errs validator.ValidationErrors
+	if !errors.As(err, &errs) {
+		return nil, false
+	}
+	Validator()
+	trans, ok := translators[tag]
+	if !ok {
+		trans = translators[Default]
+	}
+	out := make([]Violation, len(errs))
+	for i, fe := range errs {
+		out[i] = Violation{Field: fieldPath(fe.Namespace()), Message: fe.Translate(trans)}
+	}
+	return out, true
+}
+
+// jsonName - имя поля в сообщениях такое же, как в JSON запроса.
+func jsonName(f reflect.StructField) string {
+	name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+	if name == "-" {
+		return ""
+	}
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// fieldPath - путь поля без имени корневой структуры: "events[0]", "delivery.phone".
+func fieldPath(namespace string) string {
+	if _, rest, ok := strings.Cut(namespace, "."); ok {
+		return rest
+	}
+	return namespace
+}
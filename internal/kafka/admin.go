@@ -7,8 +7,8 @@ import (
 	"github.com/IBM/sarama"
 )
 
-func EnsureTopicExists(broker []string, topic string) error {
-	config := sarama.NewConfig()
+func EnsureTopicExists(broker []string, sec *Security, topic string) error {
+	config := newConfig(sec)
 	config.Version = sarama.V2_1_0_0
 
 	//создаем клиента для управления кластером
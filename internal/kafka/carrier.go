@@ -0,0 +1,75 @@
+package kafka
+
+import (
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+var (
+	_ propagation.TextMapCarrier = KafkaHeaderCarrier(nil)
+	_ propagation.TextMapCarrier = (*ProducerHeaderCarrier)(nil)
+)
+
+// KafkaHeaderCarrier - обертка над заголовками прочитанного сообщения,
+// используется на стороне консьюмера для извлечения контекста трассировки.
+type KafkaHeaderCarrier []*sarama.RecordHeader
+
+func (c KafkaHeaderCarrier) Get(key string) string {
+	for _, h := range c {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set ничего не делает: заголовки прочитанного сообщения только для чтения.
+// Для записи используется ProducerHeaderCarrier.
+func (c KafkaHeaderCarrier) Set(string, string) {
+}
+
+func (c KafkaHeaderCarrier) Keys() []string {
+	keys := make([]string, len(c))
+	for i, h := range c {
+		keys[i] = string(h.Key)
+	}
+	return keys
+}
+
+// ProducerHeaderCarrier записывает контекст трассировки в заголовки
+// sarama.ProducerMessage перед отправкой.
+type ProducerHeaderCarrier struct {
+	msg *sarama.ProducerMessage
+}
+
+func NewProducerHeaderCarrier(msg *sarama.ProducerMessage) *ProducerHeaderCarrier {
+	return &ProducerHeaderCarrier{msg: msg}
+}
+
+func (c *ProducerHeaderCarrier) Get(key string) string {
+	for _, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			return string(h.Value)
+		}
+	}
+	return ""
+}
+
+// Set заменяет существующий заголовок, чтобы повторная инъекция не дублировала traceparent.
+func (c *ProducerHeaderCarrier) Set(key string, value string) {
+	for i, h := range c.msg.Headers {
+		if string(h.Key) == key {
+			c.msg.Headers[i].Value = []byte(value)
+			return
+		}
+	}
+	c.msg.Headers = append(c.msg.Headers, sarama.RecordHeader{Key: []byte(key), Value: []byte(value)})
+}
+
+func (c *ProducerHeaderCarrier) Keys() []string {
+	keys := make([]string, len(c.msg.Headers))
+	for i, h := range c.msg.Headers {
+		keys[i] = string(h.Key)
+	}
+	return keys
+}
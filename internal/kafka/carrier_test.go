@@ -0,0 +1,40 @@
+package kafka
+
+import (
+	"context"
+	"testing"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Контекст, записанный продюсером в заголовки, должен извлекаться консьюмером.
+func TestHeaderCarrier_RoundTrip(t *testing.T) {
+	//1. Arrange(подготовка)
+	sc := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{1, 2, 3},
+		SpanID:     trace.SpanID{4, 5, 6},
+		TraceFlags: trace.FlagsSampled,
+	})
+	ctx := trace.ContextWithSpanContext(context.Background(), sc)
+	prop := propagation.TraceContext{}
+	msg := &sarama.ProducerMessage{Topic: "orders"}
+
+	//2. Act(Действие)
+	prop.Inject(ctx, NewProducerHeaderCarrier(msg))
+	prop.Inject(ctx, NewProducerHeaderCarrier(msg)) // повторная инъекция не дублирует заголовок
+
+	consumed := make([]*sarama.RecordHeader, len(msg.Headers))
+	for i := range msg.Headers {
+		consumed[i] = &msg.Headers[i]
+	}
+	extracted := trace.SpanContextFromContext(prop.Extract(context.Background(), KafkaHeaderCarrier(consumed)))
+
+	//3. Assert
+	assert.Len(t, msg.Headers, 1)
+	assert.Equal(t, sc.TraceID(), extracted.TraceID())
+	assert.Equal(t, sc.SpanID(), extracted.SpanID())
+	assert.True(t, extracted.IsRemote())
+}
@@ -2,66 +2,188 @@ package kafka
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"log/slog"
+	"sync"
+	"sync/atomic"
 	"wb-project/internal/logger/sl"
 	"wb-project/internal/metric"
+	"wb-project/internal/models"
 
 	"github.com/IBM/sarama"
 	"go.opentelemetry.io/otel"
-	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
 )
 
-type KafkaHeaderCarrier []*sarama.RecordHeader
+// partition - сервис читает единственную партицию топика заказов.
+const partition int32 = 0
+
 type MessageProcessor func(context.Context, []byte) error
 type OrderConsumer struct {
-	consumer sarama.Consumer
-	topic    string
+	brokers []string
+	conf    *sarama.Config
+	group   string
+
+	// подключение к брокерам, см. Connect; поля заполняются до connected
+	connMu    sync.Mutex
+	connected atomic.Bool
+	client    sarama.Client
+	consumer  sarama.Consumer
+	offsets   sarama.OffsetManager // nil, если группа не задана
+
+	topic   string
+	pom     sarama.PartitionOffsetManager // закоммиченный offset группы для partition
+	running atomic.Bool                   // партиция читается, сбрасывается при выходе из Start
 	// Это может быть сервис, который умеет валидировать и сохранять.
 	processor MessageProcessor
+
+	// управление из admin API, см. control.go
+	paused atomic.Bool
+	offset atomic.Int64 // следующий offset к обработке, -1 - еще ничего не прочитано
+	seeks  chan int64
+	wake   chan struct{}
+	mu     sync.Mutex
+	pc     sarama.PartitionConsumer
 }
 
-func NewOrderConsumer(broker []string, topic string, processor MessageProcessor) (*OrderConsumer, error) {
-	conf := sarama.NewConfig()
+// NewOrderConsumer - консьюмер топика заказов. С непустой group обработанный offset
+// коммитится в Kafka от имени группы, и после перезапуска чтение продолжается с него;
+// без группы, как и при первом запуске группы, читаются только новые сообщения.
+// К брокерам консьюмер подключается в Connect или при первом Start.
+func NewOrderConsumer(broker []string, sec *Security, topic, group string, processor MessageProcessor) *OrderConsumer {
+	conf := newConfig(sec)
 	// Указываем, откуда будет читать наш консьюмер
 	conf.Consumer.Offsets.Initial = sarama.OffsetNewest
 
-	consumer, err := sarama.NewConsumer(broker, conf)
+	order := &OrderConsumer{
+		brokers:   broker,
+		conf:      conf,
+		group:     group,
+		topic:     topic,
+		processor: processor,
+		seeks:     make(chan int64),
+		wake:      make(chan struct{}, 1),
+	}
+	order.offset.Store(-1)
+	return order
+}
+
+// Connect - подключение к брокерам; повторный вызов после успеха ничего не делает.
+func (order *OrderConsumer) Connect() error {
+	order.connMu.Lock()
+	defer order.connMu.Unlock()
+	if order.connected.Load() {
+		return nil
+	}
+	// клиент держим отдельно: через него проверяется доступность брокеров
+	client, err := sarama.NewClient(order.brokers, order.conf)
+	if err != nil {
+		return fmt.Errorf("ошибка при подключении к Kafka: %w", err)
+	}
+	consumer, err := sarama.NewConsumerFromClient(client)
 	if err != nil {
-		return nil, fmt.Errorf("ошибка при создании консьюмера: %w", err)
+		_ = client.Close()
+		return fmt.Errorf("ошибка при создании консьюмера: %w", err)
+	}
+	var offsets sarama.OffsetManager
+	if order.group != "" {
+		if offsets, err = sarama.NewOffsetManagerFromClient(order.group, client); err != nil {
+			_ = consumer.Close()
+			_ = client.Close()
+			return fmt.Errorf("ошибка при создании offset manager группы %s: %w", order.group, err)
+		}
 	}
-	return &OrderConsumer{consumer: consumer, topic: topic, processor: processor}, nil
+	order.client, order.consumer, order.offsets = client, consumer, offsets
+	order.connected.Store(true)
+	return nil
 }
 
 //Подключиться и подписаться на канал сообщений: настроить получение данных из брокера сообщений (Kafka).
 
 func (order *OrderConsumer) Start(ctx context.Context) error {
-	//подключение к партициям(test-new), номер партиции(0), откуда начинаем читать(с новых сообщенией)
-	partitionConsumer, err := order.consumer.ConsumePartition(order.topic, 0, sarama.OffsetNewest)
+	if err := order.Connect(); err != nil {
+		return err
+	}
+	//подключение к партициям(test-new), номер партиции(0), откуда начинаем читать(с закоммиченного группой или с новых сообщенией)
+	offset, err := order.committed()
 	if err != nil {
-		panic("Не удалось устроить работу с партициями")
+		return err
 	}
-	defer func() {
+	defer order.running.Store(false)
+	for {
+		partitionConsumer, err := order.consumer.ConsumePartition(order.topic, partition, offset)
+		if errors.Is(err, sarama.ErrOffsetOutOfRange) && offset >= 0 {
+			// закоммиченные сообщения уже удалены по retention - читаем с самого раннего
+			log.Printf("Kafka consumer: offset %d топика %s больше не хранится, чтение с начала партиции", offset, order.topic)
+			offset = sarama.OffsetOldest
+			partitionConsumer, err = order.consumer.ConsumePartition(order.topic, partition, offset)
+		}
+		if err != nil {
+			return fmt.Errorf("не удалось подписаться на партицию топика %s с offset %d: %w", order.topic, offset, err)
+		}
+		order.attach(partitionConsumer, offset)
+
+		//читаем, пока не остановят или не попросят перемотать
+		next, err := order.consume(ctx, partitionConsumer)
+		order.attach(nil, 0)
 		if err := partitionConsumer.Close(); err != nil {
 			log.Printf("ошибка при закрытии partitionConsumer: %v", err)
 		}
-	}()
+		if err != nil {
+			return err
+		}
+		log.Printf("Kafka consumer: перемотка топика %s на offset %d", order.topic, next)
+		if order.pom != nil {
+			order.pom.ResetOffset(next, "")
+		}
+		offset = next
+	}
+}
+
+// committed - offset, с которого продолжает группа.
+func (order *OrderConsumer) committed() (int64, error) {
+	if order.offsets == nil {
+		return sarama.OffsetNewest, nil
+	}
+	if order.pom == nil {
+		pom, err := order.offsets.ManagePartition(order.topic, partition)
+		if err != nil {
+			return 0, fmt.Errorf("не удалось получить offset группы для топика %s: %w", order.topic, err)
+		}
+		order.pom = pom
+	}
+	offset, _ := order.pom.NextOffset()
+	return offset, nil
+}
 
+// consume - обработка сообщений партиции. Возвращает offset, если пришел запрос на перемотку.
+func (order *OrderConsumer) consume(ctx context.Context, partitionConsumer sarama.PartitionConsumer) (int64, error) {
 	//Messages() возвращает сообщения из партиций.
 	for {
+		// на паузе сообщения из буфера тоже не забираем
+		messages := partitionConsumer.Messages()
+		if order.paused.Load() {
+			messages = nil
+		}
 		select {
 		case <-ctx.Done(): //1. шаг 1 graceful shutdown
 			log.Println("Kafka consumer stopping...")
-			return ctx.Err()
-		case message := <-partitionConsumer.Messages():
-			parCtx := otel.GetTextMapPropagator().Extract(ctx, KafkaHeaderCarrier(message.Headers))
-
-			//трасировка
+			return 0, ctx.Err()
+		case offset := <-order.seeks:
+			return offset, nil
+		case <-order.wake:
+		case message := <-messages:
+			// начатое сообщение дообрабатывается и при остановке: БД закрывается только после выхода из Start
+			parCtx := otel.GetTextMapPropagator().Extract(context.WithoutCancel(ctx), KafkaHeaderCarrier(message.Headers))
+
+			//трасировка: продолжаем трейс продюсера и дополнительно связываем спаны ссылкой
 			tr := otel.Tracer("consumer")
-			processCtx, span := tr.Start(parCtx, "Kafka.Consume",
-				trace.WithSpanKind(trace.SpanKindConsumer)) //отмечаем что это консьюмер
+			processCtx, span := tr.Start(parCtx, order.topic+" process",
+				trace.WithSpanKind(trace.SpanKindConsumer), //отмечаем что это консьюмер
+				trace.WithLinks(trace.LinkFromContext(parCtx)))
 
 			//логирование
 			slog.Info("Сообщение из кафки прочитано: ",
@@ -72,9 +194,16 @@ func (order *OrderConsumer) Start(ctx context.Context) error {
 			)
 
 			span.SetAttributes(
-				attribute.String("message.kafka.topic", order.topic),
-				attribute.Int("message.kafka.partition", 0),
-				attribute.Int64("message.kafka.offset", message.Offset))
+				semconv.MessagingSystem("kafka"),
+				semconv.MessagingOperationProcess,
+				semconv.MessagingSourceName(message.Topic),
+				semconv.MessagingSourceKindTopic,
+				semconv.MessagingKafkaSourcePartition(int(message.Partition)),
+				semconv.MessagingKafkaMessageOffset(int(message.Offset)),
+				semconv.MessagingMessagePayloadSizeBytes(len(message.Value)))
+			if len(message.Key) > 0 {
+				span.SetAttributes(semconv.MessagingKafkaMessageKey(string(message.Key)))
+			}
 
 			if err := order.processor(processCtx, message.Value); err != nil {
 				slog.Error("error processing message",
@@ -82,34 +211,36 @@ func (order *OrderConsumer) Start(ctx context.Context) error {
 					sl.Traced(processCtx))
 				span.RecordError(err)
 				metric.KafkaMessagesTotal.WithLabelValues("error").Inc()
+				if errors.Is(err, models.ErrUnavailable) {
+					// БД недоступна: offset не сдвигаем, консьюмер перезапустится с паузой
+					// и прочитает сообщение снова, а не пропустит его
+					span.End()
+					return 0, fmt.Errorf("обработка сообщения с offset %d отложена: %w", message.Offset, err)
+				}
 			} else {
 				metric.KafkaMessagesTotal.WithLabelValues("success").Inc()
 			}
 			span.End()
+			order.offset.Store(message.Offset + 1)
+			if order.pom != nil {
+				order.pom.MarkOffset(message.Offset+1, "")
+			}
 		}
 	}
 }
 
 func (order *OrderConsumer) Close() error {
-	return order.consumer.Close()
-}
-
-func (c KafkaHeaderCarrier) Get(key string) string {
-	for _, h := range c {
-		if string(h.Key) == key {
-			return string(h.Value)
-		}
+	if !order.connected.Load() {
+		return nil
 	}
-	return ""
-}
-
-func (c KafkaHeaderCarrier) Set(key string, value string) {
-}
-
-func (c KafkaHeaderCarrier) Keys() []string {
-	keys := make([]string, len(c))
-	for i, h := range c {
-		keys[i] = string(h.Key)
+	var errs []error
+	// offset manager при закрытии коммитит последний отмеченный offset, поэтому закрывается до клиента
+	if order.pom != nil {
+		errs = append(errs, order.pom.Close())
+	}
+	if order.offsets != nil {
+		errs = append(errs, order.offsets.Close())
 	}
-	return keys
+	errs = append(errs, order.consumer.Close(), order.client.Close())
+	return errors.Join(errs...)
 }
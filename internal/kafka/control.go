@@ -0,0 +1,146 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/IBM/sarama"
+)
+
+var ErrOffsetOutOfRange = errors.New("offset вне диапазона партиции")
+
+// Position - положение консьюмера в партиции.
+type Position struct {
+	Topic         string `json:"topic"`
+	Partition     int32  `json:"partition"`
+	Running       bool   `json:"running"`
+	Paused        bool   `json:"paused"`
+	Offset        int64  `json:"offset"`          // следующий к обработке, -1 - с момента запуска сообщений не было
+	Oldest        int64  `json:"oldest"`          // самый ранний offset, который еще хранит брокер
+	HighWaterMark int64  `json:"high_water_mark"` // offset следующего записанного сообщения
+	Lag           int64  `json:"lag"`             // сообщений записано, но еще не обработано
+}
+
+// attach - запоминает текущий partition consumer, чтобы пауза переживала перемотку.
+func (order *OrderConsumer) attach(pc sarama.PartitionConsumer, offset int64) {
+	order.mu.Lock()
+	defer order.mu.Unlock()
+	order.pc = pc
+	if pc == nil {
+		return
+	}
+	if offset >= 0 {
+		order.offset.Store(offset)
+	}
+	if order.paused.Load() {
+		pc.Pause()
+	}
+	order.running.Store(true)
+}
+
+// Pause - прекращает получение и обработку сообщений; обрабатываемое сейчас сообщение дорабатывается.
+func (order *OrderConsumer) Pause() error {
+	return order.setPaused(true)
+}
+
+// Resume - продолжает чтение с того же места.
+func (order *OrderConsumer) Resume() error {
+	return order.setPaused(false)
+}
+
+func (order *OrderConsumer) setPaused(paused bool) error {
+	order.mu.Lock()
+	defer order.mu.Unlock()
+	if order.pc == nil {
+		return ErrConsumerStopped
+	}
+	order.paused.Store(paused)
+	if paused {
+		order.pc.Pause()
+	} else {
+		order.pc.Resume()
+	}
+	// цикл чтения пересчитывает, забирать ли сообщения из буфера
+	select {
+	case order.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// SeekOffset - перематывает консьюмер: следующим будет обработано сообщение с offset.
+// При перемотке назад уже сохраненные заказы отклоняются БД как дубликаты,
+// так что повтор догружает только пропущенные.
+func (order *OrderConsumer) SeekOffset(ctx context.Context, offset int64) (int64, error) {
+	if !order.Running() {
+		return 0, ErrConsumerStopped
+	}
+	oldest, newest, err := order.bounds()
+	if err != nil {
+		return 0, err
+	}
+	if offset < oldest || offset > newest {
+		return 0, fmt.Errorf("%w: %d не входит в [%d, %d]", ErrOffsetOutOfRange, offset, oldest, newest)
+	}
+	select {
+	case order.seeks <- offset:
+		return offset, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
+
+// SeekTime - перематывает на первое сообщение, записанное не раньше t.
+// Если таких нет, консьюмер ждет новых сообщений.
+func (order *OrderConsumer) SeekTime(ctx context.Context, t time.Time) (int64, error) {
+	if !order.connected.Load() {
+		return 0, ErrConsumerStopped
+	}
+	offset, err := order.client.GetOffset(order.topic, partition, t.UnixMilli())
+	if err != nil {
+		return 0, fmt.Errorf("не удалось найти offset по времени: %w", err)
+	}
+	if offset == sarama.OffsetNewest {
+		if _, offset, err = order.bounds(); err != nil {
+			return 0, err
+		}
+	}
+	return order.SeekOffset(ctx, offset)
+}
+
+// Position - текущий offset консьюмера и отставание от последнего записанного сообщения.
+func (order *OrderConsumer) Position(context.Context) (Position, error) {
+	oldest, newest, err := order.bounds()
+	if err != nil {
+		return Position{}, err
+	}
+	pos := Position{
+		Topic:         order.topic,
+		Partition:     partition,
+		Running:       order.Running(),
+		Paused:        order.paused.Load(),
+		Offset:        order.offset.Load(),
+		Oldest:        oldest,
+		HighWaterMark: newest,
+	}
+	if pos.Offset >= 0 {
+		pos.Lag = max(newest-pos.Offset, 0)
+	}
+	return pos, nil
+}
+
+// bounds - самый ранний хранимый offset и high water mark партиции.
+func (order *OrderConsumer) bounds() (oldest, newest int64, err error) {
+	if !order.connected.Load() {
+		return 0, 0, ErrConsumerStopped
+	}
+	if oldest, err = order.client.GetOffset(order.topic, partition, sarama.OffsetOldest); err != nil {
+		return 0, 0, fmt.Errorf("не удалось получить начало партиции: %w", err)
+	}
+	if newest, err = order.client.GetOffset(order.topic, partition, sarama.OffsetNewest); err != nil {
+		return 0, 0, fmt.Errorf("не удалось получить конец партиции: %w", err)
+	}
+	return oldest, newest, nil
+}
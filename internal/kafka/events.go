@@ -0,0 +1,90 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+	"wb-project/internal/models"
+
+	"github.com/IBM/sarama"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// Заголовки сообщений топика событий.
+const (
+	HeaderEventID       = "event-id"
+	HeaderEventType     = "event-type"
+	HeaderSchemaVersion = "schema-version"
+)
+
+// EventProducer публикует доменные события из outbox в топик событий.
+// К брокерам подключается в Connect; до этого Publish возвращает ErrNotConnected,
+// и релей откладывает отправку.
+type EventProducer struct {
+	brokers  []string
+	config   *sarama.Config
+	mu       sync.RWMutex
+	producer sarama.SyncProducer
+	topic    string
+}
+
+func NewEventProducer(broker []string, sec *Security, topic string) *EventProducer {
+	config := newConfig(sec)
+	config.Producer.Return.Successes = true
+	config.Producer.RequiredAcks = sarama.WaitForAll
+	config.Producer.Idempotent = true // повторная отправка не должна дублировать событие
+	config.Producer.Retry.Max = 5
+	config.Net.MaxOpenRequests = 1 // обязательно для идемпотентного продюсера
+	config.Version = sarama.V2_1_0_0
+
+	return &EventProducer{brokers: broker, config: config, topic: topic}
+}
+
+// Connect - подключение к брокерам; повторный вызов после успеха ничего не делает.
+func (p *EventProducer) Connect() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer != nil {
+		return nil
+	}
+	producer, err := sarama.NewSyncProducer(p.brokers, p.config)
+	if err != nil {
+		return fmt.Errorf("не удалось создать продюсера событий: %w", err)
+	}
+	p.producer = producer
+	return nil
+}
+
+// Publish - отправляет событие с ключом order_uid, чтобы события одного заказа
+// попадали в одну партицию. Трейс продолжается из контекста, сохраненного в outbox.
+func (p *EventProducer) Publish(ctx context.Context, event models.OutboxEvent) error {
+	ctx = otel.GetTextMapPropagator().Extract(ctx, propagation.MapCarrier(event.Headers))
+
+	message := &sarama.ProducerMessage{
+		Topic: p.topic,
+		Key:   sarama.StringEncoder(event.AggregateID),
+		Value: sarama.ByteEncoder(event.Payload),
+		Headers: []sarama.RecordHeader{
+			{Key: []byte(HeaderEventID), Value: []byte(strconv.FormatInt(event.ID, 10))},
+			{Key: []byte(HeaderEventType), Value: []byte(event.EventType)},
+			{Key: []byte(HeaderSchemaVersion), Value: []byte(strconv.Itoa(event.SchemaVersion))},
+		},
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.producer == nil {
+		return ErrNotConnected
+	}
+	return SendTraced(ctx, p.producer, message)
+}
+
+func (p *EventProducer) Close() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.producer == nil {
+		return nil
+	}
+	return p.producer.Close()
+}
@@ -0,0 +1,46 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	ErrConsumerStopped = errors.New("консьюмер не читает топик")
+	ErrNotConnected    = errors.New("нет подключения к брокерам Kafka")
+)
+
+// Running - читает ли консьюмер партицию прямо сейчас.
+func (order *OrderConsumer) Running() bool {
+	return order.running.Load()
+}
+
+// CheckConsumer - проверка состояния консьюмера для readiness.
+func (order *OrderConsumer) CheckConsumer(context.Context) error {
+	if !order.Running() {
+		return ErrConsumerStopped
+	}
+	return nil
+}
+
+// CheckBrokers - доступность брокеров: обновляет метаданные топика.
+// sarama не принимает контекст, поэтому запрос ждем не дольше дедлайна ctx.
+func (order *OrderConsumer) CheckBrokers(ctx context.Context) error {
+	if !order.connected.Load() {
+		return ErrNotConnected
+	}
+	done := make(chan error, 1)
+	go func() {
+		done <- order.client.RefreshMetadata(order.topic)
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			return fmt.Errorf("брокеры Kafka недоступны: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("брокеры Kafka не ответили: %w", ctx.Err())
+	}
+}
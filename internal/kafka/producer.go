@@ -1,13 +1,16 @@
 package kafka
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"time"
 	"wb-project/internal/models"
 
 	"github.com/IBM/sarama"
-	"github.com/brianvoe/gofakeit"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type OrderProducer struct {
@@ -15,8 +18,8 @@ type OrderProducer struct {
 	topic    string
 }
 
-func NewProducer(broker []string, topic string) (*OrderProducer, error) {
-	config := sarama.NewConfig()
+func NewProducer(broker []string, sec *Security, topic string) (*OrderProducer, error) {
+	config := newConfig(sec)
 	config.Producer.Return.Successes = true
 	config.Producer.RequiredAcks = sarama.WaitForAll // Ждем подтверждения от всех брокеров
 
@@ -30,79 +33,70 @@ func NewProducer(broker []string, topic string) (*OrderProducer, error) {
 	}, nil
 }
 
-func (pr *OrderProducer) Send() error {
-	//1. Генерация данных
-	order := generateFakeOrders()
-	//2. Парсинг данных
+// Send - отправляет заказ в топик и ждет подтверждения от брокера.
+// Пустой key означает, что партицию выберет партиционер sarama.
+func (pr *OrderProducer) Send(ctx context.Context, key string, order models.Order) (int32, int64, error) {
+	//1. Парсинг данных
 	data, err := json.Marshal(order)
 	if err != nil {
-		return fmt.Errorf("ошибка при парсинге для отправки %v", err)
+		return 0, 0, fmt.Errorf("ошибка при парсинге для отправки %v", err)
 	}
-	//3. Создания сообщения
+	//2. Создания сообщения
 	message := &sarama.ProducerMessage{
 		Topic: pr.topic,
 		Value: sarama.ByteEncoder(data),
 	}
-	//4. Отправка сообщений в кафку
-	partition, offset, err := pr.producer.SendMessage(message)
-	if err != nil {
-		return fmt.Errorf("ошибка при отправке данных в кафку")
+	if key != "" {
+		message.Key = sarama.StringEncoder(key)
 	}
-	fmt.Printf("Заказ отправлен успешно!\n")
-	fmt.Printf("UID: %s\nPartition: %d\nOffset: %d\n", order.OrderUID, partition, offset)
-	return nil
+	//3. Отправка сообщений в кафку
+	if err := SendTraced(ctx, pr.producer, message); err != nil {
+		return 0, 0, fmt.Errorf("ошибка при отправке данных в кафку: %w", err)
+	}
+	return message.Partition, message.Offset, nil
 }
 
-func generateFakeOrders() models.Order {
-	return models.Order{
-		OrderUID:    gofakeit.UUID(),
-		TrackNumber: "WB-" + gofakeit.Numerify("##########"),
-		Entry:       "WBIL",
-		Delivery: models.Delivery{
-			Name:    gofakeit.Name(),
-			Phone:   "+79" + gofakeit.Numerify("#########"), // e164 формат
-			Zip:     gofakeit.Zip(),
-			City:    gofakeit.City(),
-			Address: gofakeit.Address().Address,
-			Region:  gofakeit.State(),
-			Email:   gofakeit.Email(),
-		},
-		Payment: models.Payment{
-			Transaction:  gofakeit.UUID(),
-			RequestID:    gofakeit.Numerify("##########"),
-			Currency:     "RUB", // ровно 3 символа
-			Provider:     "wbpay",
-			Amount:       gofakeit.Number(100, 100000), // gt=0
-			PaymentDt:    int(time.Now().Unix()),
-			Bank:         "alpha",
-			DeliveryCost: 500,
-			GoodsTotal:   gofakeit.Number(100, 100000),
-			CustomFee:    10,
-		},
-		Items: []models.Items{
-			{
-				ChrtID:      gofakeit.Number(1, 1000),
-				TrackNumber: "TRK-" + gofakeit.Numerify("#####"),
-				Price:       gofakeit.Number(100, 10000),
-				Rid:         gofakeit.UUID(),
-				Name:        gofakeit.Name(),
-				Sale:        gofakeit.Number(0, 50),
-				Size:        "XL",
-				TotalPrice:  gofakeit.Number(100, 10000),
-				NmID:        gofakeit.Number(1, 1000000),
-				Brand:       gofakeit.Company(),
-				Status:      202,
-			},
-		},
-		Locale:            "ru",
-		InternalSignature: "",
-		CustomerID:        gofakeit.UUID(),
-		DeliveryService:   "meest",
-		ShardKey:          "9",
-		SmID:              99,
-		DateCreated:       time.Now(),
-		OofShard:          "1",
+// SendTraced - отправляет сообщение синхронным продюсером внутри спана продюсера,
+// предварительно записав контекст трассировки в заголовки сообщения.
+// Все пути отправки в Kafka должны идти через эту функцию.
+func SendTraced(ctx context.Context, producer sarama.SyncProducer, message *sarama.ProducerMessage) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	tr := otel.Tracer("producer")
+	ctx, span := tr.Start(ctx, message.Topic+" publish",
+		trace.WithSpanKind(trace.SpanKindProducer))
+	defer span.End()
+
+	span.SetAttributes(
+		semconv.MessagingSystem("kafka"),
+		semconv.MessagingOperationPublish,
+		semconv.MessagingDestinationName(message.Topic),
+		semconv.MessagingDestinationKindTopic,
+	)
+	if message.Key != nil {
+		if key, err := message.Key.Encode(); err == nil {
+			span.SetAttributes(semconv.MessagingKafkaMessageKey(string(key)))
+		}
 	}
+	if message.Value != nil {
+		span.SetAttributes(semconv.MessagingMessagePayloadSizeBytes(message.Value.Length()))
+	}
+
+	otel.GetTextMapPropagator().Inject(ctx, NewProducerHeaderCarrier(message))
+
+	partition, offset, err := producer.SendMessage(message)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return err
+	}
+	span.SetAttributes(
+		semconv.MessagingKafkaDestinationPartition(int(partition)),
+		semconv.MessagingKafkaMessageOffset(int(offset)),
+	)
+	return nil
 }
 
 func (order *OrderProducer) Close() error {
@@ -0,0 +1,136 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"wb-project/internal/config"
+	"wb-project/internal/secret"
+
+	"github.com/IBM/sarama"
+	"github.com/xdg-go/scram"
+)
+
+// Security - TLS и SASL для подключения к брокерам. nil - открытый текст без аутентификации.
+type Security struct {
+	tls       *tls.Config
+	mechanism sarama.SASLMechanism
+	user      string
+	password  *secret.Value
+}
+
+// NewSecurity - параметры безопасности из конфигурации; password - пароль SASL,
+// файл которого перечитывается secret.Watch.
+func NewSecurity(conf config.KafkaConfig, password *secret.Value) (*Security, error) {
+	if !conf.TLSEnabled && conf.SASLMechanism == "" {
+		return nil, nil
+	}
+	sec := &Security{
+		mechanism: sarama.SASLMechanism(conf.SASLMechanism),
+		user:      conf.SASLUser,
+		password:  password,
+	}
+	if conf.TLSEnabled {
+		tlsConf, err := newTLSConfig(conf)
+		if err != nil {
+			return nil, err
+		}
+		sec.tls = tlsConf
+	}
+	return sec, nil
+}
+
+func newTLSConfig(conf config.KafkaConfig) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: conf.TLSInsecureSkipVerify, // только для отладки
+	}
+	if conf.TLSCAFile != "" {
+		pem, err := os.ReadFile(conf.TLSCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("CA Kafka: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("CA Kafka: в %s нет сертификатов", conf.TLSCAFile)
+		}
+		tlsConf.RootCAs = pool
+	}
+	if conf.TLSCertFile != "" {
+		// проверяем пару при старте, а читаем при каждом рукопожатии - так подхватывается ротация
+		if _, err := tls.LoadX509KeyPair(conf.TLSCertFile, conf.TLSKeyFile); err != nil {
+			return nil, fmt.Errorf("клиентский сертификат Kafka: %w", err)
+		}
+		tlsConf.GetClientCertificate = func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			cert, err := tls.LoadX509KeyPair(conf.TLSCertFile, conf.TLSKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("клиентский сертификат Kafka: %w", err)
+			}
+			return &cert, nil
+		}
+	}
+	return tlsConf, nil
+}
+
+// apply - включает TLS и SASL в конфигурации sarama.
+func (s *Security) apply(conf *sarama.Config) {
+	if s == nil {
+		return
+	}
+	if s.tls != nil {
+		conf.Net.TLS.Enable = true
+		conf.Net.TLS.Config = s.tls
+	}
+	if s.mechanism == "" {
+		return
+	}
+	conf.Net.SASL.Enable = true
+	conf.Net.SASL.Handshake = true
+	conf.Net.SASL.Mechanism = s.mechanism
+	conf.Net.SASL.User = s.user
+	// PLAIN берет пароль при создании клиента (поэтому файл пароля с ним запрещен
+	// валидацией конфигурации), SCRAM - из секрета на каждом соединении
+	conf.Net.SASL.Password = s.password.Get()
+	switch s.mechanism {
+	case sarama.SASLTypeSCRAMSHA256:
+		conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hash: scram.SHA256, password: s.password}
+		}
+	case sarama.SASLTypeSCRAMSHA512:
+		conf.Net.SASL.SCRAMClientGeneratorFunc = func() sarama.SCRAMClient {
+			return &scramClient{hash: scram.SHA512, password: s.password}
+		}
+	}
+}
+
+// newConfig - конфигурация sarama с параметрами безопасности.
+func newConfig(sec *Security) *sarama.Config {
+	conf := sarama.NewConfig()
+	sec.apply(conf)
+	return conf
+}
+
+// scramClient - SCRAM-диалог sarama поверх xdg-go/scram с текущим паролем из секрета.
+type scramClient struct {
+	hash     scram.HashGeneratorFcn
+	password *secret.Value
+	conv     *scram.ClientConversation
+}
+
+func (c *scramClient) Begin(userName, _, authzID string) error {
+	client, err := c.hash.NewClient(userName, c.password.Get(), authzID)
+	if err != nil {
+		return err
+	}
+	c.conv = client.NewConversation()
+	return nil
+}
+
+func (c *scramClient) Step(challenge string) (string, error) {
+	return c.conv.Step(challenge)
+}
+
+func (c *scramClient) Done() bool {
+	return c.conv.Done()
+}
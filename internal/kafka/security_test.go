@@ -0,0 +1,64 @@
+package kafka
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"wb-project/internal/config"
+	"wb-project/internal/secret"
+
+	"github.com/IBM/sarama"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSecurity(t *testing.T) {
+	t.Run("Без TLS и SASL конфигурация не меняется", func(t *testing.T) {
+		sec, err := NewSecurity(config.KafkaConfig{}, nil)
+
+		require.NoError(t, err)
+		assert.Nil(t, sec)
+		assert.NoError(t, newConfig(sec).Validate())
+	})
+
+	t.Run("SCRAM берет пароль из секрета на каждом соединении", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		path := filepath.Join(t.TempDir(), "kafka_password")
+		require.NoError(t, os.WriteFile(path, []byte("old"), 0o600))
+		password, err := secret.New("", path)
+		require.NoError(t, err)
+		sec, err := NewSecurity(config.KafkaConfig{
+			TLSEnabled:    true,
+			SASLMechanism: sarama.SASLTypeSCRAMSHA512,
+			SASLUser:      "orders",
+		}, password)
+		require.NoError(t, err)
+
+		//2. Act(Действие)
+		conf := newConfig(sec)
+		require.NoError(t, os.WriteFile(path, []byte("new"), 0o600))
+		_, _ = password.Refresh()
+		client := conf.Net.SASL.SCRAMClientGeneratorFunc()
+		require.NoError(t, client.Begin("orders", conf.Net.SASL.Password, ""))
+		first, err := client.Step("")
+
+		//3. Assert
+		require.NoError(t, err)
+		assert.NoError(t, conf.Validate())
+		assert.True(t, conf.Net.TLS.Enable)
+		assert.True(t, conf.Net.SASL.Enable)
+		assert.Equal(t, "old", conf.Net.SASL.Password)
+		assert.True(t, strings.HasPrefix(first, "n,,n=orders,r="), first)
+		assert.Equal(t, "new", client.(*scramClient).password.Get())
+	})
+
+	t.Run("Битый CA", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "ca.pem")
+		require.NoError(t, os.WriteFile(path, []byte("not a certificate"), 0o600))
+
+		_, err := NewSecurity(config.KafkaConfig{TLSEnabled: true, TLSCAFile: path}, nil)
+
+		assert.ErrorContains(t, err, "нет сертификатов")
+	})
+}
@@ -34,6 +34,20 @@ var (
 		Buckets:   prometheus.DefBuckets,
 	}, []string{"operation"}) // "save" или "get"
 
+	//2.3 Circuit breaker перед БД
+	BreakerState = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "order",
+		Subsystem: "breaker",
+		Name:      "state",
+		Help:      "Состояние circuit breaker: 0 - closed, 1 - half-open, 2 - open",
+	}, []string{"name"})
+	BreakerRejectedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "breaker",
+		Name:      "rejected_total",
+		Help:      "Вызовы, отклоненные разомкнутым circuit breaker",
+	}, []string{"name"})
+
 	//4.1 Размер кеша
 	CacheSize = promauto.NewGauge(prometheus.GaugeOpts{
 		Namespace: "order",
@@ -50,6 +64,162 @@ var (
 		Help:      "Текущее количество заказов в оперативной памяти",
 	}, []string{"result"}) //hit-нашли, miss-нет
 
+	//4.3 outbox: опубликованные события и ошибки публикации
+	OutboxEventsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "outbox",
+		Name:      "events_total",
+		Help:      "Количество опубликованных событий outbox и ошибок публикации",
+	}, []string{"status"}) // sent / error
+
+	//4.4 вебхуки: результаты попыток доставки и их длительность
+	WebhookDeliveriesTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "webhook",
+		Name:      "deliveries_total",
+		Help:      "Результаты попыток доставки вебхуков",
+	}, []string{"result"}) // delivered / retry / failed
+
+	WebhookDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "order",
+		Subsystem: "webhook",
+		Name:      "request_duration_seconds",
+		Help:      "Время ответа подписчиков вебхуков",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	//4.5 SSE-стрим заказов
+	StreamSubscribers = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "order",
+		Subsystem: "stream",
+		Name:      "subscribers",
+		Help:      "Количество подключенных клиентов стрима заказов",
+	})
+
+	StreamDroppedTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "stream",
+		Name:      "dropped_subscribers_total",
+		Help:      "Сколько медленных клиентов было отключено",
+	})
+
+	//4.6 пакетное получение заказов
+	BatchSize = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "order",
+		Subsystem: "batch",
+		Name:      "size",
+		Help:      "Количество уникальных UID в одном пакетном запросе",
+		Buckets:   []float64{1, 5, 10, 25, 50, 100, 250, 500},
+	})
+
+	BatchOrdersTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "batch",
+		Name:      "orders_total",
+		Help:      "Заказы пакетных запросов по источнику",
+	}, []string{"source"}) // cache / db / missing
+
+	BatchDuration = promauto.NewHistogram(prometheus.HistogramOpts{
+		Namespace: "order",
+		Subsystem: "batch",
+		Name:      "duration_seconds",
+		Help:      "Время обработки пакетного запроса",
+		Buckets:   prometheus.DefBuckets,
+	})
+
+	//4.7 аутентификация и авторизация
+	AuthAttemptsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "auth",
+		Name:      "attempts_total",
+		Help:      "Результаты аутентификации и проверки ролей",
+	}, []string{"method", "result"}) // api_key / jwt / none; success / missing / invalid / expired / forbidden
+
+	//4.8 доступ к персональным данным
+	PIIUnmaskedTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "pii",
+		Name:      "unmasked_total",
+		Help:      "Запросы персональных данных без маскирования",
+	}, []string{"channel", "role"}) // http / grpc
+
+	//4.9 шифрование персональных данных
+	ReencryptedRowsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "encryption",
+		Name:      "reencrypted_rows_total",
+		Help:      "Строки deliveries, перешифрованные основным ключом",
+	}, []string{"status"}) // success / error
+
+	//4.10 ограничение нагрузки
+	RateLimitDecisionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "ratelimit",
+		Name:      "decisions_total",
+		Help:      "Решения ограничителей HTTP API",
+	}, []string{"limiter", "tier", "decision"}) // rate / inflight / auth; allowed / limited
+
+	InFlightRequests = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "order",
+		Subsystem: "ratelimit",
+		Name:      "inflight_requests",
+		Help:      "Запросы к БД, выполняющиеся одновременно",
+	})
+
+	//4.11 состояние зависимостей по результатам readiness-проверок
+	HealthComponentUp = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: "order",
+		Subsystem: "health",
+		Name:      "component_up",
+		Help:      "Результат последней проверки компонента: 1 - доступен, 0 - нет",
+	}, []string{"component"})
+
+	HealthCheckDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: "order",
+		Subsystem: "health",
+		Name:      "check_duration_seconds",
+		Help:      "Время проверки компонента в readiness",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"component"})
+
+	//4.12 действия через admin API
+	AdminActionsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "admin",
+		Name:      "actions_total",
+		Help:      "Действия через admin API",
+	}, []string{"action", "result"}) // result: success / error
+
+	AdminAuditErrorsTotal = promauto.NewCounter(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "admin",
+		Name:      "audit_errors_total",
+		Help:      "Действия admin API, которые не удалось записать в журнал аудита",
+	})
+
+	// конфигурация: номер примененной версии растет при каждой успешной перезагрузке
+	ConfigVersion = promauto.NewGauge(prometheus.GaugeOpts{
+		Namespace: "order",
+		Subsystem: "config",
+		Name:      "version",
+		Help:      "Версия примененной конфигурации, 1 - загруженная при старте",
+	})
+
+	ConfigReloadsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "config",
+		Name:      "reloads_total",
+		Help:      "Попытки перезагрузки конфигурации",
+	}, []string{"result"}) // applied / unchanged / rejected
+
+	// перезапуски упавших компонентов супервизором
+	ComponentRestartsTotal = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: "order",
+		Subsystem: "supervisor",
+		Name:      "restarts_total",
+		Help:      "Перезапуски упавших компонентов",
+	}, []string{"component"})
+
 	//5 запросы
 	RequestMetrics = promauto.NewSummaryVec(prometheus.SummaryOpts{
 		Namespace:  "order",
@@ -62,3 +232,12 @@ var (
 func ObserveRequest(t time.Duration, status int) {
 	RequestMetrics.WithLabelValues(strconv.Itoa(status)).Observe(t.Seconds())
 }
+
+// ObserveBatch - метрики одного пакетного запроса.
+func ObserveBatch(size, fromCache, fromDB, missing int, t time.Duration) {
+	BatchSize.Observe(float64(size))
+	BatchOrdersTotal.WithLabelValues("cache").Add(float64(fromCache))
+	BatchOrdersTotal.WithLabelValues("db").Add(float64(fromDB))
+	BatchOrdersTotal.WithLabelValues("missing").Add(float64(missing))
+	BatchDuration.Observe(t.Seconds())
+}
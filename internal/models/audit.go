@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// PIIAccess - запись журнала доступа к персональным данным без маскирования.
+type PIIAccess struct {
+	Subject   string    // кто запросил (имя ключа или sub токена)
+	Role      string    // роль на момент запроса
+	Channel   string    // http / grpc
+	Operation string    // маршрут или gRPC-метод
+	OrderUIDs []string  // заказы, данные которых были раскрыты
+	Reason    string    // основание доступа из X-Access-Reason / x-access-reason
+	At        time.Time // время доступа
+}
+
+// AdminAction - запись журнала действий через admin API.
+type AdminAction struct {
+	Subject string            // кто выполнил
+	Role    string            // роль на момент запроса
+	Action  string            // например cache.flush, consumer.seek
+	Target  string            // UID заказа, топик и т.п., пусто - весь объект
+	Params  map[string]string // параметры действия
+	Result  string            // success / error
+	Error   string            // текст ошибки при Result=error
+	At      time.Time
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"wb-project/internal/pii"
+)
+
+// EncodedOrder - заказ вместе с каноничным JSON и сильным ETag.
+// Кэш хранит заказы в этом виде, чтобы не сериализовать их на каждый запрос.
+type EncodedOrder struct {
+	Order *Order
+	JSON  []byte
+	ETag  string // в кавычках, готов для заголовка ETag
+
+	// Masked - то же с замаскированными персональными данными: его отдают по умолчанию.
+	Masked *EncodedOrder
+}
+
+// EncodeOrder - сериализует заказ и вычисляет ETag по полученным байтам.
+// Маскированное представление готовится сразу, чтобы ответ по умолчанию тоже не требовал сериализации.
+func EncodeOrder(order *Order) (EncodedOrder, error) {
+	encoded, err := encode(order)
+	if err != nil {
+		return EncodedOrder{}, err
+	}
+	masked := pii.Redact(*order)
+	m, err := encode(&masked)
+	if err != nil {
+		return EncodedOrder{}, err
+	}
+	encoded.Masked = &m
+	return encoded, nil
+}
+
+func encode(order *Order) (EncodedOrder, error) {
+	data, err := json.Marshal(order)
+	if err != nil {
+		return EncodedOrder{}, err
+	}
+	sum := sha256.Sum256(data)
+	return EncodedOrder{
+		Order: order,
+		JSON:  data,
+		ETag:  `"` + base64.RawURLEncoding.EncodeToString(sum[:16]) + `"`,
+	}, nil
+}
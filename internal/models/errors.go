@@ -0,0 +1,18 @@
+package models
+
+import "errors"
+
+// ErrNotFound возвращается хранилищами, когда запрошенная запись отсутствует.
+// Слои выше проверяют его через errors.Is, чтобы отличить "нет данных" от сбоя.
+var ErrNotFound = errors.New("запись не найдена")
+
+// ErrAlreadyExists - запись с таким ключом уже сохранена (повторная доставка сообщения).
+var ErrAlreadyExists = errors.New("запись уже существует")
+
+// ErrUnreadable - запись есть, но прочитать ее не удается (например, не расшифровывается).
+// Повтор не поможет, и сбоем хранилища это не считается.
+var ErrUnreadable = errors.New("запись не читается")
+
+// ErrUnavailable - хранилище не ответило вовремя или временно не принимает запросы.
+// В отличие от прочих сбоев запрос имеет смысл повторить позже.
+var ErrUnavailable = errors.New("хранилище временно недоступно")
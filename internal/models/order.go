@@ -24,14 +24,15 @@ type Order struct {
 }
 
 // Delivery содержит контактные данные получателя и адрес доставки заказа.
+// Поля с тегом pii маскируются в ответах API, логах и трейсах (см. internal/pii).
 type Delivery struct {
-	Name    string `json:"name" validate:"required,min=2"`
-	Phone   string `json:"phone" validate:"required,e164"`
+	Name    string `json:"name" validate:"required,min=2" pii:"name"`
+	Phone   string `json:"phone" validate:"required,e164" pii:"phone"`
 	Zip     string `json:"zip" validate:"required,numeric"`
 	City    string `json:"city" validate:"required"`
-	Address string `json:"address" validate:"required"`
+	Address string `json:"address" validate:"required" pii:"address"`
 	Region  string `json:"region" validate:"required"`
-	Email   string `json:"email" validate:"required,email"`
+	Email   string `json:"email" validate:"required,email" pii:"email"`
 }
 
 // Payment описывает финансовые параметры транзакции, включая информацию о банке,
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Типы доменных событий, публикуемых через outbox.
+const (
+	EventOrderStored = "order.stored"
+
+	// OrderEventSchemaVersion - версия схемы payload событий о заказе.
+	OrderEventSchemaVersion = 1
+)
+
+// OutboxEvent - запись transactional outbox: событие, сохраненное в одной
+// транзакции с заказом и ожидающее публикации в Kafka.
+type OutboxEvent struct {
+	ID            int64
+	AggregateID   string // order_uid, используется как ключ сообщения
+	EventType     string
+	SchemaVersion int
+	Payload       []byte            // JSON заказа, собирается релеем при публикации и в outbox не хранится
+	Headers       map[string]string // контекст трассировки на момент сохранения
+	CreatedAt     time.Time
+	Attempts      int
+}
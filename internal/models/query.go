@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+)
+
+// OrderListQuery - параметры постраничной выборки заказов (от новых к старым).
+type OrderListQuery struct {
+	Limit           int
+	After           *OrderCursor // продолжить после этого заказа, nil - с начала
+	Entry           string
+	DeliveryService string
+	CustomerID      string
+	Phone           string // точное совпадение телефона получателя (через слепой индекс)
+	Email           string // точное совпадение email получателя (через слепой индекс)
+}
+
+// OrderCursor - позиция в выборке: ключ сортировки последнего отданного заказа.
+type OrderCursor struct {
+	DateCreated time.Time
+	OrderUID    string
+}
+
+// OrderPage - страница заказов и курсор следующей страницы (nil, если страница последняя).
+type OrderPage struct {
+	Orders []Order
+	Next   *OrderCursor
+}
+
+// Token - непрозрачное представление курсора для клиентов API.
+func (c OrderCursor) Token() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.DateCreated.Format(time.RFC3339Nano) + "|" + c.OrderUID))
+}
+
+// ParseOrderCursor - разбирает токен страницы, пустой токен означает первую страницу.
+func ParseOrderCursor(token string) (*OrderCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, errors.New("некорректный токен страницы")
+	}
+	ts, uid, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, errors.New("некорректный токен страницы")
+	}
+	dateCreated, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, errors.New("некорректный токен страницы")
+	}
+	return &OrderCursor{DateCreated: dateCreated, OrderUID: uid}, nil
+}
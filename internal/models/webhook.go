@@ -0,0 +1,74 @@
+package models
+
+import "time"
+
+// Типы событий, на которые можно подписать вебхук.
+const (
+	WebhookOrderCreated = "order.created"
+	// WebhookOrderUpdated - заказ с уже сохраненным order_uid пришел повторно с другим содержимым.
+	WebhookOrderUpdated = "order.updated"
+	// WebhookPing отправляется только в тестовом режиме и не сохраняется в журнал.
+	WebhookPing = "ping"
+)
+
+// Статусы доставки вебхука.
+const (
+	DeliveryPending   = "pending"
+	DeliveryDelivered = "delivered"
+	DeliveryFailed    = "failed" // исчерпаны попытки или подписка отключена
+)
+
+// WebhookSubscription - подписчик, которому отправляются HTTP callback'и о заказах.
+type WebhookSubscription struct {
+	ID                  int64     `json:"id"`
+	URL                 string    `json:"url" validate:"required,url"`
+	Secret              string    `json:"secret,omitempty"`
+	Events              []string  `json:"events" validate:"required,gt=0,dive,oneof=order.created order.updated"`
+	Enabled             bool      `json:"enabled"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+	DisabledReason      string    `json:"disabled_reason,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+	UpdatedAt           time.Time `json:"updated_at"`
+}
+
+// WebhookEvent - тело запроса, которое получает подписчик.
+type WebhookEvent struct {
+	Event      string    `json:"event"`
+	OrderUID   string    `json:"order_uid"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Order      *Order    `json:"order,omitempty"`
+}
+
+// WebhookDelivery - доставка одного события одному подписчику.
+type WebhookDelivery struct {
+	ID             int64      `json:"id"`
+	SubscriptionID int64      `json:"subscription_id"`
+	EventType      string     `json:"event_type"`
+	OrderUID       string     `json:"order_uid"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	NextAttemptAt  time.Time  `json:"next_attempt_at"`
+	LastStatusCode int        `json:"last_status_code,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// WebhookAttempt - результат одной попытки доставки.
+type WebhookAttempt struct {
+	DeliveryID int64         `json:"delivery_id"`
+	Attempt    int           `json:"attempt"`
+	StatusCode int           `json:"status_code,omitempty"`
+	Error      string        `json:"error,omitempty"`
+	Duration   time.Duration `json:"duration"`
+	// NotSent - запрос подписчику не отправлялся (не удалось собрать тело),
+	// такая неудача не засчитывается подписке.
+	NotSent bool `json:"-"`
+}
+
+// WebhookTask - доставка вместе с адресом и секретом подписчика, взятая в работу диспетчером.
+type WebhookTask struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
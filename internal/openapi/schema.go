@@ -0,0 +1,169 @@
+package openapi
+
+import (
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeType = reflect.TypeFor[time.Time]()
+
+// Register - выводит схему типа v и всех вложенных структур, кладет их в components
+// под именами Go-типов и возвращает ссылку на схему v.
+func (d *Document) Register(v any) *Schema {
+	if d.Components.Schemas == nil {
+		d.Components.Schemas = make(map[string]*Schema)
+	}
+	return d.schemaOf(reflect.TypeOf(v))
+}
+
+func (d *Document) schemaOf(t reflect.Type) *Schema {
+	switch {
+	case t == timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	case t.Kind() == reflect.Pointer:
+		s := d.schemaOf(t.Elem())
+		s.Nullable = true
+		return s
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return ArrayOf(d.schemaOf(t.Elem()))
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: d.schemaOf(t.Elem())}
+	case reflect.Struct:
+		name := t.Name()
+		if _, ok := d.Components.Schemas[name]; !ok {
+			d.Components.Schemas[name] = &Schema{} // защита от рекурсии
+			d.Components.Schemas[name] = d.structSchema(t)
+		}
+		return Ref(name)
+	default:
+		return &Schema{}
+	}
+}
+
+func (d *Document) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		prop := d.schemaOf(f.Type)
+		required := applyValidateTag(prop, f.Tag.Get("validate"))
+		if kind := f.Tag.Get("pii"); kind != "" {
+			// маскированное значение ("+79*******67") не проходит проверку формата
+			*prop = Schema{Type: prop.Type, Description: "Персональные данные (" + kind + "): маскируются, если не запрошен view=unmasked"}
+		}
+		// обязательными в ответе считаем поля без omitempty: encoding/json выводит их всегда
+		if required || !strings.Contains(opts, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+		s.Properties[name] = prop
+	}
+	return s
+}
+
+// applyValidateTag - переносит правила go-playground/validator в ограничения схемы.
+// Возвращает true, если поле помечено required.
+func applyValidateTag(s *Schema, tag string) bool {
+	if tag == "" {
+		return false
+	}
+	rules := strings.Split(tag, ",")
+	required := false
+	target := s
+	for _, rule := range rules {
+		name, param, _ := strings.Cut(rule, "=")
+		switch name {
+		case "dive":
+			// правила после dive относятся к элементам массива,
+			// у структур они описаны в собственной схеме
+			if target.Items == nil || target.Items.Ref != "" {
+				return required
+			}
+			target = target.Items
+		case "required":
+			required = true
+		case "email":
+			target.Format = "email"
+		case "url":
+			target.Format = "uri"
+		case "e164":
+			target.Pattern = `^\+[1-9]\d{1,14}$`
+		case "numeric":
+			target.Pattern = `^[-+]?[0-9]+(?:\.[0-9]+)?$`
+		case "oneof":
+			for _, v := range strings.Fields(param) {
+				target.Enum = append(target.Enum, v)
+			}
+		case "len", "min", "max", "gt", "gte":
+			applyBound(target, name, param)
+		}
+	}
+	return required
+}
+
+func applyBound(s *Schema, rule, param string) {
+	n, err := strconv.Atoi(param)
+	if err != nil {
+		return
+	}
+	f := float64(n)
+	switch s.Type {
+	case "string":
+		switch rule {
+		case "len":
+			s.MinLength, s.MaxLength = &n, &n
+		case "min", "gte":
+			s.MinLength = &n
+		case "max":
+			s.MaxLength = &n
+		case "gt":
+			m := n + 1
+			s.MinLength = &m
+		}
+	case "array":
+		switch rule {
+		case "len":
+			s.MinItems, s.MaxItems = &n, &n
+		case "min", "gte":
+			s.MinItems = &n
+		case "max":
+			s.MaxItems = &n
+		case "gt":
+			m := n + 1
+			s.MinItems = &m
+		}
+	case "integer", "number":
+		switch rule {
+		case "min", "gte":
+			s.Minimum = &f
+		case "gt":
+			s.ExclusiveMinimum = &f
+		}
+	}
+}
@@ -0,0 +1,124 @@
+// Package openapi описывает API сервиса в формате OpenAPI 3.1.
+// Схемы выводятся из Go-структур (json и validate теги), поэтому документ
+// не расходится с моделями, а Validate позволяет проверять ответы в тестах.
+package openapi
+
+// Document - корневой объект OpenAPI.
+type Document struct {
+	OpenAPI    string               `json:"openapi"`
+	Info       Info                 `json:"info"`
+	Servers    []Server             `json:"servers,omitempty"`
+	Paths      map[string]*PathItem `json:"paths"`
+	Components Components           `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Version     string `json:"version"`
+	Description string `json:"description,omitempty"`
+}
+
+type Server struct {
+	URL string `json:"url"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema         `json:"schemas"`
+	SecuritySchemes map[string]*SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+	In           string `json:"in,omitempty"`
+	Name         string `json:"name,omitempty"`
+}
+
+// PathItem - операции одного пути, ключ - метод в нижнем регистре.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	OperationID string                `json:"operationId"`
+	Summary     string                `json:"summary,omitempty"`
+	Description string                `json:"description,omitempty"`
+	Tags        []string              `json:"tags,omitempty"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name        string  `json:"name"`
+	In          string  `json:"in"` // path, query, header
+	Required    bool    `json:"required,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required,omitempty"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Headers     map[string]Header    `json:"headers,omitempty"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type Header struct {
+	Description string  `json:"description,omitempty"`
+	Schema      *Schema `json:"schema"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Schema - подмножество JSON Schema, которого достаточно для моделей сервиса.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	ExclusiveMinimum     *float64           `json:"exclusiveMinimum,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Nullable             bool               `json:"-"` // указатели: null допустим при валидации
+}
+
+// JSON - ссылка на media type application/json со схемой s.
+func JSON(s *Schema) map[string]MediaType {
+	return map[string]MediaType{"application/json": {Schema: s}}
+}
+
+// Ref - ссылка на схему из components.
+func Ref(name string) *Schema {
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+// ArrayOf - массив элементов s.
+func ArrayOf(s *Schema) *Schema {
+	return &Schema{Type: "array", Items: s}
+}
+
+// PathParam - обязательный строковый параметр пути.
+func PathParam(name, description string) Parameter {
+	return Parameter{Name: name, In: "path", Required: true, Description: description, Schema: &Schema{Type: "string"}}
+}
+
+// QueryParam - необязательный параметр строки запроса.
+func QueryParam(name, description string, schema *Schema) Parameter {
+	return Parameter{Name: name, In: "query", Description: description, Schema: schema}
+}
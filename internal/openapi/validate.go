@@ -0,0 +1,157 @@
+package openapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// Validate - проверяет JSON-значение data на соответствие схеме s.
+// Поддерживается то же подмножество JSON Schema, которое генерирует Register.
+func (d *Document) Validate(s *Schema, data []byte) error {
+	var v any
+	if err := json.Unmarshal(data, &v); err != nil {
+		return fmt.Errorf("некорректный JSON: %w", err)
+	}
+	return d.validate(s, v, "$")
+}
+
+func (d *Document) resolve(s *Schema) (*Schema, error) {
+	for s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		ref, ok := d.Components.Schemas[name]
+		if !ok {
+			return nil, fmt.Errorf("неизвестная схема %q", s.Ref)
+		}
+		s = ref
+	}
+	return s, nil
+}
+
+func (d *Document) validate(s *Schema, v any, path string) error {
+	s, err := d.resolve(s)
+	if err != nil {
+		return err
+	}
+	if v == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return fmt.Errorf("%s: null вместо %s", path, s.Type)
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+		return fmt.Errorf("%s: значение %v не из %v", path, v, s.Enum)
+	}
+
+	switch s.Type {
+	case "object":
+		obj, ok := v.(map[string]any)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: нет обязательного поля %q", path, name)
+			}
+		}
+		for name, val := range obj {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil {
+					prop = s.AdditionalProperties
+				} else if len(s.Properties) > 0 {
+					return fmt.Errorf("%s: поле %q не описано в схеме", path, name)
+				} else {
+					continue
+				}
+			}
+			if err := d.validate(prop, val, path+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		arr, ok := v.([]any)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		if s.MinItems != nil && len(arr) < *s.MinItems {
+			return fmt.Errorf("%s: элементов меньше %d", path, *s.MinItems)
+		}
+		if s.MaxItems != nil && len(arr) > *s.MaxItems {
+			return fmt.Errorf("%s: элементов больше %d", path, *s.MaxItems)
+		}
+		if s.Items != nil {
+			for i, item := range arr {
+				if err := d.validate(s.Items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case "string":
+		str, ok := v.(string)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		return validateString(s, str, path)
+	case "integer", "number":
+		n, ok := v.(float64)
+		if !ok {
+			return typeError(path, s.Type, v)
+		}
+		if s.Type == "integer" && n != float64(int64(n)) {
+			return fmt.Errorf("%s: %v не целое", path, n)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %v меньше %v", path, n, *s.Minimum)
+		}
+		if s.ExclusiveMinimum != nil && n <= *s.ExclusiveMinimum {
+			return fmt.Errorf("%s: %v должно быть больше %v", path, n, *s.ExclusiveMinimum)
+		}
+	case "boolean":
+		if _, ok := v.(bool); !ok {
+			return typeError(path, s.Type, v)
+		}
+	}
+	return nil
+}
+
+func validateString(s *Schema, str, path string) error {
+	n := len([]rune(str))
+	if s.MinLength != nil && n < *s.MinLength {
+		return fmt.Errorf("%s: длина меньше %d", path, *s.MinLength)
+	}
+	if s.MaxLength != nil && n > *s.MaxLength {
+		return fmt.Errorf("%s: длина больше %d", path, *s.MaxLength)
+	}
+	if s.Pattern != "" {
+		re, err := regexp.Compile(s.Pattern)
+		if err != nil {
+			return fmt.Errorf("%s: некорректный pattern: %w", path, err)
+		}
+		if !re.MatchString(str) {
+			return fmt.Errorf("%s: %q не соответствует %s", path, str, s.Pattern)
+		}
+	}
+	var err error
+	switch s.Format {
+	case "date-time":
+		_, err = time.Parse(time.RFC3339Nano, str)
+	case "email":
+		_, err = mail.ParseAddress(str)
+	case "uri":
+		_, err = url.ParseRequestURI(str)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %q не в формате %s", path, str, s.Format)
+	}
+	return nil
+}
+
+func typeError(path, want string, v any) error {
+	return fmt.Errorf("%s: ожидался %s, получено %T", path, want, v)
+}
@@ -0,0 +1,87 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "wb-project/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// DeleteSentBefore provides a mock function with given fields: ctx, before
+func (_m *Store) DeleteSentBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSentBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Relay provides a mock function with given fields: ctx, limit, publish
+func (_m *Store) Relay(ctx context.Context, limit int, publish func(context.Context, models.OutboxEvent) error) (int, error) {
+	ret := _m.Called(ctx, limit, publish)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Relay")
+	}
+
+	var r0 int
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, func(context.Context, models.OutboxEvent) error) (int, error)); ok {
+		return rf(ctx, limit, publish)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, func(context.Context, models.OutboxEvent) error) int); ok {
+		r0 = rf(ctx, limit, publish)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, func(context.Context, models.OutboxEvent) error) error); ok {
+		r1 = rf(ctx, limit, publish)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
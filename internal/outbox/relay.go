@@ -0,0 +1,132 @@
+// Package outbox содержит релей transactional outbox: фоновый воркер,
+// который публикует сохраненные в БД события в Kafka и чистит отправленные.
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+)
+
+// Store - хранилище событий outbox.
+//
+//go:generate mockery --name=Store --output=./mocks --case=underscore
+type Store interface {
+	Relay(ctx context.Context, limit int, publish func(context.Context, models.OutboxEvent) error) (int, error)
+	DeleteSentBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Publisher - получатель событий (топик событий в Kafka).
+//
+//go:generate mockery --name=Publisher --output=./mocks --case=underscore
+type Publisher interface {
+	Publish(ctx context.Context, event models.OutboxEvent) error
+}
+
+// Orders - источник заказов для payload: в outbox хранится только order_uid,
+// а не копия заказа с персональными данными.
+//
+//go:generate mockery --name=Orders --output=./mocks --case=underscore
+type Orders interface {
+	Get(ctx context.Context, uid string) (models.Order, error)
+}
+
+// Relay периодически переносит события из outbox в Publisher.
+type Relay struct {
+	store     Store
+	orders    Orders
+	publisher Publisher
+	cfg       config.OutboxConfig
+}
+
+func NewRelay(store Store, orders Orders, publisher Publisher, cfg config.OutboxConfig) *Relay {
+	return &Relay{store: store, orders: orders, publisher: publisher, cfg: cfg}
+}
+
+// Run - работает до отмены ctx. Пока батчи заполнены целиком, следующий берется сразу;
+// после ошибки релей ждет с экспоненциальной задержкой, чтобы не долбить упавший брокер.
+func (r *Relay) Run(ctx context.Context) error {
+	slog.Info("Запуск outbox релея",
+		slog.Duration("poll_interval", r.cfg.PollInterval),
+		slog.Int("batch_size", r.cfg.BatchSize))
+
+	cleanup := time.NewTicker(r.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	failures := 0
+	for {
+		wait := r.cfg.PollInterval
+		sent, err := r.RelayOnce(ctx)
+		switch {
+		case err != nil:
+			failures++
+			wait = r.backoff(failures)
+			slog.Error("ошибка публикации событий outbox",
+				slog.Any("error", err),
+				slog.Int("sent", sent),
+				slog.Duration("retry_in", wait))
+		case sent == r.cfg.BatchSize:
+			failures = 0
+			wait = 0
+		default:
+			failures = 0
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-cleanup.C:
+			timer.Stop()
+			r.cleanup(ctx)
+		case <-timer.C:
+		}
+	}
+}
+
+// RelayOnce - публикует один батч событий, возвращает количество отправленных.
+func (r *Relay) RelayOnce(ctx context.Context) (int, error) {
+	sent, err := r.store.Relay(ctx, r.cfg.BatchSize, r.publish)
+	metric.OutboxEventsTotal.WithLabelValues("sent").Add(float64(sent))
+	if err != nil {
+		metric.OutboxEventsTotal.WithLabelValues("error").Inc()
+	}
+	return sent, err
+}
+
+// publish - собирает payload из заказа и передает событие в Publisher.
+func (r *Relay) publish(ctx context.Context, event models.OutboxEvent) error {
+	order, err := r.orders.Get(ctx, event.AggregateID)
+	if err != nil {
+		return fmt.Errorf("заказ %s: %w", event.AggregateID, err)
+	}
+	if event.Payload, err = json.Marshal(order); err != nil {
+		return err
+	}
+	return r.publisher.Publish(ctx, event)
+}
+
+func (r *Relay) cleanup(ctx context.Context) {
+	deleted, err := r.store.DeleteSentBefore(ctx, time.Now().Add(-r.cfg.Retention))
+	if err != nil {
+		slog.Error("ошибка очистки outbox", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("outbox: удалены отправленные события", slog.Int64("count", deleted))
+	}
+}
+
+func (r *Relay) backoff(failures int) time.Duration {
+	wait := r.cfg.PollInterval
+	for i := 1; i < failures && wait < r.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, r.cfg.MaxBackoff)
+}
@@ -0,0 +1,98 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/models"
+	"wb-project/internal/outbox/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setup(t *testing.T) (*mocks.Store, *mocks.Orders, *mocks.Publisher, *Relay) {
+	store := mocks.NewStore(t)
+	orders := mocks.NewOrders(t)
+	publisher := mocks.NewPublisher(t)
+	relay := NewRelay(store, orders, publisher, config.OutboxConfig{
+		PollInterval:    100 * time.Millisecond,
+		BatchSize:       10,
+		MaxBackoff:      time.Second,
+		Retention:       time.Hour,
+		CleanupInterval: time.Minute,
+	})
+	return store, orders, publisher, relay
+}
+
+// События из хранилища передаются в Publisher с payload, собранным из заказа.
+func TestRelay_RelayOnce_Success(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, publisher, relay := setup(t)
+	event := models.OutboxEvent{ID: 1, AggregateID: "uid", EventType: models.EventOrderStored}
+	order := models.Order{OrderUID: "uid", TrackNumber: "WBILMTESTTRACK"}
+	payload, err := json.Marshal(order)
+	assert.NoError(t, err)
+
+	orders.On("Get", mock.Anything, "uid").Return(order, nil)
+	published := event
+	published.Payload = payload
+	publisher.On("Publish", mock.Anything, published).Return(nil)
+	store.On("Relay", mock.Anything, 10, mock.Anything).
+		Return(func(ctx context.Context, _ int, publish func(context.Context, models.OutboxEvent) error) (int, error) {
+			return 1, publish(ctx, event)
+		})
+
+	//2. Act(Действие)
+	sent, err := relay.RelayOnce(context.Background())
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, sent)
+	publisher.AssertExpectations(t)
+}
+
+// Ошибка публикации возвращается вызывающему, чтобы релей ушел в backoff.
+func TestRelay_RelayOnce_PublishError(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, _, _, relay := setup(t)
+	store.On("Relay", mock.Anything, 10, mock.Anything).Return(0, errors.New("broker down"))
+
+	//2. Act(Действие)
+	sent, err := relay.RelayOnce(context.Background())
+
+	//3. Assert
+	assert.Error(t, err)
+	assert.Equal(t, 0, sent)
+}
+
+// Без заказа событие не публикуется, а ошибка уходит в хранилище как неудачная попытка.
+func TestRelay_RelayOnce_OrderUnavailable(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, _, relay := setup(t)
+	event := models.OutboxEvent{ID: 1, AggregateID: "uid", EventType: models.EventOrderStored}
+
+	orders.On("Get", mock.Anything, "uid").Return(models.Order{}, errors.New("connection refused"))
+	store.On("Relay", mock.Anything, 10, mock.Anything).
+		Return(func(ctx context.Context, _ int, publish func(context.Context, models.OutboxEvent) error) (int, error) {
+			return 0, publish(ctx, event)
+		})
+
+	//2. Act(Действие)
+	sent, err := relay.RelayOnce(context.Background())
+
+	//3. Assert
+	assert.ErrorContains(t, err, "заказ uid: connection refused")
+	assert.Equal(t, 0, sent)
+}
+
+func TestRelay_Backoff(t *testing.T) {
+	_, _, _, relay := setup(t)
+
+	assert.Equal(t, 100*time.Millisecond, relay.backoff(1))
+	assert.Equal(t, 400*time.Millisecond, relay.backoff(3))
+	assert.Equal(t, time.Second, relay.backoff(10))
+}
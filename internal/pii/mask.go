@@ -0,0 +1,238 @@
+// Package pii - политика маскирования персональных данных. Поля помечаются
+// тегом `pii:"<вид>"`, а маскирование применяется к ответам API, записям slog
+// и атрибутам спанов.
+package pii
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"unicode/utf8"
+)
+
+// Виды персональных данных, значения тега pii.
+const (
+	KindName    = "name"
+	KindPhone   = "phone"
+	KindEmail   = "email"
+	KindAddress = "address"
+)
+
+const hidden = "***"
+
+// Mask - частично скрывает значение в зависимости от вида: остается ровно
+// столько, чтобы оператор мог сверить данные с клиентом.
+func Mask(kind, value string) string {
+	if value == "" {
+		return ""
+	}
+	switch kind {
+	case KindPhone:
+		return maskPhone(value)
+	case KindEmail:
+		return maskEmail(value)
+	case KindName:
+		words := strings.Fields(value)
+		for i, w := range words {
+			r, _ := utf8.DecodeRuneInString(w)
+			words[i] = string(r) + hidden
+		}
+		return strings.Join(words, " ")
+	default:
+		return hidden
+	}
+}
+
+// +79991234567 -> +79*******67
+func maskPhone(phone string) string {
+	digits := 0
+	for _, r := range phone {
+		if r >= '0' && r <= '9' {
+			digits++
+		}
+	}
+	if digits < 6 {
+		return hidden
+	}
+	var b strings.Builder
+	seen := 0
+	for _, r := range phone {
+		if r < '0' || r > '9' {
+			b.WriteRune(r)
+			continue
+		}
+		seen++
+		if seen <= 2 || seen > digits-2 {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('*')
+		}
+	}
+	return b.String()
+}
+
+// ivan@example.com -> i***@example.com
+func maskEmail(email string) string {
+	local, domain, ok := strings.Cut(email, "@")
+	if !ok || local == "" {
+		return hidden
+	}
+	r, _ := utf8.DecodeRuneInString(local)
+	return string(r) + hidden + "@" + domain
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+\d[\d\-\s()]{8,}\d`)
+)
+
+// Scrub - маскирует email и телефоны, встреченные в произвольном тексте
+// (сообщения об ошибках, текст логов).
+func Scrub(text string) string {
+	if !strings.ContainsAny(text, "@+") {
+		return text
+	}
+	text = emailPattern.ReplaceAllStringFunc(text, maskEmail)
+	return phonePattern.ReplaceAllStringFunc(text, maskPhone)
+}
+
+// Redact - копия v, в которой все поля с тегом pii замаскированы.
+// Исходное значение не меняется.
+func Redact[T any](v T) T {
+	rv := reflect.ValueOf(&v).Elem()
+	if containsPII(rv.Type()) {
+		rv.Set(redactValue(rv))
+	}
+	return v
+}
+
+// RedactAny - Redact для значения неизвестного типа; ok=false, если маскировать нечего.
+func RedactAny(v any) (any, bool) {
+	if v == nil || !containsPII(reflect.TypeOf(v)) {
+		return v, false
+	}
+	return redactValue(reflect.ValueOf(v)).Interface(), true
+}
+
+var piiTypes sync.Map // reflect.Type -> bool
+
+// containsPII - есть ли в типе (в том числе во вложенных) поля с тегом pii.
+func containsPII(t reflect.Type) bool {
+	if cached, ok := piiTypes.Load(t); ok {
+		return cached.(bool)
+	}
+	piiTypes.Store(t, false) // защита от рекурсивных типов
+	found := false
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		found = containsPII(t.Elem())
+	case reflect.Map:
+		found = containsPII(t.Elem())
+	case reflect.Struct:
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			if f.Tag.Get("pii") != "" || containsPII(f.Type) {
+				found = true
+				break
+			}
+		}
+	}
+	piiTypes.Store(t, found)
+	return found
+}
+
+// redactValue - новая (не разделяющая память с v) версия значения с маскированием.
+func redactValue(v reflect.Value) reflect.Value {
+	t := v.Type()
+	if !containsPII(t) {
+		return v
+	}
+	switch t.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(t.Elem())
+		out.Elem().Set(redactValue(v.Elem()))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		return redactValue(v.Elem())
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(t, v.Len(), v.Len())
+		for i := range v.Len() {
+			out.Index(i).Set(redactValue(v.Index(i)))
+		}
+		return out
+	case reflect.Array:
+		out := reflect.New(t).Elem()
+		for i := range v.Len() {
+			out.Index(i).Set(redactValue(v.Index(i)))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(t, v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), redactValue(iter.Value()))
+		}
+		return out
+	case reflect.Struct:
+		out := reflect.New(t).Elem()
+		out.Set(v)
+		for i := range t.NumField() {
+			f := t.Field(i)
+			if !f.IsExported() {
+				continue
+			}
+			field := out.Field(i)
+			if kind := f.Tag.Get("pii"); kind != "" && field.Kind() == reflect.String {
+				field.SetString(Mask(kind, field.String()))
+				continue
+			}
+			field.Set(redactValue(field))
+		}
+		return out
+	}
+	return v
+}
+
+// Keys - ключи атрибутов логов и спанов, значения которых всегда маскируются.
+// Выводятся из json-тегов полей с тегом pii, "name" исключено как слишком общее.
+func Keys(types ...any) map[string]string {
+	keys := map[string]string{}
+	for _, v := range types {
+		collectKeys(reflect.TypeOf(v), keys)
+	}
+	return keys
+}
+
+func collectKeys(t reflect.Type, keys map[string]string) {
+	for t.Kind() == reflect.Pointer || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		f := t.Field(i)
+		kind := f.Tag.Get("pii")
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if kind != "" && name != "" && kind != KindName {
+			keys[name] = kind
+		}
+		collectKeys(f.Type, keys)
+	}
+}
@@ -0,0 +1,79 @@
+package pii
+
+import (
+	"bytes"
+	"log/slog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type contact struct {
+	Name  string `json:"name" pii:"name"`
+	Phone string `json:"phone" pii:"phone"`
+	Email string `json:"email" pii:"email"`
+	City  string `json:"city"`
+}
+
+type envelope struct {
+	Contact  contact
+	Contacts []contact
+}
+
+func TestMask(t *testing.T) {
+	cases := []struct {
+		kind, value, expected string
+	}{
+		{KindPhone, "+79991234567", "+79*******67"},
+		{KindPhone, "123", "***"},
+		{KindEmail, "ivan@example.com", "i***@example.com"},
+		{KindEmail, "broken", "***"},
+		{KindName, "Иван Иванов", "И*** И***"},
+		{KindAddress, "ул. Ленина, 1", "***"},
+		{KindPhone, "", ""},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.expected, Mask(tc.kind, tc.value), tc.value)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	//1. Arrange(подготовка)
+	original := envelope{
+		Contact:  contact{Name: "Иван", Phone: "+79991234567", City: "Москва"},
+		Contacts: []contact{{Email: "ivan@example.com"}},
+	}
+
+	//2. Act(Действие)
+	redacted := Redact(original)
+
+	//3. Assert
+	assert.Equal(t, "И***", redacted.Contact.Name)
+	assert.Equal(t, "+79*******67", redacted.Contact.Phone)
+	assert.Equal(t, "Москва", redacted.Contact.City)
+	assert.Equal(t, "i***@example.com", redacted.Contacts[0].Email)
+	// исходное значение, включая общий слайс, не изменилось
+	assert.Equal(t, "+79991234567", original.Contact.Phone)
+	assert.Equal(t, "ivan@example.com", original.Contacts[0].Email)
+}
+
+func TestHandler(t *testing.T) {
+	//1. Arrange(подготовка)
+	var buf bytes.Buffer
+	logger := slog.New(NewHandler(slog.NewTextHandler(&buf, nil), Keys(contact{})))
+
+	//2. Act(Действие)
+	logger.Info("письмо для ivan@example.com не доставлено",
+		slog.String("phone", "+79991234567"),
+		slog.Any("contact", contact{Name: "Иван", City: "Москва"}),
+		slog.String("uid", "b563feb7b2b84b6test"))
+
+	//3. Assert
+	out := buf.String()
+	assert.NotContains(t, out, "ivan@example.com")
+	assert.NotContains(t, out, "+79991234567")
+	assert.Contains(t, out, "i***@example.com")
+	assert.Contains(t, out, "phone=+79*******67")
+	assert.Contains(t, out, "Name:И***")
+	assert.Contains(t, out, "uid=b563feb7b2b84b6test")
+}
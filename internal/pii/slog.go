@@ -0,0 +1,69 @@
+package pii
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Handler - обертка slog.Handler, маскирующая персональные данные в каждой записи:
+// атрибуты с известными ключами, структуры с тегами pii и email/телефоны в тексте.
+type Handler struct {
+	next slog.Handler
+	keys map[string]string
+}
+
+// NewHandler - keys: ключ атрибута -> вид данных (см. Keys).
+func NewHandler(next slog.Handler, keys map[string]string) *Handler {
+	return &Handler{next: next, keys: keys}
+}
+
+func (h *Handler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *Handler) Handle(ctx context.Context, r slog.Record) error {
+	out := slog.NewRecord(r.Time, r.Level, Scrub(r.Message), r.PC)
+	r.Attrs(func(a slog.Attr) bool {
+		out.AddAttrs(h.maskAttr(a))
+		return true
+	})
+	return h.next.Handle(ctx, out)
+}
+
+func (h *Handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	masked := make([]slog.Attr, len(attrs))
+	for i, a := range attrs {
+		masked[i] = h.maskAttr(a)
+	}
+	return &Handler{next: h.next.WithAttrs(masked), keys: h.keys}
+}
+
+func (h *Handler) WithGroup(name string) slog.Handler {
+	return &Handler{next: h.next.WithGroup(name), keys: h.keys}
+}
+
+func (h *Handler) maskAttr(a slog.Attr) slog.Attr {
+	v := a.Value.Resolve()
+	switch v.Kind() {
+	case slog.KindGroup:
+		group := v.Group()
+		masked := make([]any, len(group))
+		for i, ga := range group {
+			masked[i] = h.maskAttr(ga)
+		}
+		return slog.Group(a.Key, masked...)
+	case slog.KindString:
+		if kind, ok := h.keys[a.Key]; ok {
+			return slog.String(a.Key, Mask(kind, v.String()))
+		}
+		return slog.String(a.Key, Scrub(v.String()))
+	case slog.KindAny:
+		if err, ok := v.Any().(error); ok {
+			return slog.String(a.Key, Scrub(err.Error()))
+		}
+		if redacted, ok := RedactAny(v.Any()); ok {
+			return slog.Any(a.Key, redacted)
+		}
+	}
+	return slog.Attr{Key: a.Key, Value: v}
+}
@@ -0,0 +1,82 @@
+package pii
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// SpanExporter - маскирует атрибуты спанов и их событий перед отправкой в коллектор.
+type SpanExporter struct {
+	next sdktrace.SpanExporter
+	keys map[string]string
+}
+
+func NewSpanExporter(next sdktrace.SpanExporter, keys map[string]string) *SpanExporter {
+	return &SpanExporter{next: next, keys: keys}
+}
+
+func (e *SpanExporter) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	masked := make([]sdktrace.ReadOnlySpan, len(spans))
+	for i, s := range spans {
+		masked[i] = maskedSpan{ReadOnlySpan: s, keys: e.keys}
+	}
+	return e.next.ExportSpans(ctx, masked)
+}
+
+func (e *SpanExporter) Shutdown(ctx context.Context) error {
+	return e.next.Shutdown(ctx)
+}
+
+// maskedSpan - спан с замаскированными атрибутами, остальное берется из исходного.
+type maskedSpan struct {
+	sdktrace.ReadOnlySpan
+	keys map[string]string
+}
+
+func (s maskedSpan) Attributes() []attribute.KeyValue {
+	return maskAttributes(s.ReadOnlySpan.Attributes(), s.keys)
+}
+
+func (s maskedSpan) Events() []sdktrace.Event {
+	events := s.ReadOnlySpan.Events()
+	out := make([]sdktrace.Event, len(events))
+	for i, ev := range events {
+		ev.Attributes = maskAttributes(ev.Attributes, s.keys)
+		out[i] = ev
+	}
+	return out
+}
+
+func (s maskedSpan) Status() sdktrace.Status {
+	st := s.ReadOnlySpan.Status()
+	st.Description = Scrub(st.Description)
+	return st
+}
+
+func maskAttributes(attrs []attribute.KeyValue, keys map[string]string) []attribute.KeyValue {
+	out := make([]attribute.KeyValue, len(attrs))
+	for i, kv := range attrs {
+		out[i] = kv
+		if kv.Value.Type() != attribute.STRING {
+			continue
+		}
+		if kind, ok := keys[lastSegment(string(kv.Key))]; ok {
+			out[i] = kv.Key.String(Mask(kind, kv.Value.AsString()))
+			continue
+		}
+		out[i] = kv.Key.String(Scrub(kv.Value.AsString()))
+	}
+	return out
+}
+
+// lastSegment - "order.delivery.phone" -> "phone".
+func lastSegment(key string) string {
+	for i := len(key) - 1; i >= 0; i-- {
+		if key[i] == '.' {
+			return key[i+1:]
+		}
+	}
+	return key
+}
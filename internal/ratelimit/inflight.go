@@ -0,0 +1,57 @@
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// InFlight - ограничение одновременных запросов, доходящих до БД.
+// Лишние запросы ждут свободного места не дольше wait, затем получают отказ.
+type InFlight struct {
+	slots chan struct{}
+	wait  time.Duration
+}
+
+// NewInFlight - max <= 0 означает отсутствие ограничения (nil).
+func NewInFlight(max int, wait time.Duration) *InFlight {
+	if max <= 0 {
+		return nil
+	}
+	return &InFlight{slots: make(chan struct{}, max), wait: wait}
+}
+
+// Acquire - занимает место; при успехе release нужно вызвать по завершении запроса.
+func (f *InFlight) Acquire(ctx context.Context) (release func(), ok bool) {
+	select {
+	case f.slots <- struct{}{}:
+		return f.release, true
+	default:
+	}
+	if f.wait <= 0 {
+		return nil, false
+	}
+	timer := time.NewTimer(f.wait)
+	defer timer.Stop()
+	select {
+	case f.slots <- struct{}{}:
+		return f.release, true
+	case <-timer.C:
+		return nil, false
+	case <-ctx.Done():
+		return nil, false
+	}
+}
+
+func (f *InFlight) release() {
+	<-f.slots
+}
+
+// InUse - занятые места.
+func (f *InFlight) InUse() int {
+	return len(f.slots)
+}
+
+// Max - максимум одновременных запросов.
+func (f *InFlight) Max() int {
+	return cap(f.slots)
+}
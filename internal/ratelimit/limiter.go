@@ -0,0 +1,162 @@
+// Package ratelimit - ограничение частоты запросов по клиентам (token bucket)
+// и общего числа одновременных запросов к БД.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Tier - параметры корзины: Rate токенов в секунду, не больше Burst подряд.
+// Rate <= 0 означает отсутствие ограничения.
+type Tier struct {
+	Name  string
+	Rate  float64
+	Burst int
+}
+
+// Unlimited - уровень без ограничений.
+func (t Tier) Unlimited() bool {
+	return t.Rate <= 0
+}
+
+// ParseTiers - разбирает "anonymous:5:10,viewer:20:40,admin:0:0" (имя:в секунду:burst).
+func ParseTiers(raw string) (map[string]Tier, error) {
+	tiers := map[string]Tier{}
+	for _, entry := range strings.Split(raw, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.Split(entry, ":")
+		if len(parts) != 3 {
+			return nil, fmt.Errorf("уровень %q: ожидается имя:в_секунду:burst", entry)
+		}
+		rate, err := strconv.ParseFloat(parts[1], 64)
+		if err != nil {
+			return nil, fmt.Errorf("уровень %q: %w", entry, err)
+		}
+		burst, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, fmt.Errorf("уровень %q: %w", entry, err)
+		}
+		if rate > 0 && burst < 1 {
+			return nil, fmt.Errorf("уровень %q: burst должен быть не меньше 1", entry)
+		}
+		tiers[parts[0]] = Tier{Name: parts[0], Rate: rate, Burst: burst}
+	}
+	return tiers, nil
+}
+
+// Decision - результат проверки, из него строятся заголовки RateLimit-*.
+type Decision struct {
+	Allowed    bool
+	Limit      int           // размер корзины
+	Remaining  int           // целых токенов после запроса
+	Reset      time.Duration // через сколько корзина наполнится полностью
+	RetryAfter time.Duration // через сколько появится токен (только при отказе)
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// Limiter - корзины токенов по ключу клиента (API-ключ, sub токена или IP).
+type Limiter struct {
+	mu      sync.Mutex
+	buckets map[string]*bucket
+	idleTTL time.Duration
+	now     func() time.Time
+}
+
+// NewLimiter - idleTTL: корзины клиентов, не приходивших дольше, удаляются при Cleanup.
+func NewLimiter(idleTTL time.Duration) *Limiter {
+	return &Limiter{buckets: map[string]*bucket{}, idleTTL: idleTTL, now: time.Now}
+}
+
+// Allow - списывает токен из корзины key по правилам tier.
+func (l *Limiter) Allow(key string, tier Tier) Decision {
+	return l.take(key, tier, true)
+}
+
+// Peek - решение, которое принял бы Allow, без списания токена.
+func (l *Limiter) Peek(key string, tier Tier) Decision {
+	return l.take(key, tier, false)
+}
+
+func (l *Limiter) take(key string, tier Tier, consume bool) Decision {
+	if tier.Unlimited() {
+		return Decision{Allowed: true}
+	}
+	now := l.now()
+	burst := float64(tier.Burst)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[tier.Name+"|"+key]
+	if !ok {
+		b = &bucket{tokens: burst, last: now}
+		l.buckets[tier.Name+"|"+key] = b
+	}
+	b.tokens = math.Min(burst, b.tokens+now.Sub(b.last).Seconds()*tier.Rate)
+	b.last = now
+
+	d := Decision{Limit: tier.Burst}
+	if b.tokens >= 1 {
+		if consume {
+			b.tokens--
+		}
+		d.Allowed = true
+	} else {
+		d.RetryAfter = seconds((1 - b.tokens) / tier.Rate)
+	}
+	d.Remaining = int(b.tokens)
+	d.Reset = seconds((burst - b.tokens) / tier.Rate)
+	return d
+}
+
+func seconds(s float64) time.Duration {
+	return time.Duration(s * float64(time.Second))
+}
+
+// Cleanup - удаляет корзины неактивных клиентов (они все равно полные).
+func (l *Limiter) Cleanup() int {
+	cutoff := l.now().Add(-l.idleTTL)
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	removed := 0
+	for key, b := range l.buckets {
+		if b.last.Before(cutoff) {
+			delete(l.buckets, key)
+			removed++
+		}
+	}
+	return removed
+}
+
+// Len - количество отслеживаемых корзин.
+func (l *Limiter) Len() int {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return len(l.buckets)
+}
+
+// Run - периодически удаляет неактивные корзины, работает до отмены ctx.
+func (l *Limiter) Run(ctx context.Context) error {
+	ticker := time.NewTicker(l.idleTTL)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			l.Cleanup()
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseTiers(t *testing.T) {
+	tiers, err := ParseTiers("anonymous:5:10, admin:0:0")
+	require.NoError(t, err)
+	assert.Equal(t, Tier{Name: "anonymous", Rate: 5, Burst: 10}, tiers["anonymous"])
+	assert.True(t, tiers["admin"].Unlimited())
+
+	for _, raw := range []string{"viewer:5", "viewer:x:1", "viewer:5:0"} {
+		_, err := ParseTiers(raw)
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	//1. Arrange(подготовка)
+	now := time.Unix(0, 0)
+	l := NewLimiter(time.Minute)
+	l.now = func() time.Time { return now }
+	tier := Tier{Name: "viewer", Rate: 2, Burst: 3}
+
+	//2. Act(Действие)
+	var decisions []Decision
+	for range 4 {
+		decisions = append(decisions, l.Allow("client", tier))
+	}
+	other := l.Allow("other", tier)
+	now = now.Add(500 * time.Millisecond) // за полсекунды набегает один токен
+	refilled := l.Allow("client", tier)
+
+	//3. Assert
+	assert.True(t, decisions[0].Allowed)
+	assert.Equal(t, 2, decisions[0].Remaining)
+	assert.True(t, decisions[2].Allowed)
+	assert.False(t, decisions[3].Allowed)
+	assert.Equal(t, 0, decisions[3].Remaining)
+	assert.Equal(t, 500*time.Millisecond, decisions[3].RetryAfter)
+	assert.Equal(t, 1500*time.Millisecond, decisions[3].Reset)
+	assert.True(t, other.Allowed, "у каждого клиента своя корзина")
+	assert.True(t, refilled.Allowed)
+}
+
+// Peek не расходует токены.
+func TestLimiter_Peek(t *testing.T) {
+	l := NewLimiter(time.Minute)
+	tier := Tier{Name: "anonymous", Rate: 1, Burst: 1}
+
+	assert.True(t, l.Peek("ip", tier).Allowed)
+	assert.True(t, l.Peek("ip", tier).Allowed)
+	assert.True(t, l.Allow("ip", tier).Allowed)
+	assert.False(t, l.Peek("ip", tier).Allowed)
+}
+
+func TestLimiter_Cleanup(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := NewLimiter(time.Minute)
+	l.now = func() time.Time { return now }
+	l.Allow("old", Tier{Name: "viewer", Rate: 1, Burst: 1})
+	now = now.Add(2 * time.Minute)
+	l.Allow("fresh", Tier{Name: "viewer", Rate: 1, Burst: 1})
+
+	assert.Equal(t, 1, l.Cleanup())
+	assert.Equal(t, 1, l.Len())
+}
+
+func TestInFlight(t *testing.T) {
+	//1. Arrange(подготовка)
+	f := NewInFlight(1, 20*time.Millisecond)
+	release, ok := f.Acquire(context.Background())
+	require.True(t, ok)
+
+	//2. Act(Действие)
+	_, waited := f.Acquire(context.Background())
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		release()
+	}()
+	releaseNext, acquired := f.Acquire(context.Background())
+
+	//3. Assert
+	assert.False(t, waited, "место не освободилось за время ожидания")
+	assert.True(t, acquired, "место освободилось во время ожидания")
+	releaseNext()
+	assert.Equal(t, 0, f.InUse())
+	assert.Nil(t, NewInFlight(0, time.Second))
+}
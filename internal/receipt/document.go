@@ -0,0 +1,123 @@
+package receipt
+
+import (
+	"math"
+	"sort"
+	"time"
+	"wb-project/internal/i18n"
+	"wb-project/internal/models"
+
+	"golang.org/x/text/currency"
+	"golang.org/x/text/language"
+	"golang.org/x/text/message"
+	"golang.org/x/text/number"
+)
+
+// Document - данные шаблона: заказ и посчитанные для печати значения.
+// Денежные суммы в шаблоне форматируются через {{ $.Money ... }} по locale заказа.
+type Document struct {
+	Order     *models.Order
+	Lines     []Line
+	Groups    []Group // для упаковочного листа: товары по трек-номерам
+	Quantity  int
+	PrintedAt time.Time
+
+	labels  language.Tag // язык подписей
+	lang    language.Base
+	printer *message.Printer
+	unit    currency.Unit
+	known   bool // валюта из заказа известна x/text
+}
+
+// Line - позиция заказа с ценой после скидки.
+type Line struct {
+	models.Items
+	No        int     // номер позиции в заказе, с 1
+	SalePrice float64 // цена за единицу со скидкой
+}
+
+// Group - товары одной посылки.
+type Group struct {
+	TrackNumber string
+	Lines       []Line
+}
+
+// NewDocument - готовит заказ к печати с подписями на языке labels.
+// Суммы и даты форматируются по locale заказа, неизвестная locale - по-английски.
+func NewDocument(order *models.Order, printedAt time.Time, labels language.Tag) *Document {
+	tag, err := language.Parse(order.Locale)
+	if err != nil {
+		tag = language.English
+	}
+	lang, _ := tag.Base()
+	unit, err := currency.ParseISO(order.Payment.Currency)
+	doc := &Document{
+		Order:     order,
+		Lines:     make([]Line, len(order.Items)),
+		Quantity:  len(order.Items),
+		PrintedAt: printedAt,
+		labels:    labels,
+		lang:      lang,
+		printer:   message.NewPrinter(tag),
+		unit:      unit,
+		known:     err == nil,
+	}
+
+	groups := map[string]*Group{}
+	for i, item := range order.Items {
+		line := Line{Items: item, No: i + 1, SalePrice: salePrice(item.Price, item.Sale)}
+		doc.Lines[i] = line
+		g, ok := groups[item.TrackNumber]
+		if !ok {
+			g = &Group{TrackNumber: item.TrackNumber}
+			groups[item.TrackNumber] = g
+		}
+		g.Lines = append(g.Lines, line)
+	}
+	for _, g := range groups {
+		doc.Groups = append(doc.Groups, *g)
+	}
+	sort.Slice(doc.Groups, func(i, j int) bool { return doc.Groups[i].TrackNumber < doc.Groups[j].TrackNumber })
+	return doc
+}
+
+// Money - сумма в валюте заказа с разделителями по locale: "₽ 2 528,00", "USD 1,817.00".
+// Валюту, которой нет в справочнике x/text, печатаем кодом из заказа.
+func (d *Document) Money(v any) string {
+	amount := toFloat(v)
+	if !d.known {
+		return d.printer.Sprint(number.Decimal(amount, number.Scale(2))) + " " + d.Order.Payment.Currency
+	}
+	return d.printer.Sprint(currency.Symbol(d.unit.Amount(amount)))
+}
+
+// T - подпись из каталога сообщений: {{ .T "receipt.total" }}.
+func (d *Document) T(key string, args ...any) string {
+	return i18n.T(d.labels, key, args...)
+}
+
+// Date - дата и время по locale заказа.
+func (d *Document) Date(t time.Time) string {
+	if d.lang.String() == "ru" {
+		return t.Format("02.01.2006 15:04")
+	}
+	return t.Format("Jan 2, 2006 15:04")
+}
+
+// salePrice - цена за единицу с учетом скидки в процентах, до копеек.
+func salePrice(price, sale int) float64 {
+	return math.Round(float64(price)*float64(100-sale)) / 100
+}
+
+func toFloat(v any) float64 {
+	switch n := v.(type) {
+	case int:
+		return float64(n)
+	case int64:
+		return float64(n)
+	case float64:
+		return n
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,117 @@
+// Package receipt - печатные формы заказа: чек покупателю и упаковочный лист для склада.
+// Шаблоны по умолчанию зашиты в бинарник, любой из них можно заменить файлом
+// с тем же именем из каталога RECEIPT_TEMPLATES_DIR.
+package receipt
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	texttemplate "text/template"
+	"time"
+	"wb-project/internal/models"
+
+	"golang.org/x/text/language"
+)
+
+// Виды печатных форм.
+const (
+	KindReceipt     = "receipt"
+	KindPackingSlip = "packing_slip"
+)
+
+// Форматы печатных форм.
+const (
+	FormatHTML = "html"
+	FormatText = "text"
+)
+
+var ErrUnknownTemplate = errors.New("неизвестная печатная форма")
+
+//go:embed templates
+var defaults embed.FS
+
+// Renderer - набор разобранных шаблонов, безопасен для параллельного использования.
+type Renderer struct {
+	html map[string]*htmltemplate.Template
+	text map[string]*texttemplate.Template
+	now  func() time.Time
+}
+
+// NewRenderer - шаблоны по умолчанию, переопределенные файлами из dir
+// (receipt.html.tmpl, receipt.txt.tmpl, packing_slip.html.tmpl, packing_slip.txt.tmpl).
+// Пустой dir - только встроенные шаблоны.
+func NewRenderer(dir string) (*Renderer, error) {
+	r := &Renderer{
+		html: map[string]*htmltemplate.Template{},
+		text: map[string]*texttemplate.Template{},
+		now:  time.Now,
+	}
+	for _, kind := range []string{KindReceipt, KindPackingSlip} {
+		name := kind + ".html.tmpl"
+		src, err := source(dir, name)
+		if err != nil {
+			return nil, err
+		}
+		if r.html[kind], err = htmltemplate.New(name).Parse(src); err != nil {
+			return nil, fmt.Errorf("шаблон %s: %w", name, err)
+		}
+
+		name = kind + ".txt.tmpl"
+		if src, err = source(dir, name); err != nil {
+			return nil, err
+		}
+		if r.text[kind], err = texttemplate.New(name).Parse(src); err != nil {
+			return nil, fmt.Errorf("шаблон %s: %w", name, err)
+		}
+	}
+	return r, nil
+}
+
+// Default - только встроенные шаблоны.
+func Default() *Renderer {
+	r, err := NewRenderer("")
+	if err != nil {
+		panic(err) // встроенные шаблоны проверяются тестами
+	}
+	return r
+}
+
+// source - файл из каталога переопределений, если он там есть, иначе встроенный.
+func source(dir, name string) (string, error) {
+	if dir != "" {
+		data, err := os.ReadFile(filepath.Join(dir, name))
+		if err == nil {
+			return string(data), nil
+		}
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", fmt.Errorf("шаблон %s: %w", name, err)
+		}
+	}
+	data, err := defaults.ReadFile("templates/" + name)
+	if err != nil {
+		return "", fmt.Errorf("шаблон %s: %w", name, err)
+	}
+	return string(data), nil
+}
+
+// Render - печатная форма kind заказа в формате format с подписями на языке lang.
+func (r *Renderer) Render(w io.Writer, kind, format string, order *models.Order, lang language.Tag) error {
+	doc := NewDocument(order, r.now(), lang)
+	switch format {
+	case FormatHTML:
+		if t, ok := r.html[kind]; ok {
+			return t.Execute(w, doc)
+		}
+	case FormatText:
+		if t, ok := r.text[kind]; ok {
+			return t.Execute(w, doc)
+		}
+	}
+	return fmt.Errorf("%w: %s/%s", ErrUnknownTemplate, kind, format)
+}
@@ -0,0 +1,135 @@
+package receipt
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"wb-project/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/text/language"
+)
+
+func testOrder(locale string) *models.Order {
+	return &models.Order{
+		OrderUID:    "b563feb7b2b84b6test",
+		TrackNumber: "WBILMTESTTRACK",
+		Locale:      locale,
+		DateCreated: time.Date(2026, 3, 1, 10, 30, 0, 0, time.UTC),
+		Delivery:    models.Delivery{Name: "Test Testov", Zip: "2639809", City: "Kiryat Mozkin", Address: "Ploshad Mira 15"},
+		Payment:     models.Payment{Currency: "RUB", Amount: 3317, DeliveryCost: 1500, GoodsTotal: 1817, CustomFee: 0},
+		Items: []models.Items{
+			{NmID: 2389212, TrackNumber: "TRK-2", Name: "Mascaras", Brand: "Vivienne Sabo", Price: 2528, Sale: 25, TotalPrice: 1896},
+			{NmID: 1000001, TrackNumber: "TRK-1", Name: "Socks", Price: 100, TotalPrice: 100},
+			{NmID: 1000002, TrackNumber: "TRK-2", Name: "Brush", Price: 99, Sale: 50, TotalPrice: 50},
+		},
+	}
+}
+
+func TestDocument_Money(t *testing.T) {
+	//1. Arrange(подготовка)
+	ru := NewDocument(testOrder("ru"), time.Now(), language.Russian)
+	en := NewDocument(testOrder("en"), time.Now(), language.Russian)
+	unknown := testOrder("")
+	unknown.Payment.Currency = "XYZ"
+
+	//2. Act(Действие)
+	//3. Assert
+	assert.Equal(t, "₽ 2\u00a0528,00", ru.Money(2528)) // неразрывный пробел между разрядами
+	assert.Equal(t, "RUB 2,528.00", en.Money(2528))
+	assert.Equal(t, "49.50 XYZ", NewDocument(unknown, time.Now(), language.Russian).Money(49.5))
+}
+
+func TestDocument_LinesAndGroups(t *testing.T) {
+	//1. Arrange(подготовка)
+	order := testOrder("ru")
+
+	//2. Act(Действие)
+	doc := NewDocument(order, time.Now(), language.Russian)
+
+	//3. Assert
+	require.Len(t, doc.Lines, 3)
+	assert.Equal(t, 1896.0, doc.Lines[0].SalePrice)
+	assert.Equal(t, 49.5, doc.Lines[2].SalePrice)
+	assert.Equal(t, 3, doc.Lines[2].No)
+
+	require.Len(t, doc.Groups, 2)
+	assert.Equal(t, "TRK-1", doc.Groups[0].TrackNumber)
+	assert.Equal(t, "TRK-2", doc.Groups[1].TrackNumber)
+	assert.Len(t, doc.Groups[1].Lines, 2)
+}
+
+func TestRenderer_Defaults(t *testing.T) {
+	//1. Arrange(подготовка)
+	r := Default()
+	order := testOrder("ru")
+	order.Delivery.Name = "<script>alert(1)</script>"
+
+	for _, kind := range []string{KindReceipt, KindPackingSlip} {
+		for _, format := range []string{FormatHTML, FormatText} {
+			//2. Act(Действие)
+			var buf bytes.Buffer
+			err := r.Render(&buf, kind, format, order, language.Russian)
+
+			//3. Assert
+			require.NoError(t, err, kind+"/"+format)
+			assert.Contains(t, buf.String(), order.OrderUID)
+			if format == FormatHTML {
+				assert.NotContains(t, buf.String(), "<script>")
+			}
+		}
+	}
+
+	var buf bytes.Buffer
+	assert.ErrorIs(t, r.Render(&buf, "invoice", FormatHTML, order, language.Russian), ErrUnknownTemplate)
+}
+
+func TestRenderer_Labels(t *testing.T) {
+	//1. Arrange(подготовка)
+	r := Default()
+	order := testOrder("ru")
+
+	//2. Act(Действие)
+	var ru, en bytes.Buffer
+	require.NoError(t, r.Render(&ru, KindPackingSlip, FormatText, order, language.Russian))
+	require.NoError(t, r.Render(&en, KindPackingSlip, FormatText, order, language.English))
+
+	//3. Assert
+	assert.Contains(t, ru.String(), "Позиций: 3, посылок: 2")
+	assert.Contains(t, en.String(), "Items: 3, parcels: 2")
+	assert.Contains(t, en.String(), "01.03.2026 10:30") // даты по locale заказа
+}
+
+func TestRenderer_Override(t *testing.T) {
+	//1. Arrange(подготовка)
+	dir := t.TempDir()
+	tmpl := "{{ .Order.OrderUID }} {{ range .Groups }}[{{ .TrackNumber }}]{{ end }}"
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "packing_slip.txt.tmpl"), []byte(tmpl), 0o600))
+
+	r, err := NewRenderer(dir)
+	require.NoError(t, err)
+
+	//2. Act(Действие)
+	var slip, receipt bytes.Buffer
+	require.NoError(t, r.Render(&slip, KindPackingSlip, FormatText, testOrder("ru"), language.Russian))
+	require.NoError(t, r.Render(&receipt, KindReceipt, FormatText, testOrder("ru"), language.Russian))
+
+	//3. Assert
+	assert.Equal(t, "b563feb7b2b84b6test [TRK-1][TRK-2]", slip.String())
+	assert.Contains(t, receipt.String(), "Чек по заказу") // остальные шаблоны встроенные
+}
+
+func TestNewRenderer_InvalidTemplate(t *testing.T) {
+	//1. Arrange(подготовка)
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "receipt.html.tmpl"), []byte("{{ .Order"), 0o600))
+
+	//2. Act(Действие)
+	_, err := NewRenderer(dir)
+
+	//3. Assert
+	assert.ErrorContains(t, err, "receipt.html.tmpl")
+}
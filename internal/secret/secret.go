@@ -0,0 +1,96 @@
+// Package secret - учетные данные из конфигурации или из файла (Docker secrets,
+// Kubernetes Secret, смонтированный томом). Файл перечитывается периодически,
+// и новое значение используется для следующих соединений без перезапуска сервиса.
+package secret
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Value - текущее значение секрета, безопасно для параллельного использования.
+type Value struct {
+	path string // пусто - значение задано в конфигурации и не меняется
+
+	mu    sync.RWMutex
+	value string
+}
+
+// New - секрет из файла file, если он задан, иначе value.
+// Ошибка чтения файла при старте возвращается сразу: без пароля сервис не подключится.
+func New(value, file string) (*Value, error) {
+	v := &Value{path: file, value: value}
+	if file == "" {
+		return v, nil
+	}
+	if _, err := v.Refresh(); err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// Get - текущее значение.
+func (v *Value) Get() string {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+	return v.value
+}
+
+// String не раскрывает значение, если секрет случайно попадет в лог.
+func (v *Value) String() string {
+	return "******"
+}
+
+// Refresh - перечитывает файл, changed - значение изменилось.
+func (v *Value) Refresh() (changed bool, err error) {
+	if v.path == "" {
+		return false, nil
+	}
+	data, err := os.ReadFile(v.path)
+	if err != nil {
+		return false, fmt.Errorf("секрет %s: %w", v.path, err)
+	}
+	// редакторы и echo добавляют перевод строки, в пароль он не входит
+	value := strings.TrimRight(string(data), "\r\n")
+	if value == "" {
+		return false, fmt.Errorf("секрет %s: файл пуст", v.path)
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	changed = value != v.value
+	v.value = value
+	return changed, nil
+}
+
+// Watch - перечитывает файлы секретов каждые interval до отмены ctx.
+// Пока файл недоступен (например, в момент замены), остается прежнее значение.
+func Watch(ctx context.Context, interval time.Duration, values ...*Value) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+			for _, v := range values {
+				if v == nil {
+					continue
+				}
+				changed, err := v.Refresh()
+				if err != nil {
+					slog.Error("не удалось перечитать секрет, используется прежнее значение", slog.Any("error", err))
+					continue
+				}
+				if changed {
+					slog.Info("секрет обновлен из файла", slog.String("path", v.path))
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package secret
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValue(t *testing.T) {
+	t.Run("Значение из конфигурации", func(t *testing.T) {
+		v, err := New("pass", "")
+
+		require.NoError(t, err)
+		assert.Equal(t, "pass", v.Get())
+	})
+
+	t.Run("Файл важнее значения и перечитывается", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		path := filepath.Join(t.TempDir(), "db_password")
+		require.NoError(t, os.WriteFile(path, []byte("first\n"), 0o600))
+		v, err := New("pass", path)
+		require.NoError(t, err)
+		require.Equal(t, "first", v.Get())
+
+		//2. Act(Действие)
+		require.NoError(t, os.WriteFile(path, []byte("second"), 0o600))
+		changed, err := v.Refresh()
+		unchanged, _ := v.Refresh()
+
+		//3. Assert
+		require.NoError(t, err)
+		assert.True(t, changed)
+		assert.False(t, unchanged)
+		assert.Equal(t, "second", v.Get())
+		assert.Equal(t, "******", fmt.Sprint(v))
+	})
+
+	t.Run("Недоступный файл оставляет прежнее значение", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "db_password")
+		require.NoError(t, os.WriteFile(path, []byte("first"), 0o600))
+		v, _ := New("", path)
+
+		require.NoError(t, os.Remove(path))
+		_, err := v.Refresh()
+
+		assert.Error(t, err)
+		assert.Equal(t, "first", v.Get())
+	})
+
+	t.Run("Пустой файл при старте", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "db_password")
+		require.NoError(t, os.WriteFile(path, []byte("\n"), 0o600))
+
+		_, err := New("pass", path)
+
+		assert.ErrorContains(t, err, "файл пуст")
+	})
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+	"wb-project/internal/breaker"
+	"wb-project/internal/config"
+	"wb-project/internal/models"
+)
+
+// BreakerRepository - OrderRepository с таймаутом на каждую операцию и circuit breaker:
+// когда БД тормозит или падает, запросы перестают копиться в ожидании и отклоняются
+// сразу с models.ErrUnavailable, а сервис отдает что может из кэша.
+type BreakerRepository struct {
+	repo    OrderRepository
+	breaker *breaker.Breaker
+	cfg     config.RepositoryConfig
+}
+
+func NewBreakerRepository(repo OrderRepository, cfg config.RepositoryConfig) *BreakerRepository {
+	return &BreakerRepository{
+		repo: repo,
+		breaker: breaker.New("orders_db", breaker.Config{
+			FailureRate: cfg.BreakerFailureRate,
+			MinRequests: cfg.BreakerMinRequests,
+			Window:      cfg.BreakerWindow,
+			OpenTimeout: cfg.BreakerOpenTimeout,
+		}),
+		cfg: cfg,
+	}
+}
+
+func (r *BreakerRepository) Save(ctx context.Context, order models.Order) error {
+	return r.call(ctx, "save", r.cfg.WriteTimeout, func(ctx context.Context) error {
+		return r.repo.Save(ctx, order)
+	})
+}
+
+func (r *BreakerRepository) Get(ctx context.Context, uid string) (order models.Order, err error) {
+	err = r.call(ctx, "get", r.cfg.ReadTimeout, func(ctx context.Context) error {
+		order, err = r.repo.Get(ctx, uid)
+		return err
+	})
+	return order, err
+}
+
+func (r *BreakerRepository) GetMany(ctx context.Context, uids []string) (orders []models.Order, err error) {
+	err = r.call(ctx, "get_many", r.cfg.ReadTimeout, func(ctx context.Context) error {
+		orders, err = r.repo.GetMany(ctx, uids)
+		return err
+	})
+	return orders, err
+}
+
+func (r *BreakerRepository) GetAll(ctx context.Context) (orders []models.Order, err error) {
+	err = r.call(ctx, "get_all", r.cfg.ScanTimeout, func(ctx context.Context) error {
+		orders, err = r.repo.GetAll(ctx)
+		return err
+	})
+	return orders, err
+}
+
+func (r *BreakerRepository) List(ctx context.Context, q models.OrderListQuery) (page models.OrderPage, err error) {
+	err = r.call(ctx, "list", r.cfg.ReadTimeout, func(ctx context.Context) error {
+		page, err = r.repo.List(ctx, q)
+		return err
+	})
+	return page, err
+}
+
+// call - операция через breaker с собственным дедлайном.
+func (r *BreakerRepository) call(ctx context.Context, op string, timeout time.Duration, fn func(ctx context.Context) error) error {
+	//1. Разомкнутый breaker - отказ без обращения к БД
+	done, err := r.breaker.Allow()
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", models.ErrUnavailable, op, err)
+	}
+
+	//2. Запрос с таймаутом операции
+	opCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	err = fn(opCtx)
+
+	//3. Сбоем БД считаются ошибки и истекший таймаут операции, но не отсутствие записи,
+	// не повторное сохранение, не нечитаемая запись и не отмена запроса клиентом
+	failed := err != nil && !errors.Is(err, models.ErrNotFound) && !errors.Is(err, models.ErrAlreadyExists) &&
+		!errors.Is(err, models.ErrUnreadable) && ctx.Err() == nil
+	done(failed)
+	switch {
+	case !failed:
+		return err
+	case opCtx.Err() != nil:
+		return fmt.Errorf("%w: %s дольше %s: %w", models.ErrUnavailable, op, timeout, err)
+	default:
+		return fmt.Errorf("%w: %s: %w", models.ErrUnavailable, op, err)
+	}
+}
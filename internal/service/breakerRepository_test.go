@@ -0,0 +1,167 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+	"wb-project/internal/cache"
+	"wb-project/internal/config"
+	"wb-project/internal/models"
+	"wb-project/internal/service/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func testRepositoryConfig() config.RepositoryConfig {
+	return config.RepositoryConfig{
+		ReadTimeout:        20 * time.Millisecond,
+		WriteTimeout:       20 * time.Millisecond,
+		ScanTimeout:        20 * time.Millisecond,
+		BreakerFailureRate: 0.5,
+		BreakerMinRequests: 2,
+		BreakerWindow:      time.Minute,
+		BreakerOpenTimeout: time.Minute,
+	}
+}
+
+func TestBreakerRepository(t *testing.T) {
+	t.Run("После сбоев запросы отклоняются без обращения к БД", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		mockRepo.On("Get", mock.Anything, "uid").Return(models.Order{}, errors.New("connection refused")).Times(2)
+
+		//2. Act(Действие)
+		_, _ = repo.Get(context.Background(), "uid")
+		_, _ = repo.Get(context.Background(), "uid")
+		_, err := repo.Get(context.Background(), "uid")
+		saveErr := repo.Save(context.Background(), models.Order{OrderUID: "uid"})
+
+		//3. Assert
+		assert.ErrorIs(t, err, models.ErrUnavailable)
+		assert.ErrorIs(t, saveErr, models.ErrUnavailable)
+		mockRepo.AssertNumberOfCalls(t, "Get", 2)
+		mockRepo.AssertNotCalled(t, "Save", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Отсутствие заказа не считается сбоем", func(t *testing.T) {
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		mockRepo.On("Get", mock.Anything, "uid").Return(models.Order{}, fmt.Errorf("заказ uid: %w", models.ErrNotFound))
+
+		for range 3 {
+			_, err := repo.Get(context.Background(), "uid")
+			assert.ErrorIs(t, err, models.ErrNotFound)
+			assert.NotErrorIs(t, err, models.ErrUnavailable)
+		}
+		mockRepo.AssertNumberOfCalls(t, "Get", 3)
+	})
+
+	t.Run("Повторное сохранение не считается сбоем", func(t *testing.T) {
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(fmt.Errorf("заказ uid: %w", models.ErrAlreadyExists))
+
+		for range 3 {
+			err := repo.Save(context.Background(), models.Order{OrderUID: "uid"})
+			assert.ErrorIs(t, err, models.ErrAlreadyExists)
+			assert.NotErrorIs(t, err, models.ErrUnavailable)
+		}
+		mockRepo.AssertNumberOfCalls(t, "Save", 3)
+	})
+
+	t.Run("Нечитаемый заказ не считается сбоем", func(t *testing.T) {
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		mockRepo.On("Get", mock.Anything, "uid").Return(models.Order{}, fmt.Errorf("заказ uid: %w: ключ данных", models.ErrUnreadable))
+
+		for range 3 {
+			_, err := repo.Get(context.Background(), "uid")
+			assert.ErrorIs(t, err, models.ErrUnreadable)
+			assert.NotErrorIs(t, err, models.ErrUnavailable)
+		}
+		mockRepo.AssertNumberOfCalls(t, "Get", 3)
+	})
+
+	t.Run("Любой сбой БД - ErrUnavailable", func(t *testing.T) {
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		refused := errors.New("dial tcp 127.0.0.1:5432: connect: connection refused")
+		mockRepo.On("GetAll", mock.Anything).Return(nil, refused)
+
+		_, err := repo.GetAll(context.Background())
+
+		assert.ErrorIs(t, err, models.ErrUnavailable)
+		assert.ErrorIs(t, err, refused)
+	})
+
+	t.Run("Таймаут операции", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		mockRepo := mocks.NewOrderRepository(t)
+		repo := NewBreakerRepository(mockRepo, testRepositoryConfig())
+		mockRepo.On("Save", mock.Anything, mock.Anything).Return(func(ctx context.Context, _ models.Order) error {
+			<-ctx.Done() // БД не отвечает
+			return ctx.Err()
+		})
+
+		//2. Act(Действие)
+		start := time.Now()
+		err := repo.Save(context.Background(), models.Order{OrderUID: "uid"})
+
+		//3. Assert
+		assert.ErrorIs(t, err, models.ErrUnavailable)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+// Пока БД недоступна, заказ отдается из кэша даже после истечения срока жизни.
+func TestOrderService_GetOrder_StaleOnUnavailable(t *testing.T) {
+	//1. Arrange(подготовка)
+	mockRepo, mockCache, svc := setup(t)
+	stale := &models.Order{OrderUID: "stale"}
+	unavailable := errors.Join(models.ErrUnavailable, errors.New("circuit breaker разомкнут"))
+
+	mockCache.On("Get", mock.Anything).Return(nil, false)
+	mockRepo.On("Get", mock.Anything, mock.Anything).Return(models.Order{}, unavailable)
+	mockCache.On("GetStale", "stale").Return(stale, true)
+	mockCache.On("GetStale", "gone").Return(nil, false)
+
+	//2. Act(Действие)
+	found, err := svc.GetOrder(context.Background(), "stale")
+	_, goneErr := svc.GetOrder(context.Background(), "gone")
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, *stale, found)
+	assert.ErrorIs(t, goneErr, models.ErrUnavailable)
+}
+
+// Запись после TTL, но в пределах stale_ttl отдается, пока БД недоступна;
+// после запаса заказ уже не отдается.
+func TestOrderService_GetOrder_StaleGrace(t *testing.T) {
+	//1. Arrange(подготовка)
+	mockRepo := mocks.NewOrderRepository(t)
+	unavailable := fmt.Errorf("%w: get: connection refused", models.ErrUnavailable)
+	mockRepo.On("Get", mock.Anything, "uid").Return(models.Order{}, unavailable)
+
+	withinGrace := cache.NewOrderCache(time.Millisecond, time.Hour).WithStaleTTL(time.Hour)
+	pastGrace := cache.NewOrderCache(time.Millisecond, time.Hour).WithStaleTTL(time.Millisecond)
+	for _, c := range []*cache.OrderCache{withinGrace, pastGrace} {
+		c.Set("uid", &models.Order{OrderUID: "uid"})
+		defer c.Stop()
+	}
+	time.Sleep(5 * time.Millisecond) // срок жизни истек в обоих кэшах
+
+	//2. Act(Действие)
+	found, err := NewOrderService(mockRepo, withinGrace).GetOrder(context.Background(), "uid")
+	_, expiredErr := NewOrderService(mockRepo, pastGrace).GetOrder(context.Background(), "uid")
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, "uid", found.OrderUID)
+	assert.ErrorIs(t, expiredErr, models.ErrUnavailable)
+}
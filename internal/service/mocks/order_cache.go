@@ -43,6 +43,64 @@ func (_m *OrderCache) Get(uid string) (*models.Order, bool) {
 	return r0, r1
 }
 
+// GetEncoded provides a mock function with given fields: uid
+func (_m *OrderCache) GetEncoded(uid string) (models.EncodedOrder, bool) {
+	ret := _m.Called(uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetEncoded")
+	}
+
+	var r0 models.EncodedOrder
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (models.EncodedOrder, bool)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(string) models.EncodedOrder); ok {
+		r0 = rf(uid)
+	} else {
+		r0 = ret.Get(0).(models.EncodedOrder)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
+// GetStale provides a mock function with given fields: uid
+func (_m *OrderCache) GetStale(uid string) (*models.Order, bool) {
+	ret := _m.Called(uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetStale")
+	}
+
+	var r0 *models.Order
+	var r1 bool
+	if rf, ok := ret.Get(0).(func(string) (*models.Order, bool)); ok {
+		return rf(uid)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.Order); ok {
+		r0 = rf(uid)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) bool); ok {
+		r1 = rf(uid)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	return r0, r1
+}
+
 // Set provides a mock function with given fields: uid, order
 func (_m *OrderCache) Set(uid string, order *models.Order) {
 	_m.Called(uid, order)
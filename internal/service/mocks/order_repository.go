@@ -72,6 +72,64 @@ func (_m *OrderRepository) GetAll(ctx context.Context) ([]models.Order, error) {
 	return r0, r1
 }
 
+// GetMany provides a mock function with given fields: ctx, uids
+func (_m *OrderRepository) GetMany(ctx context.Context, uids []string) ([]models.Order, error) {
+	ret := _m.Called(ctx, uids)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetMany")
+	}
+
+	var r0 []models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, []string) ([]models.Order, error)); ok {
+		return rf(ctx, uids)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, []string) []models.Order); ok {
+		r0 = rf(ctx, uids)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.Order)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, []string) error); ok {
+		r1 = rf(ctx, uids)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// List provides a mock function with given fields: ctx, q
+func (_m *OrderRepository) List(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error) {
+	ret := _m.Called(ctx, q)
+
+	if len(ret) == 0 {
+		panic("no return value specified for List")
+	}
+
+	var r0 models.OrderPage
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderListQuery) (models.OrderPage, error)); ok {
+		return rf(ctx, q)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.OrderListQuery) models.OrderPage); ok {
+		r0 = rf(ctx, q)
+	} else {
+		r0 = ret.Get(0).(models.OrderPage)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.OrderListQuery) error); ok {
+		r1 = rf(ctx, q)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // Save provides a mock function with given fields: ctx, order
 func (_m *OrderRepository) Save(ctx context.Context, order models.Order) error {
 	ret := _m.Called(ctx, order)
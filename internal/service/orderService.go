@@ -6,8 +6,10 @@ package service
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"sync/atomic"
 	"time"
 	"wb-project/internal/logger/sl"
 	"wb-project/internal/metric"
@@ -16,6 +18,7 @@ import (
 	"github.com/go-playground/validator/v10"
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
 // OrderRepository описывает контракт для постоянного хранения и получения заказов.
@@ -25,7 +28,9 @@ import (
 type OrderRepository interface {
 	Save(ctx context.Context, order models.Order) error
 	Get(ctx context.Context, uid string) (models.Order, error)
+	GetMany(ctx context.Context, uids []string) ([]models.Order, error)
 	GetAll(ctx context.Context) ([]models.Order, error)
+	List(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error)
 }
 
 // OrderCache определяет контракт для высокопроизводительного
@@ -35,14 +40,29 @@ type OrderRepository interface {
 type OrderCache interface {
 	Set(uid string, order *models.Order)
 	Get(uid string) (*models.Order, bool)
+	GetEncoded(uid string) (models.EncodedOrder, bool)
+	GetStale(uid string) (*models.Order, bool)
 }
 
+// OrderNotifier получает каждый успешно обработанный заказ (например, SSE-стрим).
+type OrderNotifier interface {
+	Publish(order models.Order)
+}
+
+// Режимы валидации входящих заказов.
+const (
+	ValidationStrict  = "strict"  // любое нарушение отклоняет заказ
+	ValidationLenient = "lenient" // отклоняются только заказы без обязательных полей
+)
+
 // OrderService предоставляет методы для управления заказами,
 // включая их обработку, сохранение в БД и кэширование.
 type OrderService struct {
 	repo     OrderRepository // Используем интерфейс, а не struct
 	cache    OrderCache      // Используем интерфейс
 	validate *validator.Validate
+	notifier OrderNotifier // необязательный
+	lenient  atomic.Bool   // режим валидации меняется без перезапуска
 }
 
 // NewOrderService принимает интерфейсы.
@@ -54,6 +74,17 @@ func NewOrderService(repo OrderRepository, orderCache OrderCache) *OrderService
 	}
 }
 
+// WithNotifier подключает получателя новых заказов.
+func (s *OrderService) WithNotifier(n OrderNotifier) *OrderService {
+	s.notifier = n
+	return s
+}
+
+// SetValidationMode - ValidationStrict или ValidationLenient, действует со следующего сообщения.
+func (s *OrderService) SetValidationMode(mode string) {
+	s.lenient.Store(mode == ValidationLenient)
+}
+
 // HandleOrderMessage - функция для получения заказов
 func (s *OrderService) HandleOrderMessage(ctx context.Context, data []byte) error {
 	tr := otel.Tracer("orderService")
@@ -72,7 +103,7 @@ func (s *OrderService) HandleOrderMessage(ctx context.Context, data []byte) erro
 
 	span.SetAttributes(attribute.String("order_uid", order.OrderUID))
 	//2. Валидация данных, до сохранения в бд
-	if err := s.validateOrder(&order); err != nil {
+	if err := s.validateOrder(ctx, &order); err != nil {
 		return fmt.Errorf("валидация не пройдена %w", err)
 	}
 
@@ -97,6 +128,11 @@ func (s *OrderService) HandleOrderMessage(ctx context.Context, data []byte) erro
 	//4. Добавление в кеш
 	s.cache.Set(order.OrderUID, &order)
 	span.AddEvent("order добавлен в кеш")
+
+	//5. Уведомление подписчиков, не блокирует обработку
+	if s.notifier != nil {
+		s.notifier.Publish(order)
+	}
 	slog.Info("Успешно сохранен order", slog.String("order_uid", order.OrderUID), sl.Traced(ctx))
 	return nil
 }
@@ -124,6 +160,10 @@ func (s *OrderService) GetOrder(ctx context.Context, uid string) (models.Order,
 	//3. возвращаем из БД, пробрасывая контекст
 	found, err := s.repo.Get(ctx, uid)
 	if err != nil {
+		//3.1 БД недоступна - отдаем просроченную запись, если она еще в кэше
+		if stale, ok := s.staleOnUnavailable(ctx, err, uid); ok {
+			return *stale, nil
+		}
 		span.RecordError(err)
 		metric.DbOperationsTotal.WithLabelValues("get", "error").Inc()
 		return models.Order{}, fmt.Errorf("order не найден в БД %w", err)
@@ -136,6 +176,155 @@ func (s *OrderService) GetOrder(ctx context.Context, uid string) (models.Order,
 	return found, nil
 }
 
+// GetOrderEncoded - заказ в сериализованном виде с ETag. При попадании в кэш
+// готовые байты отдаются без повторного json.Marshal.
+func (s *OrderService) GetOrderEncoded(ctx context.Context, uid string) (models.EncodedOrder, error) {
+	if encoded, ok := s.cache.GetEncoded(uid); ok {
+		trace.SpanFromContext(ctx).AddEvent("cache hit")
+		metric.CacheHitsTotal.WithLabelValues("hit").Inc()
+		return encoded, nil
+	}
+
+	order, err := s.GetOrder(ctx, uid)
+	if err != nil {
+		return models.EncodedOrder{}, err
+	}
+	return models.EncodeOrder(&order)
+}
+
+// Границы размера страницы ListOrders.
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+// GetOrders - несколько заказов по UID: сначала кэш, промахи одним запросом в БД.
+// Дубликаты схлопываются, найденные заказы идут в порядке запроса. Ненайденные UID
+// возвращаются отдельно, ошибка означает сбой, а не отсутствие заказа.
+func (s *OrderService) GetOrders(ctx context.Context, uids []string) ([]models.Order, []string, error) {
+	tr := otel.Tracer("orderService")
+	ctx, span := tr.Start(ctx, "GetOrders")
+	defer span.End()
+
+	start := time.Now()
+	//1. Дедупликация и поиск в кеше
+	requested := make([]string, 0, len(uids))
+	byUID := make(map[string]models.Order, len(uids))
+	seen := make(map[string]bool, len(uids))
+	var misses []string
+	for _, uid := range uids {
+		if seen[uid] {
+			continue
+		}
+		seen[uid] = true
+		requested = append(requested, uid)
+
+		if fromCache, ok := s.cache.Get(uid); ok {
+			byUID[uid] = *fromCache
+			continue
+		}
+		misses = append(misses, uid)
+	}
+	hits := len(requested) - len(misses)
+	metric.CacheHitsTotal.WithLabelValues("hit").Add(float64(hits))
+	metric.CacheHitsTotal.WithLabelValues("miss").Add(float64(len(misses)))
+	span.SetAttributes(
+		attribute.Int("orders.requested", len(requested)),
+		attribute.Int("orders.cache_hits", hits),
+	)
+
+	//2. Промахи - одним запросом в БД
+	if len(misses) > 0 {
+		dbStart := time.Now()
+		fromDB, err := s.repo.GetMany(ctx, misses)
+		if err != nil && errors.Is(err, models.ErrUnavailable) {
+			//2.1 БД недоступна - ответ возможен, только если все промахи есть в кэше просроченными
+			fromDB = make([]models.Order, 0, len(misses))
+			for _, uid := range misses {
+				stale, ok := s.staleOnUnavailable(ctx, err, uid)
+				if !ok {
+					fromDB = nil
+					break
+				}
+				fromDB = append(fromDB, *stale)
+			}
+			if fromDB != nil {
+				err = nil
+			}
+		}
+		if err != nil {
+			span.RecordError(err)
+			metric.DbOperationsTotal.WithLabelValues("get_many", "error").Inc()
+			return nil, nil, fmt.Errorf("не удалось получить заказы из БД: %w", err)
+		}
+		metric.DbOperationsTotal.WithLabelValues("get_many", "success").Inc()
+		metric.DbDuration.WithLabelValues("get_many").Observe(time.Since(dbStart).Seconds())
+
+		//3. Нашли в бд, обновляем кеш
+		for i := range fromDB {
+			byUID[fromDB[i].OrderUID] = fromDB[i]
+			s.cache.Set(fromDB[i].OrderUID, &fromDB[i])
+		}
+	}
+
+	//4. Ответ в порядке запроса
+	found := make([]models.Order, 0, len(byUID))
+	missing := []string{}
+	for _, uid := range requested {
+		if order, ok := byUID[uid]; ok {
+			found = append(found, order)
+		} else {
+			missing = append(missing, uid)
+		}
+	}
+
+	metric.ObserveBatch(len(requested), hits, len(found)-hits, len(missing), time.Since(start))
+	span.SetAttributes(attribute.Int("orders.missing", len(missing)))
+	return found, missing, nil
+}
+
+// ListOrders - страница заказов от новых к старым.
+func (s *OrderService) ListOrders(ctx context.Context, q models.OrderListQuery) (models.OrderPage, error) {
+	tr := otel.Tracer("orderService")
+	ctx, span := tr.Start(ctx, "ListOrders")
+	defer span.End()
+
+	if q.Limit <= 0 {
+		q.Limit = DefaultPageSize
+	}
+	q.Limit = min(q.Limit, MaxPageSize)
+
+	start := time.Now()
+	page, err := s.repo.List(ctx, q)
+	if err != nil {
+		span.RecordError(err)
+		metric.DbOperationsTotal.WithLabelValues("list", "error").Inc()
+		return models.OrderPage{}, fmt.Errorf("не удалось получить список заказов: %w", err)
+	}
+	metric.DbOperationsTotal.WithLabelValues("list", "success").Inc()
+	metric.DbDuration.WithLabelValues("list").Observe(time.Since(start).Seconds())
+	span.SetAttributes(attribute.Int("orders.count", len(page.Orders)))
+	return page, nil
+}
+
+// staleOnUnavailable - просроченный заказ из кэша, если запрос к БД не выполнен из-за ее недоступности.
+func (s *OrderService) staleOnUnavailable(ctx context.Context, err error, uid string) (*models.Order, bool) {
+	if !errors.Is(err, models.ErrUnavailable) {
+		return nil, false
+	}
+	stale, ok := s.cache.GetStale(uid)
+	if !ok {
+		return nil, false
+	}
+	trace.SpanFromContext(ctx).AddEvent("stale cache hit")
+	slog.Warn("БД недоступна, заказ отдан из кэша после истечения срока",
+		slog.String("uid", uid),
+		slog.Any("error", err),
+		sl.Traced(ctx))
+	metric.CacheHitsTotal.WithLabelValues("stale").Inc()
+	return stale, true
+}
+
 // ReCache - функция для насыщения кэша
 func (s *OrderService) ReCache(ctx context.Context) error {
 	//1.1 trace
@@ -178,8 +367,27 @@ func (s *OrderService) ReCache(ctx context.Context) error {
 }
 
 // validateOrder - функция для валидации заказов
-func (s *OrderService) validateOrder(order *models.Order) error {
-	if err := s.validate.Struct(order); err != nil {
+func (s *OrderService) validateOrder(ctx context.Context, order *models.Order) error {
+	err := s.validate.Struct(order)
+	var violations validator.ValidationErrors
+	if err != nil && s.lenient.Load() && errors.As(err, &violations) {
+		// без обязательных полей заказ не сохранить, остальные нарушения только логируем
+		var required validator.ValidationErrors
+		for _, fe := range violations {
+			if fe.Tag() == "required" {
+				required = append(required, fe)
+			}
+		}
+		if len(required) > 0 {
+			return required
+		}
+		slog.Warn("заказ принят с нарушениями валидации (режим lenient)",
+			slog.String("order_uid", order.OrderUID),
+			slog.Any("error", err),
+			sl.Traced(ctx))
+		err = nil
+	}
+	if err != nil {
 		return err
 	}
 	if len(order.Items) == 0 {
@@ -82,6 +82,35 @@ func TestOrderService_HandleOrderMessage_ValidationError(t *testing.T) {
 	mockCache.AssertNotCalled(t, "Set", mock.Anything, mock.Anything)
 }
 
+// В режиме lenient заказ с неверным телефоном сохраняется, без товаров - по-прежнему отклоняется.
+func TestOrderService_HandleOrderMessage_LenientValidation(t *testing.T) {
+	//1. Arrange(подготовка)
+	mockRepo, mockCache, svc := setup(t)
+	svc.SetValidationMode(ValidationLenient)
+
+	jsonData, _ := os.ReadFile("testdata/test_order.json")
+	var order models.Order
+	_ = json.Unmarshal(jsonData, &order)
+	order.Delivery.Phone = "8 (939) 833-77-99"
+	badPhone, _ := json.Marshal(order)
+	noItems, _ := os.ReadFile("testdata/test_order_validation.json")
+
+	mockRepo.On("Save", mock.Anything, order).Return(nil)
+	mockCache.On("Set", order.OrderUID, &order).Return()
+
+	//2. Act(Действие)
+	lenientErr := svc.HandleOrderMessage(context.Background(), badPhone)
+	noItemsErr := svc.HandleOrderMessage(context.Background(), noItems)
+	svc.SetValidationMode(ValidationStrict)
+	strictErr := svc.HandleOrderMessage(context.Background(), badPhone)
+
+	//3. Assert
+	assert.NoError(t, lenientErr)
+	assert.ErrorContains(t, noItemsErr, "валидация не пройдена")
+	assert.ErrorContains(t, strictErr, "валидация не пройдена")
+	mockRepo.AssertNumberOfCalls(t, "Save", 1)
+}
+
 // Метод вернул ошибку "ошибка сохранения в БД".
 func TestOrderService_HandleOrderMessage_DBError(t *testing.T) {
 	//1. Arrange(подготовка)
@@ -196,3 +225,43 @@ func TestOrderService_ReCache_DBError(t *testing.T) {
 
 	assert.Error(t, err)
 }
+
+// Кэшированные заказы не идут в БД, промахи запрашиваются одним GetMany,
+// дубликаты схлопываются, а порядок ответа совпадает с порядком запроса.
+func TestOrderService_GetOrders(t *testing.T) {
+	//1. Arrange(подготовка)
+	mockRepo, mockCache, svc := setup(t)
+
+	cached := &models.Order{OrderUID: "cached"}
+	mockCache.On("Get", "cached").Return(cached, true)
+	mockCache.On("Get", "db").Return(nil, false)
+	mockCache.On("Get", "unknown").Return(nil, false)
+	mockRepo.On("GetMany", mock.Anything, []string{"db", "unknown"}).
+		Return([]models.Order{{OrderUID: "db"}}, nil).Once()
+	mockCache.On("Set", "db", mock.Anything).Return()
+
+	//2. Act(Действие)
+	orders, missing, err := svc.GetOrders(context.Background(), []string{"db", "cached", "unknown", "db"})
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"unknown"}, missing)
+	if assert.Len(t, orders, 2) {
+		assert.Equal(t, "db", orders[0].OrderUID)
+		assert.Equal(t, "cached", orders[1].OrderUID)
+	}
+	mockRepo.AssertNotCalled(t, "Get", mock.Anything, mock.Anything)
+}
+
+func TestOrderService_GetOrders_DBError(t *testing.T) {
+	//1. Arrange(подготовка)
+	mockRepo, mockCache, svc := setup(t)
+	mockCache.On("Get", "uid").Return(nil, false)
+	mockRepo.On("GetMany", mock.Anything, []string{"uid"}).Return(nil, errors.New("db down"))
+
+	//2. Act(Действие)
+	_, _, err := svc.GetOrders(context.Background(), []string{"uid"})
+
+	//3. Assert
+	assert.Error(t, err)
+}
@@ -0,0 +1,153 @@
+// Package stream раздает только что обработанные заказы подключенным
+// клиентам (SSE) через ограниченный fan-out хаб.
+package stream
+
+import (
+	"encoding/json"
+	"log/slog"
+	"sync"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
+)
+
+// Event - заказ с порядковым номером, который клиент присылает в Last-Event-ID.
+type Event struct {
+	ID   uint64
+	Data []byte // маскированный JSON заказа, сериализуется один раз на всех подписчиков
+}
+
+// Filter - необязательные условия отбора заказов, пустые поля не проверяются.
+type Filter struct {
+	Entry           string
+	DeliveryService string
+}
+
+func (f Filter) match(e *entry) bool {
+	return (f.Entry == "" || f.Entry == e.entry) &&
+		(f.DeliveryService == "" || f.DeliveryService == e.deliveryService)
+}
+
+// entry - событие в кольцевом буфере; от заказа остаются только поля фильтра,
+// персональные данные в памяти хаба не хранятся.
+type entry struct {
+	event           Event
+	entry           string
+	deliveryService string
+}
+
+// Subscriber - подключенный клиент. Канал C закрывается при отписке
+// или когда клиент не успевает читать и хаб его отключает.
+type Subscriber struct {
+	C      <-chan Event
+	ch     chan Event
+	filter Filter
+}
+
+// Hub - fan-out новых заказов. Publish никогда не блокируется: подписчик
+// с заполненным буфером отключается, чтобы не тормозить прием заказов.
+// Последние history событий хранятся в кольцевом буфере для докачки по Last-Event-ID.
+type Hub struct {
+	mu         sync.Mutex
+	lastID     uint64
+	ring       []entry
+	next       int // позиция для следующей записи в ring
+	subs       map[*Subscriber]struct{}
+	bufferSize int
+}
+
+func NewHub(bufferSize, history int) *Hub {
+	return &Hub{
+		ring:       make([]entry, 0, history),
+		subs:       make(map[*Subscriber]struct{}),
+		bufferSize: bufferSize,
+	}
+}
+
+// Publish - отправляет заказ всем подходящим подписчикам. Персональные данные
+// маскируются один раз здесь, в поток и историю попадает только маскированный JSON.
+func (h *Hub) Publish(order models.Order) {
+	data, err := json.Marshal(pii.Redact(order))
+	if err != nil {
+		slog.Error("не удалось сериализовать заказ для стрима", slog.Any("error", err))
+		return
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.lastID++
+	e := entry{event: Event{ID: h.lastID, Data: data}, entry: order.Entry, deliveryService: order.DeliveryService}
+	if cap(h.ring) > 0 {
+		if len(h.ring) < cap(h.ring) {
+			h.ring = append(h.ring, e)
+		} else {
+			h.ring[h.next] = e
+		}
+		h.next = (h.next + 1) % cap(h.ring)
+	}
+
+	for sub := range h.subs {
+		if !sub.filter.match(&e) {
+			continue
+		}
+		select {
+		case sub.ch <- e.event:
+		default:
+			// медленный клиент: отключаем, он переподключится с Last-Event-ID
+			h.remove(sub)
+			metric.StreamDroppedTotal.Inc()
+		}
+	}
+}
+
+// Subscribe - регистрирует подписчика и возвращает события из истории с ID > lastEventID,
+// которые нужно отдать клиенту до чтения из канала. Пропусков и дублей между историей
+// и каналом нет: оба формируются под одной блокировкой.
+func (h *Hub) Subscribe(filter Filter, lastEventID uint64) (*Subscriber, []Event) {
+	ch := make(chan Event, h.bufferSize)
+	sub := &Subscriber{C: ch, ch: ch, filter: filter}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	var backlog []Event
+	if lastEventID > 0 {
+		for i := range h.ring {
+			e := h.ring[(h.next+i)%len(h.ring)] // от старых к новым
+			if e.event.ID > lastEventID && filter.match(&e) {
+				backlog = append(backlog, e.event)
+			}
+		}
+	}
+
+	h.subs[sub] = struct{}{}
+	metric.StreamSubscribers.Inc()
+	return sub, backlog
+}
+
+// Unsubscribe - отключает подписчика, повторный вызов безопасен.
+func (h *Hub) Unsubscribe(sub *Subscriber) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.remove(sub)
+}
+
+// Close - отключает всех подписчиков, чтобы открытые SSE-соединения
+// не задерживали graceful shutdown HTTP сервера.
+func (h *Hub) Close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		h.remove(sub)
+	}
+}
+
+func (h *Hub) remove(sub *Subscriber) {
+	if _, ok := h.subs[sub]; !ok {
+		return
+	}
+	delete(h.subs, sub)
+	close(sub.ch)
+	metric.StreamSubscribers.Dec()
+}
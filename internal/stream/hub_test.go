@@ -0,0 +1,76 @@
+package stream
+
+import (
+	"testing"
+	"wb-project/internal/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub_PublishFilter(t *testing.T) {
+	//1. Arrange(подготовка)
+	hub := NewHub(4, 10)
+	sub, backlog := hub.Subscribe(Filter{Entry: "WBIL"}, 0)
+
+	//2. Act(Действие)
+	hub.Publish(models.Order{OrderUID: "1", Entry: "WBIL"})
+	hub.Publish(models.Order{OrderUID: "2", Entry: "OTHER"})
+
+	//3. Assert
+	assert.Empty(t, backlog)
+	assert.Len(t, sub.C, 1)
+	assert.Equal(t, uint64(1), (<-sub.C).ID)
+}
+
+// Медленный подписчик отключается, а публикация не блокируется.
+func TestHub_DropSlowSubscriber(t *testing.T) {
+	hub := NewHub(1, 10)
+	sub, _ := hub.Subscribe(Filter{}, 0)
+
+	hub.Publish(models.Order{OrderUID: "1"})
+	hub.Publish(models.Order{OrderUID: "2"})
+
+	<-sub.C
+	_, ok := <-sub.C
+	assert.False(t, ok, "канал медленного подписчика должен быть закрыт")
+	hub.Unsubscribe(sub) // повторная отписка безопасна
+}
+
+// По Last-Event-ID отдаются только более новые события из кольцевого буфера.
+func TestHub_ResumeFromRing(t *testing.T) {
+	hub := NewHub(4, 3)
+	for _, uid := range []string{"1", "2", "3", "4", "5"} {
+		hub.Publish(models.Order{OrderUID: uid})
+	}
+
+	_, backlog := hub.Subscribe(Filter{}, 3)
+
+	ids := make([]uint64, 0, len(backlog))
+	for _, e := range backlog {
+		ids = append(ids, e.ID)
+	}
+	assert.Equal(t, []uint64{4, 5}, ids)
+}
+
+// В поток и историю попадает только маскированный JSON заказа.
+func TestHub_PublishMasked(t *testing.T) {
+	//1. Arrange(подготовка)
+	hub := NewHub(4, 10)
+	sub, _ := hub.Subscribe(Filter{}, 0)
+	order := models.Order{OrderUID: "1"}
+	order.Delivery.Phone = "+79991234567"
+	order.Delivery.Email = "ivan@example.com"
+
+	//2. Act(Действие)
+	hub.Publish(order)
+	hub.Publish(order)
+	_, backlog := hub.Subscribe(Filter{}, 1)
+
+	//3. Assert
+	assert.Len(t, backlog, 1)
+	for _, data := range []string{string((<-sub.C).Data), string(backlog[0].Data)} {
+		assert.Contains(t, data, `"order_uid":"1"`)
+		assert.NotContains(t, data, "+79991234567")
+		assert.NotContains(t, data, "ivan@example.com")
+	}
+}
@@ -0,0 +1,34 @@
+package supervisor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// Retry - вызывает fn, пока она не завершится успешно, но не больше attempts раз;
+// паузы между попытками растут по b. Возвращает последнюю ошибку или ошибку ctx.
+func Retry(ctx context.Context, name string, attempts int, b Backoff, fn func(ctx context.Context) error) error {
+	var delay time.Duration
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+		if attempt >= attempts {
+			return fmt.Errorf("%s недоступен после %d попыток: %w", name, attempts, err)
+		}
+		delay = b.next(delay)
+		slog.Warn("зависимость недоступна, повтор",
+			slog.String("dependency", name),
+			slog.Int("attempt", attempt),
+			slog.Duration("backoff", delay),
+			slog.Any("error", err))
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,195 @@
+// Package supervisor - жизненный цикл компонентов сервиса: запуск в порядке
+// зависимостей, остановка в обратном порядке с общим дедлайном, перезапуск
+// упавших компонентов с backoff или контролируемая остановка всего сервиса.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"wb-project/internal/metric"
+)
+
+// Component - часть сервиса. Все поля, кроме Name, необязательны.
+type Component struct {
+	Name string
+	// Start - синхронная инициализация; следующий компонент запускается только после нее.
+	// Ошибка прерывает запуск, уже запущенные компоненты останавливаются.
+	Start func(ctx context.Context) error
+	// Run - фоновая работа до отмены ctx. Возврат до остановки сервиса считается падением.
+	Run func(ctx context.Context) error
+	// Stop - освобождение ресурсов после завершения Run, не дольше ctx.
+	Stop func(ctx context.Context) error
+	// Restart - перезапуск Run после падения; nil - падение останавливает сервис.
+	Restart *Backoff
+}
+
+// Backoff - пауза перед перезапуском растет от Min вдвое до Max и сбрасывается,
+// если компонент проработал дольше Max.
+type Backoff struct {
+	Min time.Duration
+	Max time.Duration
+}
+
+func (b *Backoff) next(prev time.Duration) time.Duration {
+	if prev <= 0 {
+		return b.Min
+	}
+	return min(prev*2, b.Max)
+}
+
+// Supervisor - набор компонентов в порядке зависимостей: каждый следующий
+// может пользоваться предыдущими.
+type Supervisor struct {
+	components []Component
+	timeout    time.Duration
+}
+
+// New - timeout - общий дедлайн остановки всех компонентов.
+func New(timeout time.Duration) *Supervisor {
+	return &Supervisor{timeout: timeout}
+}
+
+// Add - добавляет компонент после уже добавленных.
+func (s *Supervisor) Add(c Component) *Supervisor {
+	s.components = append(s.components, c)
+	return s
+}
+
+// Blocking - Run для серверов, которые не принимают ctx и останавливаются только через Stop:
+// отмена ctx отпускает супервизор, а сам сервер завершается в Stop.
+func Blocking(serve func() error) func(ctx context.Context) error {
+	return func(ctx context.Context) error {
+		errs := make(chan error, 1)
+		go func() { errs <- serve() }()
+		select {
+		case err := <-errs:
+			return err
+		case <-ctx.Done():
+			return nil
+		}
+	}
+}
+
+// running - запущенный компонент.
+type running struct {
+	Component
+	cancel context.CancelFunc
+	done   chan struct{} // закрывается, когда Run окончательно завершился
+}
+
+// Run - запускает компоненты и ждет отмены ctx или падения компонента без политики
+// перезапуска, затем останавливает все в обратном порядке. Возвращает причину
+// остановки: nil при штатной отмене ctx.
+func (s *Supervisor) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	// первая причина падения; остальные отбрасываются
+	failures := make(chan error, 1)
+	fail := func(err error) {
+		select {
+		case failures <- err:
+		default:
+		}
+		cancel()
+	}
+
+	//1. Запуск по порядку
+	started := make([]*running, 0, len(s.components))
+	for _, c := range s.components {
+		if ctx.Err() != nil {
+			break
+		}
+		if c.Start != nil {
+			if err := c.Start(ctx); err != nil {
+				fail(fmt.Errorf("запуск %s: %w", c.Name, err))
+				break
+			}
+		}
+		r := &running{Component: c, done: make(chan struct{})}
+		var runCtx context.Context
+		runCtx, r.cancel = context.WithCancel(context.WithoutCancel(ctx))
+		started = append(started, r)
+		if c.Run == nil {
+			close(r.done)
+			continue
+		}
+		go s.supervise(runCtx, ctx, r, fail)
+		slog.Info("компонент запущен", slog.String("component", c.Name))
+	}
+
+	//2. Ждем сигнала остановки или падения
+	<-ctx.Done()
+	var failure error
+	select {
+	case failure = <-failures:
+	default:
+	}
+	if failure != nil {
+		slog.Error("компонент упал, сервис останавливается", slog.Any("error", failure))
+	}
+
+	//3. Остановка в обратном порядке с общим дедлайном
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), s.timeout)
+	defer stopCancel()
+	for i := len(started) - 1; i >= 0; i-- {
+		s.stop(stopCtx, started[i])
+	}
+	return failure
+}
+
+// supervise - Run компонента с перезапуском по его политике.
+// runCtx отменяется при остановке компонента, serviceCtx - при остановке сервиса.
+func (s *Supervisor) supervise(runCtx, serviceCtx context.Context, r *running, fail func(error)) {
+	defer close(r.done)
+	var delay time.Duration
+	for {
+		began := time.Now()
+		err := r.Run(runCtx)
+		if runCtx.Err() != nil || serviceCtx.Err() != nil {
+			return // штатная остановка
+		}
+		if err == nil {
+			err = errors.New("завершился раньше остановки сервиса")
+		}
+		if r.Restart == nil {
+			fail(fmt.Errorf("%s: %w", r.Name, err))
+			return
+		}
+
+		if time.Since(began) > r.Restart.Max {
+			delay = 0 // проработал долго - это новый сбой, а не продолжение старого
+		}
+		delay = r.Restart.next(delay)
+		metric.ComponentRestartsTotal.WithLabelValues(r.Name).Inc()
+		slog.Error("компонент упал, перезапуск",
+			slog.String("component", r.Name),
+			slog.Duration("backoff", delay),
+			slog.Any("error", err))
+		select {
+		case <-time.After(delay):
+		case <-runCtx.Done():
+			return
+		}
+	}
+}
+
+// stop - отменяет Run, дожидается его завершения и вызывает Stop.
+func (s *Supervisor) stop(ctx context.Context, r *running) {
+	start := time.Now()
+	r.cancel()
+	select {
+	case <-r.done:
+	case <-ctx.Done():
+		slog.Error("компонент не завершился до дедлайна остановки", slog.String("component", r.Name))
+	}
+	if r.Stop != nil {
+		if err := r.Stop(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			slog.Error("ошибка остановки компонента", slog.String("component", r.Name), slog.Any("error", err))
+		}
+	}
+	slog.Info("компонент остановлен", slog.String("component", r.Name), slog.Duration("took", time.Since(start)))
+}
@@ -0,0 +1,186 @@
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// journal - порядок событий запуска и остановки.
+type journal struct {
+	mu     sync.Mutex
+	events []string
+}
+
+func (j *journal) add(event string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.events = append(j.events, event)
+}
+
+func (j *journal) list() []string {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return append([]string(nil), j.events...)
+}
+
+// component - компонент, который пишет свои Start/Stop в журнал и работает до отмены.
+func (j *journal) component(name string) Component {
+	return Component{
+		Name:  name,
+		Start: func(context.Context) error { j.add("start " + name); return nil },
+		Run: func(ctx context.Context) error {
+			<-ctx.Done()
+			return ctx.Err()
+		},
+		Stop: func(context.Context) error { j.add("stop " + name); return nil },
+	}
+}
+
+func TestSupervisor(t *testing.T) {
+	t.Run("Запуск по порядку, остановка в обратном", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		j := &journal{}
+		sv := New(time.Second).Add(j.component("db")).Add(j.component("consumer")).Add(j.component("http"))
+		ctx, cancel := context.WithCancel(context.Background())
+
+		//2. Act(Действие)
+		done := make(chan error)
+		go func() { done <- sv.Run(ctx) }()
+		require.Eventually(t, func() bool { return len(j.list()) == 3 }, time.Second, time.Millisecond)
+		cancel()
+
+		//3. Assert
+		assert.NoError(t, <-done)
+		assert.Equal(t, []string{
+			"start db", "start consumer", "start http",
+			"stop http", "stop consumer", "stop db",
+		}, j.list())
+	})
+
+	t.Run("Падение компонента останавливает сервис", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		j := &journal{}
+		failing := j.component("http")
+		failing.Run = func(context.Context) error { return errors.New("address already in use") }
+		sv := New(time.Second).Add(j.component("db")).Add(failing)
+
+		//2. Act(Действие)
+		err := sv.Run(context.Background())
+
+		//3. Assert
+		assert.ErrorContains(t, err, "http: address already in use")
+		assert.Equal(t, []string{"start db", "start http", "stop http", "stop db"}, j.list())
+	})
+
+	t.Run("Ошибка Start прерывает запуск", func(t *testing.T) {
+		j := &journal{}
+		broken := j.component("consumer")
+		broken.Start = func(context.Context) error { return errors.New("нет брокеров") }
+		sv := New(time.Second).Add(j.component("db")).Add(broken).Add(j.component("http"))
+
+		err := sv.Run(context.Background())
+
+		assert.ErrorContains(t, err, "запуск consumer: нет брокеров")
+		assert.Equal(t, []string{"start db", "stop db"}, j.list())
+	})
+
+	t.Run("Перезапуск с backoff", func(t *testing.T) {
+		//1. Arrange(подготовка)
+		var runs atomic.Int32
+		ctx, cancel := context.WithCancel(context.Background())
+		sv := New(time.Second).Add(Component{
+			Name: "consumer",
+			Run: func(ctx context.Context) error {
+				if runs.Add(1) < 3 {
+					return errors.New("брокер недоступен")
+				}
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			Restart: &Backoff{Min: time.Millisecond, Max: 10 * time.Millisecond},
+		})
+
+		//2. Act(Действие)
+		done := make(chan error)
+		go func() { done <- sv.Run(ctx) }()
+		require.Eventually(t, func() bool { return runs.Load() == 3 }, time.Second, time.Millisecond)
+		cancel()
+
+		//3. Assert
+		assert.NoError(t, <-done)
+	})
+
+	t.Run("Дедлайн остановки не ждет зависший компонент", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		release := make(chan struct{})
+		defer close(release)
+		sv := New(20 * time.Millisecond).Add(Component{
+			Name: "stuck",
+			Run:  func(context.Context) error { <-release; return nil },
+		})
+
+		start := time.Now()
+		err := sv.Run(ctx)
+
+		assert.NoError(t, err)
+		assert.Less(t, time.Since(start), time.Second)
+	})
+}
+
+func TestBackoff(t *testing.T) {
+	b := &Backoff{Min: time.Second, Max: 5 * time.Second}
+
+	assert.Equal(t, time.Second, b.next(0))
+	assert.Equal(t, 4*time.Second, b.next(2*time.Second))
+	assert.Equal(t, 5*time.Second, b.next(4*time.Second))
+}
+
+func TestRetry(t *testing.T) {
+	backoff := Backoff{Min: time.Millisecond, Max: 2 * time.Millisecond}
+
+	t.Run("Зависимость поднялась со второй попытки", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(context.Background(), "database", 3, backoff, func(context.Context) error {
+			calls++
+			if calls < 2 {
+				return errors.New("connection refused")
+			}
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 2, calls)
+	})
+
+	t.Run("Попытки ограничены", func(t *testing.T) {
+		calls := 0
+
+		err := Retry(context.Background(), "kafka", 3, backoff, func(context.Context) error {
+			calls++
+			return errors.New("brokers not available")
+		})
+
+		assert.ErrorContains(t, err, "kafka недоступен после 3 попыток: brokers not available")
+		assert.Equal(t, 3, calls)
+	})
+
+	t.Run("Отмена прерывает ожидание", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		err := Retry(ctx, "database", 3, Backoff{Min: time.Hour, Max: time.Hour}, func(context.Context) error {
+			return errors.New("connection refused")
+		})
+
+		assert.ErrorIs(t, err, context.Canceled)
+	})
+}
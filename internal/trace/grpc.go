@@ -0,0 +1,94 @@
+package trace
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	oteltrace "go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// metadataCarrier - доступ к gRPC metadata для пропагатора OpenTelemetry.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+	if v := metadata.MD(c).Get(key); len(v) > 0 {
+		return v[0]
+	}
+	return ""
+}
+
+func (c metadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+	keys := make([]string, 0, len(c))
+	for k := range c {
+		keys = append(keys, k)
+	}
+	return keys
+}
+
+// UnaryServerInterceptor - спан на каждый унарный вызов с контекстом из входящих metadata.
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, span := startServerSpan(ctx, info.FullMethod)
+		defer span.End()
+
+		resp, err := handler(ctx, req)
+		finishServerSpan(span, err)
+		return resp, err
+	}
+}
+
+// StreamServerInterceptor - спан на весь серверный стрим.
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, span := startServerSpan(ss.Context(), info.FullMethod)
+		defer span.End()
+
+		err := handler(srv, &tracedStream{ServerStream: ss, ctx: ctx})
+		finishServerSpan(span, err)
+		return err
+	}
+}
+
+type tracedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *tracedStream) Context() context.Context {
+	return s.ctx
+}
+
+func startServerSpan(ctx context.Context, fullMethod string) (context.Context, oteltrace.Span) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	ctx = otel.GetTextMapPropagator().Extract(ctx, metadataCarrier(md.Copy()))
+
+	// fullMethod имеет вид /order.v1.OrderService/GetOrder
+	service, method, _ := strings.Cut(strings.TrimPrefix(fullMethod, "/"), "/")
+	return otel.Tracer("grpc").Start(ctx, fullMethod,
+		oteltrace.WithSpanKind(oteltrace.SpanKindServer),
+		oteltrace.WithAttributes(
+			semconv.RPCSystemKey.String("grpc"),
+			semconv.RPCService(service),
+			semconv.RPCMethod(method),
+		))
+}
+
+func finishServerSpan(span oteltrace.Span, err error) {
+	st, _ := status.FromError(err)
+	span.SetAttributes(attribute.Int("rpc.grpc.status_code", int(st.Code())))
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, st.Message())
+	}
+}
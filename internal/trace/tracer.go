@@ -2,36 +2,60 @@ package trace
 
 import (
 	"context"
+	"wb-project/internal/config"
+	"wb-project/internal/models"
+	"wb-project/internal/pii"
 
 	"go.opentelemetry.io/otel"
 	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/propagation"
 	"go.opentelemetry.io/otel/sdk/resource"
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 )
 
-func InitTracer(ctx context.Context) (*sdktrace.TracerProvider, error) {
-	//настройка протокола отправки данных, использует http без шифрования
-	exporter, err := otlptracehttp.New(ctx, otlptracehttp.WithInsecure())
-	if err != nil {
-		return nil, err
-	}
+// InitTracer настраивает экспорт трейсов и W3C-пропагацию контекста
+// (traceparent/baggage), через которую трейс переходит из продюсера в консьюмер.
+// При выключенном трейсинге провайдер ничего не экспортирует, но пропагация остается.
+func InitTracer(ctx context.Context, conf config.TracingConfig) (*sdktrace.TracerProvider, error) {
+	serviceName := conf.ServiceName
 
 	//ресурс позволяет определить наш сервис
 	res, err := resource.New(ctx,
 		resource.WithAttributes(
-			semconv.ServiceNameKey.String("wb-order-service"),
+			semconv.ServiceNameKey.String(serviceName),
 		),
 	)
 	if err != nil {
 		return nil, err
 	}
 
-	tp := sdktrace.NewTracerProvider(
-		sdktrace.WithBatcher(exporter),
+	opts := []sdktrace.TracerProviderOption{
 		sdktrace.WithResource(res),
-	)
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(conf.SampleRatio))),
+	}
+	if conf.Enabled {
+		//настройка протокола отправки данных
+		var exporterOpts []otlptracehttp.Option
+		if conf.Endpoint != "" {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithEndpoint(conf.Endpoint))
+		}
+		if conf.Insecure {
+			exporterOpts = append(exporterOpts, otlptracehttp.WithInsecure())
+		}
+		exporter, err := otlptracehttp.New(ctx, exporterOpts...)
+		if err != nil {
+			return nil, err
+		}
+		// персональные данные не должны попадать в коллектор трейсов
+		opts = append(opts, sdktrace.WithBatcher(pii.NewSpanExporter(exporter, pii.Keys(models.Order{}))))
+	}
+	tp := sdktrace.NewTracerProvider(opts...)
 
 	otel.SetTracerProvider(tp)
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
 	return tp, nil
 }
@@ -0,0 +1,393 @@
+'use strict';
+
+// Веб-консоль заказов: поиск по UID, постраничный список с фильтрами и лента новых заказов.
+// Работает только с публичным API /api/v1, ошибки показывает по машиночитаемому code.
+
+const API = '/api/v1';
+const LIVE_LIMIT = 100;      // сколько строк держим в ленте
+const RECONNECT_MS = 3000;   // пауза перед переподключением ленты
+
+const $ = (id) => document.getElementById(id);
+
+// ---------- API ----------
+
+class APIError extends Error {
+    constructor(status, code, message, retryAfter) {
+        super(message);
+        this.status = status;
+        this.code = code;
+        this.retryAfter = retryAfter;
+    }
+}
+
+function headers(extra) {
+    const h = Object.assign({'Accept': 'application/json'}, extra);
+    const key = $('apiKey').value.trim();
+    if (key) {
+        h['X-API-Key'] = key;
+    }
+    return h;
+}
+
+// apiError - ошибка из тела {"code", "error"}; тело может быть и не JSON (например, от прокси).
+async function apiError(resp) {
+    let body = {};
+    try {
+        body = await resp.json();
+    } catch (e) {
+        // не JSON - остается только статус
+    }
+    return new APIError(resp.status, body.code || '', body.error || resp.statusText, resp.headers.get('Retry-After'));
+}
+
+async function api(path) {
+    const resp = await fetch(API + path, {headers: headers()});
+    if (!resp.ok) {
+        throw await apiError(resp);
+    }
+    return resp.json();
+}
+
+// ---------- ошибки ----------
+
+const errorTexts = {
+    order_not_found: () => 'Заказ не найден. Проверьте UID.',
+    invalid_id: (e) => 'Некорректный UID: ' + e.message,
+    invalid_request: (e) => 'Некорректный запрос: ' + e.message,
+    unauthorized: () => 'Нужен API-ключ, или указанный ключ недействителен.',
+    forbidden: (e) => 'Недостаточно прав: ' + e.message,
+    rate_limited: (e) => 'Слишком много запросов.' + (e.retryAfter ? ` Повторите через ${e.retryAfter} с.` : ''),
+    not_acceptable: () => 'Сервер не может отдать ответ в формате JSON.',
+    internal_error: () => 'Внутренняя ошибка сервера, попробуйте позже.',
+};
+
+function showError(err) {
+    const box = $('error');
+    box.replaceChildren();
+    let text = err.message;
+    if (err instanceof APIError) {
+        const describe = errorTexts[err.code];
+        text = describe ? describe(err) : `Ошибка ${err.status}: ${err.message}`;
+    } else if (err instanceof TypeError) {
+        text = 'Сервер недоступен: ' + err.message;
+    }
+    box.append(text);
+    if (err.code) {
+        const code = document.createElement('code');
+        code.textContent = ` [${err.code}]`;
+        box.append(code);
+    }
+    box.hidden = false;
+}
+
+function clearError() {
+    $('error').hidden = true;
+}
+
+// ---------- форматирование ----------
+
+function formatDate(value) {
+    const d = new Date(value);
+    return isNaN(d) ? value : d.toLocaleString('ru-RU');
+}
+
+function money(value, currency) {
+    return `${Number(value).toLocaleString('ru-RU')} ${currency || ''}`.trim();
+}
+
+function cell(text, className) {
+    const td = document.createElement('td');
+    td.textContent = text ?? '';
+    if (className) {
+        td.className = className;
+    }
+    return td;
+}
+
+function fillList(dl, pairs) {
+    dl.replaceChildren();
+    for (const [label, value] of pairs) {
+        const dt = document.createElement('dt');
+        dt.textContent = label;
+        const dd = document.createElement('dd');
+        dd.textContent = value ?? '';
+        dl.append(dt, dd);
+    }
+}
+
+function orderRow(order) {
+    const tr = document.createElement('tr');
+    tr.append(
+        cell(order.order_uid),
+        cell(formatDate(order.date_created)),
+        cell(order.entry),
+        cell(order.delivery_service),
+        cell(order.customer_id),
+        cell(money(order.payment?.amount ?? 0, order.payment?.currency), 'num'),
+    );
+    tr.addEventListener('click', () => openOrder(order.order_uid));
+    return tr;
+}
+
+// ---------- карточка заказа ----------
+
+function renderDetail(order) {
+    $('detailTitle').textContent = 'Заказ ' + order.order_uid;
+    fillList($('detailSummary'), [
+        ['Трек-номер', order.track_number],
+        ['Создан', formatDate(order.date_created)],
+        ['Entry', order.entry],
+        ['Покупатель', order.customer_id],
+        ['Служба доставки', order.delivery_service],
+        ['Локаль', order.locale],
+        ['Шард', `${order.shard_key} / oof ${order.oof_shard}`],
+    ]);
+
+    const d = order.delivery || {};
+    fillList($('detailDelivery'), [
+        ['Получатель', d.name],
+        ['Телефон', d.phone],
+        ['Email', d.email],
+        ['Город', [d.zip, d.city].filter(Boolean).join(', ')],
+        ['Адрес', d.address],
+        ['Регион', d.region],
+    ]);
+
+    const p = order.payment || {};
+    fillList($('detailPayment'), [
+        ['Транзакция', p.transaction],
+        ['Провайдер', p.provider],
+        ['Банк', p.bank],
+        ['Товары', money(p.goods_total, p.currency)],
+        ['Доставка', money(p.delivery_cost, p.currency)],
+        ['Пошлина', money(p.custom_fee, p.currency)],
+        ['Итого', money(p.amount, p.currency)],
+        ['Оплачено', p.payment_dt ? formatDate(p.payment_dt * 1000) : ''],
+    ]);
+
+    const items = $('detailItems');
+    items.replaceChildren();
+    for (const item of order.items || []) {
+        const tr = document.createElement('tr');
+        tr.append(
+            cell(item.name),
+            cell(item.brand),
+            cell(item.size),
+            cell(money(item.price, p.currency), 'num'),
+            cell(item.sale ? item.sale + '%' : '', 'num'),
+            cell(money(item.total_price, p.currency), 'num'),
+            cell(item.status),
+        );
+        items.append(tr);
+    }
+
+    $('detailJson').textContent = JSON.stringify(order, null, 2);
+    $('detail').hidden = false;
+    $('detail').scrollIntoView({behavior: 'smooth'});
+}
+
+async function openOrder(uid) {
+    clearError();
+    try {
+        renderDetail(await api('/orders/' + encodeURIComponent(uid)));
+        history.replaceState(null, '', '#' + encodeURIComponent(uid));
+    } catch (err) {
+        $('detail').hidden = true;
+        showError(err);
+    }
+}
+
+// ---------- список ----------
+
+const list = {
+    query: null,   // фильтры текущего поиска
+    tokens: [''],  // page_token каждой открытой страницы, для перехода назад
+    next: '',
+};
+
+async function loadPage() {
+    clearError();
+    const params = new URLSearchParams(list.query);
+    const token = list.tokens[list.tokens.length - 1];
+    if (token) {
+        params.set('page_token', token);
+    }
+    try {
+        const page = await api('/orders?' + params);
+        $('listBody').replaceChildren(...page.orders.map(orderRow));
+        list.next = page.next_page_token || '';
+        $('pageInfo').textContent = `Страница ${list.tokens.length}` + (page.orders.length ? '' : ' — ничего не найдено');
+    } catch (err) {
+        list.next = '';
+        showError(err);
+    }
+    $('prevPage').disabled = list.tokens.length < 2;
+    $('nextPage').disabled = !list.next;
+}
+
+function search(form) {
+    const query = {};
+    for (const [name, value] of new FormData(form)) {
+        if (value.trim()) {
+            query[name] = value.trim();
+        }
+    }
+    list.query = query;
+    list.tokens = [''];
+    loadPage();
+}
+
+// ---------- лента новых заказов ----------
+
+// EventSource не умеет передавать заголовки, поэтому SSE читается через fetch:
+// так работает и API-ключ, и докачка пропущенного по Last-Event-ID.
+const live = {
+    controller: null,
+    lastEventID: '',
+    timer: null,
+};
+
+function liveStatus(text) {
+    $('liveStatus').textContent = text;
+}
+
+function handleEvent(block) {
+    let id = '', event = 'message', data = '';
+    for (const line of block.split('\n')) {
+        if (line.startsWith('id:')) id = line.slice(3).trim();
+        else if (line.startsWith('event:')) event = line.slice(6).trim();
+        else if (line.startsWith('data:')) data += line.slice(5).trim();
+    }
+    if (id) {
+        live.lastEventID = id;
+    }
+    if (event !== 'order' || !data) {
+        return; // heartbeat
+    }
+    const row = orderRow(JSON.parse(data));
+    row.className = 'fresh';
+    const body = $('liveBody');
+    body.prepend(row);
+    while (body.rows.length > LIVE_LIMIT) {
+        body.deleteRow(-1);
+    }
+}
+
+async function connectLive() {
+    const params = new URLSearchParams();
+    if ($('liveEntry').value.trim()) params.set('entry', $('liveEntry').value.trim());
+    if ($('liveService').value.trim()) params.set('delivery_service', $('liveService').value.trim());
+
+    const controller = new AbortController();
+    live.controller = controller;
+    const extra = {'Accept': 'text/event-stream'};
+    if (live.lastEventID) {
+        extra['Last-Event-ID'] = live.lastEventID;
+    }
+    try {
+        const resp = await fetch(`${API}/orders/stream?${params}`, {headers: headers(extra), signal: controller.signal});
+        if (!resp.ok) {
+            throw await apiError(resp);
+        }
+        clearError();
+        liveStatus('подключено');
+        const reader = resp.body.pipeThrough(new TextDecoderStream()).getReader();
+        let buffer = '';
+        for (;;) {
+            const {value, done} = await reader.read();
+            if (done) break;
+            buffer += value;
+            let end;
+            while ((end = buffer.indexOf('\n\n')) >= 0) {
+                handleEvent(buffer.slice(0, end));
+                buffer = buffer.slice(end + 2);
+            }
+        }
+        throw new Error('соединение закрыто сервером');
+    } catch (err) {
+        if (controller.signal.aborted) {
+            return;
+        }
+        if (err instanceof APIError && err.status !== 429 && err.status < 500) {
+            // без ключа или прав повтор не поможет
+            showError(err);
+            stopLive();
+            return;
+        }
+        liveStatus('переподключение…');
+        live.timer = setTimeout(connectLive, err.retryAfter ? err.retryAfter * 1000 : RECONNECT_MS);
+    }
+}
+
+function stopLive() {
+    clearTimeout(live.timer);
+    if (live.controller) {
+        live.controller.abort();
+        live.controller = null;
+    }
+    $('liveToggle').textContent = 'Подключиться';
+    liveStatus('отключено');
+}
+
+// ---------- навигация ----------
+
+function showView(name) {
+    for (const tab of document.querySelectorAll('.tab')) {
+        tab.classList.toggle('active', tab.dataset.view === name);
+    }
+    for (const view of document.querySelectorAll('.view')) {
+        view.hidden = view.id !== 'view-' + name;
+    }
+    clearError();
+}
+
+document.addEventListener('DOMContentLoaded', () => {
+    const key = $('apiKey');
+    key.value = sessionStorage.getItem('apiKey') || '';
+    key.addEventListener('change', () => sessionStorage.setItem('apiKey', key.value.trim()));
+
+    for (const tab of document.querySelectorAll('.tab')) {
+        tab.addEventListener('click', () => showView(tab.dataset.view));
+    }
+
+    $('lookupForm').addEventListener('submit', (e) => {
+        e.preventDefault();
+        openOrder($('orderUid').value.trim());
+    });
+
+    $('listForm').addEventListener('submit', (e) => {
+        e.preventDefault();
+        search(e.target);
+    });
+    $('nextPage').addEventListener('click', () => {
+        list.tokens.push(list.next);
+        loadPage();
+    });
+    $('prevPage').addEventListener('click', () => {
+        list.tokens.pop();
+        loadPage();
+    });
+
+    $('liveToggle').addEventListener('click', () => {
+        if (live.controller) {
+            stopLive();
+            return;
+        }
+        live.lastEventID = '';
+        $('liveToggle').textContent = 'Отключиться';
+        liveStatus('подключение…');
+        connectLive();
+    });
+
+    $('detailClose').addEventListener('click', () => {
+        $('detail').hidden = true;
+        history.replaceState(null, '', location.pathname);
+    });
+
+    // ссылка вида /#<order_uid> сразу открывает заказ
+    if (location.hash.length > 1) {
+        const uid = decodeURIComponent(location.hash.slice(1));
+        $('orderUid').value = uid;
+        openOrder(uid);
+    }
+});
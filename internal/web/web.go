@@ -0,0 +1,29 @@
+// Package web - встроенная веб-консоль заказов. Файлы зашиты в бинарник,
+// поэтому консоль работает независимо от рабочего каталога процесса.
+package web
+
+import (
+	"embed"
+	"io/fs"
+)
+
+//go:embed assets
+var assets embed.FS
+
+// Assets - файлы консоли (index.html, console.js, console.css) от корня.
+func Assets() fs.FS {
+	sub, err := fs.Sub(assets, "assets")
+	if err != nil {
+		panic(err) // каталог зашит при сборке, ошибка возможна только при опечатке в пути
+	}
+	return sub
+}
+
+// Index - стартовая страница консоли.
+func Index() []byte {
+	data, err := assets.ReadFile("assets/index.html")
+	if err != nil {
+		panic(err)
+	}
+	return data
+}
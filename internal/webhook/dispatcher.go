@@ -0,0 +1,247 @@
+// Package webhook реализует исходящие вебхуки: подписанные HMAC-SHA256 HTTP callback'и
+// о событиях заказов с повторными попытками, журналом доставок и автоотключением подписчиков.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/metric"
+	"wb-project/internal/models"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Store - хранилище очереди доставок.
+//
+//go:generate mockery --name=Store --output=./mocks --case=underscore
+type Store interface {
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTask, error)
+	RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, disableAfter int) (bool, error)
+	DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Orders - источник заказов для тела события: в очереди доставок хранится
+// только order_uid, а не копия заказа с персональными данными.
+//
+//go:generate mockery --name=Orders --output=./mocks --case=underscore
+type Orders interface {
+	Get(ctx context.Context, uid string) (models.Order, error)
+}
+
+// Dispatcher периодически забирает доставки, время которых пришло, и отправляет их подписчикам.
+type Dispatcher struct {
+	store  Store
+	orders Orders
+	client *http.Client
+	cfg    config.WebhookConfig
+}
+
+func NewDispatcher(store Store, orders Orders, cfg config.WebhookConfig) *Dispatcher {
+	return &Dispatcher{
+		store:  store,
+		orders: orders,
+		client: &http.Client{Timeout: cfg.Timeout},
+		cfg:    cfg,
+	}
+}
+
+// Run - работает до отмены ctx.
+func (d *Dispatcher) Run(ctx context.Context) error {
+	slog.Info("Запуск диспетчера вебхуков", slog.Duration("poll_interval", d.cfg.PollInterval))
+	ticker := time.NewTicker(d.cfg.PollInterval)
+	defer ticker.Stop()
+	cleanup := time.NewTicker(d.cfg.CleanupInterval)
+	defer cleanup.Stop()
+
+	for {
+		if err := d.DispatchOnce(ctx); err != nil {
+			slog.Error("ошибка обработки очереди вебхуков", slog.Any("error", err))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-cleanup.C:
+			d.cleanup(ctx)
+		case <-ticker.C:
+		}
+	}
+}
+
+// DispatchOnce - отправляет один батч доставок.
+func (d *Dispatcher) DispatchOnce(ctx context.Context) error {
+	// аренда с запасом на таймаут запроса, чтобы доставку не взял другой экземпляр
+	tasks, err := d.store.ClaimDue(ctx, d.cfg.BatchSize, 2*d.cfg.Timeout)
+	if err != nil {
+		return err
+	}
+	for _, task := range tasks {
+		if err := d.deliver(ctx, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, task models.WebhookTask) error {
+	delivery := task.Delivery
+	body, err := d.body(ctx, delivery)
+	if err != nil && (errors.Is(err, models.ErrUnavailable) || ctx.Err() != nil) {
+		// БД недоступна: попытка не тратится, доставка будет взята снова, когда истечет аренда
+		return fmt.Errorf("не удалось собрать тело доставки %d: %w", delivery.ID, err)
+	}
+	delivery.Attempts++
+	attempt := models.WebhookAttempt{
+		DeliveryID: delivery.ID,
+		Attempt:    delivery.Attempts,
+	}
+
+	var sendErr error
+	if err != nil {
+		// заказ удален или не читается: запрос не отправляется, но попытка тратится,
+		// иначе такая доставка возвращалась бы в каждый батч
+		attempt.NotSent = true
+		sendErr = fmt.Errorf("не удалось собрать тело события: %w", err)
+	} else {
+		attempt.StatusCode, attempt.Duration, sendErr = d.send(ctx, task.URL, task.Secret, delivery.EventType,
+			strconv.FormatInt(delivery.ID, 10), body)
+	}
+
+	delivery.LastStatusCode = attempt.StatusCode
+	if sendErr == nil {
+		now := time.Now()
+		delivery.Status = models.DeliveryDelivered
+		delivery.DeliveredAt = &now
+		delivery.LastError = ""
+		metric.WebhookDeliveriesTotal.WithLabelValues("delivered").Inc()
+	} else {
+		attempt.Error = sendErr.Error()
+		delivery.LastError = sendErr.Error()
+		if delivery.Attempts >= d.cfg.MaxAttempts {
+			delivery.Status = models.DeliveryFailed
+			metric.WebhookDeliveriesTotal.WithLabelValues("failed").Inc()
+		} else {
+			delivery.Status = models.DeliveryPending
+			delivery.NextAttemptAt = time.Now().Add(d.backoff(delivery.Attempts))
+			metric.WebhookDeliveriesTotal.WithLabelValues("retry").Inc()
+		}
+	}
+
+	disabled, err := d.store.RecordAttempt(ctx, delivery, attempt, d.cfg.DisableAfter)
+	if err != nil {
+		return fmt.Errorf("не удалось сохранить результат доставки %d: %w", delivery.ID, err)
+	}
+	if disabled {
+		slog.Warn("подписка на вебхуки отключена после серии неудачных доставок",
+			slog.Int64("subscription_id", delivery.SubscriptionID),
+			slog.String("url", task.URL))
+	}
+	return nil
+}
+
+// body - тело события из текущего состояния заказа.
+func (d *Dispatcher) body(ctx context.Context, delivery models.WebhookDelivery) ([]byte, error) {
+	order, err := d.orders.Get(ctx, delivery.OrderUID)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(models.WebhookEvent{
+		Event:      delivery.EventType,
+		OrderUID:   delivery.OrderUID,
+		OccurredAt: delivery.CreatedAt.UTC(),
+		Order:      &order,
+	})
+}
+
+// cleanup - удаляет завершенные доставки старше Retention вместе с журналом попыток.
+func (d *Dispatcher) cleanup(ctx context.Context) {
+	deleted, err := d.store.DeleteFinishedBefore(ctx, time.Now().Add(-d.cfg.Retention))
+	if err != nil {
+		slog.Error("ошибка очистки журнала доставок", slog.Any("error", err))
+		return
+	}
+	if deleted > 0 {
+		slog.Info("вебхуки: удалены завершенные доставки", slog.Int64("count", deleted))
+	}
+}
+
+// Ping - тестовый режим: синхронно отправляет подписчику подписанное событие ping,
+// результат в журнал доставок не попадает.
+func (d *Dispatcher) Ping(ctx context.Context, sub models.WebhookSubscription) (models.WebhookAttempt, error) {
+	body := []byte(`{"event":"` + models.WebhookPing + `","occurred_at":"` + time.Now().UTC().Format(time.RFC3339) + `"}`)
+	statusCode, duration, err := d.send(ctx, sub.URL, sub.Secret, models.WebhookPing, "ping", body)
+	attempt := models.WebhookAttempt{Attempt: 1, StatusCode: statusCode, Duration: duration}
+	if err != nil {
+		attempt.Error = err.Error()
+	}
+	return attempt, err
+}
+
+// send - POST с подписью. Успехом считается любой ответ 2xx.
+func (d *Dispatcher) send(ctx context.Context, url, secret, event, deliveryID string, body []byte) (int, time.Duration, error) {
+	tr := otel.Tracer("webhook")
+	ctx, span := tr.Start(ctx, "Webhook.Deliver", trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	span.SetAttributes(
+		attribute.String("webhook.event", event),
+		attribute.String("webhook.delivery_id", deliveryID),
+		semconv.HTTPMethod(http.MethodPost),
+		semconv.HTTPURL(url),
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return 0, 0, fmt.Errorf("некорректный запрос: %w", err)
+	}
+	ts := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wb-order-service-webhooks/1")
+	req.Header.Set(HeaderEvent, event)
+	req.Header.Set(HeaderDelivery, deliveryID)
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+	req.Header.Set(HeaderSignature, Sign(secret, ts, body))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	duration := time.Since(start)
+	metric.WebhookDuration.Observe(duration.Seconds())
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return 0, duration, err
+	}
+	span.SetAttributes(semconv.HTTPStatusCode(resp.StatusCode))
+	defer func() {
+		_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		span.SetStatus(codes.Error, resp.Status)
+		return resp.StatusCode, duration, fmt.Errorf("подписчик ответил %d", resp.StatusCode)
+	}
+	return resp.StatusCode, duration, nil
+}
+
+// backoff - экспоненциальная задержка перед попыткой attempts+1.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	wait := d.cfg.BaseBackoff
+	for i := 1; i < attempts && wait < d.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	return min(wait, d.cfg.MaxBackoff)
+}
@@ -0,0 +1,173 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+	"wb-project/internal/config"
+	"wb-project/internal/models"
+	"wb-project/internal/webhook/mocks"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func setup(t *testing.T) (*mocks.Store, *mocks.Orders, *Dispatcher) {
+	store := mocks.NewStore(t)
+	orders := mocks.NewOrders(t)
+	d := NewDispatcher(store, orders, config.WebhookConfig{
+		PollInterval:    time.Second,
+		BatchSize:       10,
+		Timeout:         time.Second,
+		MaxAttempts:     3,
+		BaseBackoff:     time.Second,
+		MaxBackoff:      10 * time.Second,
+		DisableAfter:    5,
+		Retention:       time.Hour,
+		CleanupInterval: time.Minute,
+	})
+	return store, orders, d
+}
+
+// Подписчик получает тело с корректной подписью, доставка помечается delivered.
+func TestDispatcher_DispatchOnce_Delivered(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, d := setup(t)
+	orders.On("Get", mock.Anything, "uid").Return(models.Order{OrderUID: "uid", TrackNumber: "WBILMTESTTRACK"}, nil)
+
+	var verified bool
+	var event models.WebhookEvent
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		ts, _ := strconv.ParseInt(r.Header.Get(HeaderTimestamp), 10, 64)
+		verified = Verify("secret", ts, body, r.Header.Get(HeaderSignature))
+		assert.NoError(t, json.Unmarshal(body, &event))
+		assert.Equal(t, models.WebhookOrderCreated, r.Header.Get(HeaderEvent))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	task := models.WebhookTask{
+		Delivery: models.WebhookDelivery{ID: 7, SubscriptionID: 1, EventType: models.WebhookOrderCreated, OrderUID: "uid"},
+		URL:      srv.URL,
+		Secret:   "secret",
+	}
+	store.On("ClaimDue", mock.Anything, 10, 2*time.Second).Return([]models.WebhookTask{task}, nil)
+	store.On("RecordAttempt", mock.Anything,
+		mock.MatchedBy(func(d models.WebhookDelivery) bool {
+			return d.Status == models.DeliveryDelivered && d.Attempts == 1 && d.DeliveredAt != nil
+		}),
+		mock.MatchedBy(func(a models.WebhookAttempt) bool { return a.StatusCode == http.StatusNoContent && a.Error == "" }),
+		5).Return(false, nil)
+
+	//2. Act(Действие)
+	err := d.DispatchOnce(context.Background())
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.True(t, verified)
+	assert.Equal(t, models.WebhookOrderCreated, event.Event)
+	assert.Equal(t, "WBILMTESTTRACK", event.Order.TrackNumber, "тело собирается из заказа при отправке")
+}
+
+// Ошибка подписчика планирует повтор, а после MaxAttempts доставка становится failed.
+func TestDispatcher_DispatchOnce_Retry(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, d := setup(t)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+	orders.On("Get", mock.Anything, mock.Anything).Return(models.Order{}, nil)
+
+	retry := models.WebhookTask{Delivery: models.WebhookDelivery{ID: 1, SubscriptionID: 1, Attempts: 1}, URL: srv.URL}
+	last := models.WebhookTask{Delivery: models.WebhookDelivery{ID: 2, SubscriptionID: 1, Attempts: 2}, URL: srv.URL}
+	store.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]models.WebhookTask{retry, last}, nil)
+
+	before := time.Now()
+	store.On("RecordAttempt", mock.Anything,
+		mock.MatchedBy(func(d models.WebhookDelivery) bool {
+			return d.ID == 1 && d.Status == models.DeliveryPending && d.LastStatusCode == 500 && d.NextAttemptAt.After(before.Add(2*time.Second-time.Millisecond))
+		}), mock.Anything, 5).Return(false, nil)
+	store.On("RecordAttempt", mock.Anything,
+		mock.MatchedBy(func(d models.WebhookDelivery) bool { return d.ID == 2 && d.Status == models.DeliveryFailed }),
+		mock.Anything, 5).Return(true, nil)
+
+	//2. Act(Действие)
+	err := d.DispatchOnce(context.Background())
+
+	//3. Assert
+	assert.NoError(t, err)
+	store.AssertNumberOfCalls(t, "RecordAttempt", 2)
+}
+
+// Пока БД недоступна, запрос не отправляется и попытка не тратится:
+// доставка вернется в работу, когда истечет аренда.
+func TestDispatcher_DispatchOnce_OrderUnavailable(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, d := setup(t)
+	task := models.WebhookTask{Delivery: models.WebhookDelivery{ID: 3, SubscriptionID: 1, OrderUID: "uid"}, URL: "http://127.0.0.1:0"}
+	store.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]models.WebhookTask{task}, nil)
+	orders.On("Get", mock.Anything, "uid").Return(models.Order{}, fmt.Errorf("%w: get: connection refused", models.ErrUnavailable))
+
+	//2. Act(Действие)
+	err := d.DispatchOnce(context.Background())
+
+	//3. Assert
+	assert.ErrorIs(t, err, models.ErrUnavailable)
+	assert.ErrorContains(t, err, "доставки 3")
+	store.AssertNotCalled(t, "RecordAttempt", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+// Заказ, который не прочитать, не блокирует батч: попытка записывается без запроса
+// подписчику и без штрафа подписке, последняя попытка переводит доставку в failed.
+func TestDispatcher_DispatchOnce_OrderUnreadable(t *testing.T) {
+	//1. Arrange(подготовка)
+	store, orders, d := setup(t)
+	var received int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		received++
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer srv.Close()
+
+	broken := models.WebhookTask{Delivery: models.WebhookDelivery{ID: 3, SubscriptionID: 1, OrderUID: "broken", Attempts: 2}, URL: srv.URL}
+	fine := models.WebhookTask{Delivery: models.WebhookDelivery{ID: 4, SubscriptionID: 1, OrderUID: "uid"}, URL: srv.URL}
+	store.On("ClaimDue", mock.Anything, 10, mock.Anything).Return([]models.WebhookTask{broken, fine}, nil)
+	orders.On("Get", mock.Anything, "broken").Return(models.Order{}, fmt.Errorf("заказ broken: %w: ключ данных", models.ErrUnreadable))
+	orders.On("Get", mock.Anything, "uid").Return(models.Order{OrderUID: "uid"}, nil)
+	store.On("RecordAttempt", mock.Anything,
+		mock.MatchedBy(func(dl models.WebhookDelivery) bool {
+			return dl.ID == 3 && dl.Attempts == 3 && dl.Status == models.DeliveryFailed
+		}),
+		mock.MatchedBy(func(a models.WebhookAttempt) bool {
+			return a.NotSent && a.StatusCode == 0 && a.Error != ""
+		}),
+		5).Return(false, nil).Once()
+	store.On("RecordAttempt", mock.Anything,
+		mock.MatchedBy(func(dl models.WebhookDelivery) bool { return dl.ID == 4 && dl.Status == models.DeliveryDelivered }),
+		mock.Anything, 5).Return(false, nil).Once()
+
+	//2. Act(Действие)
+	err := d.DispatchOnce(context.Background())
+
+	//3. Assert
+	assert.NoError(t, err)
+	assert.Equal(t, 1, received)
+	store.AssertNumberOfCalls(t, "RecordAttempt", 2)
+}
+
+func TestSign_Verify(t *testing.T) {
+	body := []byte(`{"a":1}`)
+	sig := Sign("secret", 100, body)
+
+	assert.True(t, Verify("secret", 100, body, sig))
+	assert.False(t, Verify("other", 100, body, sig))
+	assert.False(t, Verify("secret", 101, body, sig))
+}
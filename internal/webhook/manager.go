@@ -0,0 +1,122 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"wb-project/internal/i18n"
+	"wb-project/internal/models"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// ErrInvalid - подписка не прошла валидацию.
+var ErrInvalid = errors.New("некорректная подписка")
+
+// SubscriptionStore - хранилище подписок и журнала доставок.
+type SubscriptionStore interface {
+	CreateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetSubscription(ctx context.Context, id int64) (models.WebhookSubscription, error)
+	ListSubscriptions(ctx context.Context) ([]models.WebhookSubscription, error)
+	UpdateSubscription(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	DeleteSubscription(ctx context.Context, id int64) error
+	ListDeliveries(ctx context.Context, subscriptionID int64, limit int) ([]models.WebhookDelivery, error)
+}
+
+// SubscriptionPatch - частичное обновление подписки, nil-поля не меняются.
+type SubscriptionPatch struct {
+	URL     *string  `json:"url"`
+	Events  []string `json:"events"`
+	Enabled *bool    `json:"enabled"`
+}
+
+// Manager - управление подписками для admin API.
+type Manager struct {
+	store      SubscriptionStore
+	dispatcher *Dispatcher
+	validate   *validator.Validate
+}
+
+func NewManager(store SubscriptionStore, dispatcher *Dispatcher) *Manager {
+	return &Manager{store: store, dispatcher: dispatcher, validate: i18n.Validator()}
+}
+
+// Create - регистрирует подписчика. Если секрет не задан, он генерируется;
+// секрет возвращается только в ответе на создание.
+func (m *Manager) Create(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if sub.Secret == "" {
+		secret, err := NewSecret()
+		if err != nil {
+			return models.WebhookSubscription{}, fmt.Errorf("не удалось сгенерировать секрет: %w", err)
+		}
+		sub.Secret = secret
+	}
+	sub.Enabled = true
+	if err := m.validate.Struct(sub); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+	return m.store.CreateSubscription(ctx, sub)
+}
+
+func (m *Manager) Get(ctx context.Context, id int64) (models.WebhookSubscription, error) {
+	sub, err := m.store.GetSubscription(ctx, id)
+	sub.Secret = ""
+	return sub, err
+}
+
+func (m *Manager) List(ctx context.Context) ([]models.WebhookSubscription, error) {
+	subs, err := m.store.ListSubscriptions(ctx)
+	for i := range subs {
+		subs[i].Secret = ""
+	}
+	return subs, err
+}
+
+// Update - применяет patch. Повторное включение сбрасывает счетчик неудач.
+func (m *Manager) Update(ctx context.Context, id int64, patch SubscriptionPatch) (models.WebhookSubscription, error) {
+	sub, err := m.store.GetSubscription(ctx, id)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if patch.URL != nil {
+		sub.URL = *patch.URL
+	}
+	if patch.Events != nil {
+		sub.Events = patch.Events
+	}
+	if patch.Enabled != nil {
+		if *patch.Enabled && !sub.Enabled {
+			sub.ConsecutiveFailures = 0
+			sub.DisabledReason = ""
+		}
+		sub.Enabled = *patch.Enabled
+	}
+	if err := m.validate.Struct(sub); err != nil {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: %w", ErrInvalid, err)
+	}
+
+	updated, err := m.store.UpdateSubscription(ctx, sub)
+	updated.Secret = ""
+	return updated, err
+}
+
+func (m *Manager) Delete(ctx context.Context, id int64) error {
+	return m.store.DeleteSubscription(ctx, id)
+}
+
+func (m *Manager) Deliveries(ctx context.Context, id int64, limit int) ([]models.WebhookDelivery, error) {
+	if _, err := m.store.GetSubscription(ctx, id); err != nil {
+		return nil, err
+	}
+	return m.store.ListDeliveries(ctx, id, limit)
+}
+
+// Test - отправляет подписчику ping и возвращает результат попытки.
+func (m *Manager) Test(ctx context.Context, id int64) (models.WebhookAttempt, error) {
+	sub, err := m.store.GetSubscription(ctx, id)
+	if err != nil {
+		return models.WebhookAttempt{}, err
+	}
+	attempt, _ := m.dispatcher.Ping(ctx, sub)
+	return attempt, nil
+}
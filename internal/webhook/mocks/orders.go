@@ -0,0 +1,57 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "wb-project/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+)
+
+// Orders is an autogenerated mock type for the Orders type
+type Orders struct {
+	mock.Mock
+}
+
+// Get provides a mock function with given fields: ctx, uid
+func (_m *Orders) Get(ctx context.Context, uid string) (models.Order, error) {
+	ret := _m.Called(ctx, uid)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Get")
+	}
+
+	var r0 models.Order
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, string) (models.Order, error)); ok {
+		return rf(ctx, uid)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, string) models.Order); ok {
+		r0 = rf(ctx, uid)
+	} else {
+		r0 = ret.Get(0).(models.Order)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, string) error); ok {
+		r1 = rf(ctx, uid)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewOrders creates a new instance of Orders. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewOrders(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Orders {
+	mock := &Orders{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
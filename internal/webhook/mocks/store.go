@@ -0,0 +1,117 @@
+// Code generated by mockery v2.53.5. DO NOT EDIT.
+
+package mocks
+
+import (
+	context "context"
+	models "wb-project/internal/models"
+
+	mock "github.com/stretchr/testify/mock"
+
+	time "time"
+)
+
+// Store is an autogenerated mock type for the Store type
+type Store struct {
+	mock.Mock
+}
+
+// ClaimDue provides a mock function with given fields: ctx, limit, lease
+func (_m *Store) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookTask, error) {
+	ret := _m.Called(ctx, limit, lease)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ClaimDue")
+	}
+
+	var r0 []models.WebhookTask
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) ([]models.WebhookTask, error)); ok {
+		return rf(ctx, limit, lease)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, int, time.Duration) []models.WebhookTask); ok {
+		r0 = rf(ctx, limit, lease)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]models.WebhookTask)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, int, time.Duration) error); ok {
+		r1 = rf(ctx, limit, lease)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DeleteFinishedBefore provides a mock function with given fields: ctx, before
+func (_m *Store) DeleteFinishedBefore(ctx context.Context, before time.Time) (int64, error) {
+	ret := _m.Called(ctx, before)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteFinishedBefore")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) (int64, error)); ok {
+		return rf(ctx, before)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, time.Time) int64); ok {
+		r0 = rf(ctx, before)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, time.Time) error); ok {
+		r1 = rf(ctx, before)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// RecordAttempt provides a mock function with given fields: ctx, delivery, attempt, disableAfter
+func (_m *Store) RecordAttempt(ctx context.Context, delivery models.WebhookDelivery, attempt models.WebhookAttempt, disableAfter int) (bool, error) {
+	ret := _m.Called(ctx, delivery, attempt, disableAfter)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RecordAttempt")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, models.WebhookDelivery, models.WebhookAttempt, int) (bool, error)); ok {
+		return rf(ctx, delivery, attempt, disableAfter)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, models.WebhookDelivery, models.WebhookAttempt, int) bool); ok {
+		r0 = rf(ctx, delivery, attempt, disableAfter)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, models.WebhookDelivery, models.WebhookAttempt, int) error); ok {
+		r1 = rf(ctx, delivery, attempt, disableAfter)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// NewStore creates a new instance of Store. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewStore(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Store {
+	mock := &Store{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
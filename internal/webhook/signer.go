@@ -0,0 +1,41 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+)
+
+// Заголовки запроса вебхука.
+const (
+	HeaderSignature = "X-Webhook-Signature"
+	HeaderTimestamp = "X-Webhook-Timestamp"
+	HeaderEvent     = "X-Webhook-Event"
+	HeaderDelivery  = "X-Webhook-Delivery"
+)
+
+// Sign - подпись тела запроса: HMAC-SHA256 от "<timestamp>.<body>" в hex с префиксом "sha256=".
+// Timestamp входит в подпись, чтобы получатель мог отбрасывать повторно проигранные запросы.
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify - проверка подписи на стороне получателя, сравнение за постоянное время.
+func Verify(secret string, timestamp int64, body []byte, signature string) bool {
+	return hmac.Equal([]byte(Sign(secret, timestamp, body)), []byte(signature))
+}
+
+// NewSecret - случайный секрет подписки (32 байта в hex).
+func NewSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
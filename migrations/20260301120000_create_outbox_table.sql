@@ -0,0 +1,24 @@
+-- +goose Up
+-- +goose StatementBegin
+    CREATE TABLE outbox (
+        id bigserial primary key,
+        aggregate_id varchar not null,
+        event_type varchar not null,
+        schema_version INT not null,
+        payload jsonb not null,
+        headers jsonb not null default '{}'::jsonb,
+        created_at TIMESTAMP not null default now(),
+        sent_at TIMESTAMP,
+        attempts INT not null default 0,
+        last_error varchar
+    );
+
+    -- релей выбирает только неотправленные события в порядке записи
+    CREATE INDEX idx_outbox_pending ON outbox (id) WHERE sent_at IS NULL;
+    CREATE INDEX idx_outbox_sent_at ON outbox (sent_at) WHERE sent_at IS NOT NULL;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table outbox;
+-- +goose StatementEnd
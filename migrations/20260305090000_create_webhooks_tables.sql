@@ -0,0 +1,59 @@
+-- +goose Up
+-- +goose StatementBegin
+    CREATE TABLE webhook_subscriptions (
+        id bigserial primary key,
+        url varchar not null,
+        secret varchar not null,
+        events varchar[] not null,
+        enabled boolean not null default true,
+        consecutive_failures INT not null default 0,
+        disabled_reason varchar,
+        created_at TIMESTAMP not null default now(),
+        updated_at TIMESTAMP not null default now()
+    );
+
+    CREATE TABLE webhook_deliveries (
+        id bigserial primary key,
+        subscription_id bigint not null,
+        event_type varchar not null,
+        order_uid varchar not null,
+        payload jsonb not null,
+        status varchar not null default 'pending',
+        attempts INT not null default 0,
+        next_attempt_at TIMESTAMP not null default now(),
+        last_status_code INT,
+        last_error varchar,
+        created_at TIMESTAMP not null default now(),
+        delivered_at TIMESTAMP,
+
+        CONSTRAINT fk_webhook_deliveries_subscription
+            FOREIGN KEY (subscription_id)
+            REFERENCES webhook_subscriptions(id)
+            ON DELETE CASCADE
+    );
+
+    CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+    -- журнал каждой попытки доставки
+    CREATE TABLE webhook_attempts (
+        id bigserial primary key,
+        delivery_id bigint not null,
+        attempt INT not null,
+        status_code INT,
+        error varchar,
+        duration_ms INT not null,
+        created_at TIMESTAMP not null default now(),
+
+        CONSTRAINT fk_webhook_attempts_delivery
+            FOREIGN KEY (delivery_id)
+            REFERENCES webhook_deliveries(id)
+            ON DELETE CASCADE
+    );
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table webhook_attempts;
+drop table webhook_deliveries;
+drop table webhook_subscriptions;
+-- +goose StatementEnd
@@ -0,0 +1,22 @@
+-- +goose Up
+-- +goose StatementBegin
+    CREATE TABLE pii_access_log (
+        id bigserial primary key,
+        subject varchar not null,
+        role varchar not null,
+        channel varchar not null,
+        operation varchar not null,
+        order_uids text[] not null,
+        reason varchar not null default '',
+        accessed_at TIMESTAMP not null default now()
+    );
+
+    -- разбор инцидентов: кто и когда видел данные конкретного заказа
+    CREATE INDEX idx_pii_access_log_order_uids ON pii_access_log USING gin (order_uids);
+    CREATE INDEX idx_pii_access_log_subject ON pii_access_log (subject, accessed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table pii_access_log;
+-- +goose StatementEnd
@@ -0,0 +1,26 @@
+-- +goose Up
+-- +goose StatementBegin
+    -- name, phone, email и address хранят шифротекст (base64), если задан key_id.
+    -- Строки с key_id IS NULL - открытый текст до первого прохода перешифрования.
+    ALTER TABLE deliveries
+        ADD COLUMN key_id varchar,
+        ADD COLUMN wrapped_dek bytea,
+        ADD COLUMN phone_bidx varchar,
+        ADD COLUMN email_bidx varchar;
+
+    -- слепые индексы: поиск заказов по телефону и email без расшифровки
+    CREATE INDEX idx_deliveries_phone_bidx ON deliveries (phone_bidx);
+    CREATE INDEX idx_deliveries_email_bidx ON deliveries (email_bidx);
+    CREATE INDEX idx_deliveries_key_id ON deliveries (key_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+    -- шифротекст в name/phone/email/address откат не расшифровывает:
+    -- перед ним нужно выгрузить данные с ключами из keyring
+    ALTER TABLE deliveries
+        DROP COLUMN key_id,
+        DROP COLUMN wrapped_dek,
+        DROP COLUMN phone_bidx,
+        DROP COLUMN email_bidx;
+-- +goose StatementEnd
@@ -0,0 +1,23 @@
+-- +goose Up
+-- +goose StatementBegin
+    CREATE TABLE admin_audit_log (
+        id bigserial primary key,
+        subject varchar not null,
+        role varchar not null,
+        action varchar not null,
+        target varchar not null default '',
+        params jsonb not null default '{}',
+        result varchar not null,
+        error text not null default '',
+        performed_at TIMESTAMP not null default now()
+    );
+
+    -- разбор инцидентов: что и кто делал с кэшем и консьюмером
+    CREATE INDEX idx_admin_audit_log_performed_at ON admin_audit_log (performed_at);
+    CREATE INDEX idx_admin_audit_log_subject ON admin_audit_log (subject, performed_at);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+drop table admin_audit_log;
+-- +goose StatementEnd
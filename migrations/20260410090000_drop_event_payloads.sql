@@ -0,0 +1,19 @@
+-- +goose Up
+-- +goose StatementBegin
+    -- тело события собирается из заказа при публикации и отправке,
+    -- копия заказа с персональными данными открытым текстом больше не хранится
+    ALTER TABLE outbox DROP COLUMN payload;
+    ALTER TABLE webhook_deliveries DROP COLUMN payload;
+
+    -- очистка завершенных доставок по сроку хранения, попытки удаляются каскадно
+    CREATE INDEX idx_webhook_deliveries_finished ON webhook_deliveries (created_at) WHERE status <> 'pending';
+    CREATE INDEX idx_webhook_attempts_delivery_id ON webhook_attempts (delivery_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+    DROP INDEX idx_webhook_attempts_delivery_id;
+    DROP INDEX idx_webhook_deliveries_finished;
+    ALTER TABLE webhook_deliveries ADD COLUMN payload jsonb not null default '{}'::jsonb;
+    ALTER TABLE outbox ADD COLUMN payload jsonb not null default '{}'::jsonb;
+-- +goose StatementEnd
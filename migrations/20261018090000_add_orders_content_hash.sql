@@ -0,0 +1,11 @@
+-- +goose Up
+-- +goose StatementBegin
+    -- отпечаток содержимого заказа: повтор того же сообщения не перезаписывает заказ
+    -- и не создает order.updated. У старых строк NULL - первый повтор считается изменением.
+    ALTER TABLE orders ADD COLUMN content_hash varchar;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+    ALTER TABLE orders DROP COLUMN content_hash;
+-- +goose StatementEnd